@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/reports"
+)
+
+type AgingIssue struct {
+	Issue   *jira.Issue
+	Entered time.Time
+}
+
+func reportAgingCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report aging", flag.ExitOnError)
+	statuses := flags.String("status", "In Progress,Awaiting QA", "statuses to include")
+	jql := flags.String("jql", "", "restrict to issues matching this query")
+	flags.Parse(args)
+
+	search := reportSearch(options, fmt.Sprintf("(status IN (%s)) AND (resolution IS EMPTY)", quoteList(strings.Split(*statuses, ","))), *jql)
+
+	aging := make([]*AgingIssue, 0)
+	err := searchIssues(jc, options, search, &jira.SearchOptions{Expand: "changelog", MaxResults: 100, Fields: withFields(listingFields, "created", "resolution", "resolutiondate")}, func(issue jira.Issue) error {
+		aging = append(aging, &AgingIssue{Issue: listed(&issue), Entered: reports.EnteredCurrentStatus(&issue)})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	sort.Slice(aging, func(i, j int) bool {
+		return aging[i].Entered.Before(aging[j].Entered)
+	})
+
+	now := time.Now()
+	for _, a := range aging {
+		assignee := "(unassigned)"
+		if a.Issue.Fields.Assignee != nil {
+			assignee = a.Issue.Fields.Assignee.DisplayName
+		}
+		fmt.Printf("%-8s %7s %-16s %-20s %s\n", a.Issue.Key, reports.FormatDays(now.Sub(a.Entered)), a.Issue.Fields.Status.Name, assignee, a.Issue.Fields.Summary)
+	}
+
+	return nil
+}
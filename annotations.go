@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type CheckReporter struct {
+	title   string
+	github  bool
+	summary []string
+}
+
+func newCheckReporter(options *Options, title string) *CheckReporter {
+	github := options.Output == "github" || (options.Output == "" && os.Getenv("GITHUB_ACTIONS") == "true")
+	return &CheckReporter{
+		title:   title,
+		github:  github,
+		summary: make([]string, 0),
+	}
+}
+
+func escapeAnnotation(value string) string {
+	value = strings.ReplaceAll(value, "%", "%25")
+	value = strings.ReplaceAll(value, "\r", "%0D")
+	return strings.ReplaceAll(value, "\n", "%0A")
+}
+
+func escapeAnnotationProperty(value string) string {
+	value = escapeAnnotation(value)
+	value = strings.ReplaceAll(value, ":", "%3A")
+	return strings.ReplaceAll(value, ",", "%2C")
+}
+
+func escapeTableCell(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
+
+func (r *CheckReporter) pass(key, summary string) {
+	r.summary = append(r.summary, fmt.Sprintf("| [%s](%s) | :white_check_mark: | %s | |", key, issueURL(key), escapeTableCell(summary)))
+	if r.github {
+		fmt.Printf("::notice title=%s::%s\n", escapeAnnotationProperty(key), escapeAnnotation(summary))
+		return
+	}
+	fmt.Printf("%-8s %-4s %s\n", key, "ok", summary)
+}
+
+func (r *CheckReporter) fail(key, summary string, failures []string) {
+	reasons := strings.Join(failures, "; ")
+	r.summary = append(r.summary, fmt.Sprintf("| [%s](%s) | :x: | %s | %s |", key, issueURL(key), escapeTableCell(summary), escapeTableCell(reasons)))
+	if r.github {
+		fmt.Printf("::error title=%s::%s (%s)\n", escapeAnnotationProperty(key), escapeAnnotation(summary), escapeAnnotation(reasons))
+		return
+	}
+	fmt.Printf("%-8s %-4s %s (%s)\n", key, "FAIL", summary, reasons)
+}
+
+func (r *CheckReporter) close() error {
+	filename := os.Getenv("GITHUB_STEP_SUMMARY")
+	if !r.github || filename == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening step summary: %w", err)
+	}
+
+	defer file.Close()
+
+	lines := []string{
+		fmt.Sprintf("### %s", r.title),
+		"",
+		"| Issue | | Summary | Failures |",
+		"| --- | --- | --- | --- |",
+	}
+	lines = append(lines, r.summary...)
+
+	if _, err := fmt.Fprintln(file, strings.Join(lines, "\n")+"\n"); err != nil {
+		return fmt.Errorf("writing step summary: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+var defaultUnhelpfulNames = []string{
+	`^image\d*\.`,
+	`^img_\d+\.`,
+	`^dsc_?\d+\.`,
+	`^pxl_\d+`,
+	`^screen ?shot`,
+	`^screenshot`,
+	`^photo`,
+	`^unnamed`,
+	`^untitled`,
+	`^download`,
+	`^file\d*\.`,
+}
+
+type AttachmentNames struct {
+	Rename   bool
+	patterns []*regexp.Regexp
+}
+
+func loadAttachmentNames(config *Config) (*AttachmentNames, error) {
+	ac := config.AttachmentNames
+	if ac == nil || !ac.Enabled {
+		return nil, nil
+	}
+
+	patterns := ac.Patterns
+	if len(patterns) == 0 {
+		patterns = defaultUnhelpfulNames
+	}
+
+	names := &AttachmentNames{Rename: ac.Rename}
+	for _, p := range patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return nil, fmt.Errorf("attachment names: %w", err)
+		}
+		names.patterns = append(names.patterns, re)
+	}
+
+	return names, nil
+}
+
+func (an *AttachmentNames) unhelpful(filename string) bool {
+	if an == nil {
+		return false
+	}
+	for _, re := range an.patterns {
+		if re.MatchString(filename) {
+			return true
+		}
+	}
+	return false
+}
+
+func (an *AttachmentNames) report(issue *jira.Issue, out io.Writer) {
+	for _, a := range issue.Fields.Attachments {
+		if an.unhelpful(a.Filename) {
+			fmt.Fprintf(out, "%-8s unhelpful attachment name: %s (%s)\n", issue.Key, a.Filename, a.ID)
+		}
+	}
+}
+
+func (an *AttachmentNames) mirroredName(issue *jira.Issue, a *jira.Attachment, saveAs string) string {
+	if an == nil || !an.Rename || !an.unhelpful(a.Filename) {
+		return saveAs
+	}
+
+	date := "unknown"
+	if created, err := time.Parse("2006-01-02T15:04:05.000-0700", a.Created); err == nil {
+		date = created.Format("2006-01-02")
+	}
+
+	return fmt.Sprintf("%s_%s_%s", strings.ToLower(issue.Key), date, saveAs)
+}
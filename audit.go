@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type AuditRecord struct {
+	Run    string    `json:"run"`
+	Time   time.Time `json:"time"`
+	Issue  string    `json:"issue"`
+	Field  string    `json:"field"`
+	Rules  []string  `json:"rules"`
+	Before string    `json:"before"`
+	After  string    `json:"after"`
+}
+
+type AuditLog struct {
+	run  string
+	file *os.File
+	lock sync.Mutex
+}
+
+func auditLogPath() string {
+	return path.Join(stateDirectory(), "upkeep-audit.jsonl")
+}
+
+func hashBody(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func openAuditLog() (*AuditLog, error) {
+	if err := os.MkdirAll(stateDirectory(), 0755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(auditLogPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+
+	return &AuditLog{
+		run:  time.Now().UTC().Format("20060102T150405Z"),
+		file: file,
+	}, nil
+}
+
+func (a *AuditLog) record(issue, field string, rules []string, before, after string) error {
+	if a == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(&AuditRecord{
+		Run:    a.run,
+		Time:   time.Now(),
+		Issue:  issue,
+		Field:  field,
+		Rules:  rules,
+		Before: hashBody(before),
+		After:  hashBody(after),
+	})
+	if err != nil {
+		return err
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if _, err := a.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AuditLog) close() error {
+	if a == nil {
+		return nil
+	}
+	return a.file.Close()
+}
+
+func readAuditLog() ([]*AuditRecord, error) {
+	file, err := os.Open(auditLogPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	records := make([]*AuditRecord, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := &AuditRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, fmt.Errorf("parsing audit log: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+func auditCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("upkeep audit", flag.ExitOnError)
+	run := flags.String("run", "", "only show changes from this run")
+	issue := flags.String("issue", "", "only show changes to this issue")
+	since := flags.String("since", "", "only show changes newer than this (7d, 12h)")
+	flags.Parse(args)
+
+	records, err := readAuditLog()
+	if err != nil {
+		return err
+	}
+
+	var after time.Time
+	if *since != "" {
+		after, err = parseSince(*since, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	last := ""
+	for _, r := range records {
+		if (*run != "" && r.Run != *run) || (*issue != "" && !strings.EqualFold(r.Issue, *issue)) || r.Time.Before(after) {
+			continue
+		}
+		if r.Run != last {
+			fmt.Printf("run %s\n", r.Run)
+			last = r.Run
+		}
+		fmt.Printf("  %s %-8s %-16s %s -> %s (%s)\n", r.Time.Local().Format("2006/01/02 15:04"), r.Issue, r.Field, r.Before, r.After, strings.Join(r.Rules, ", "))
+	}
+
+	return nil
+}
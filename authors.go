@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type Authors struct {
+	ctx     context.Context
+	jc      *jira.Client
+	mapping map[string]string
+	cache   map[string]*jira.User
+	lock    sync.Mutex
+}
+
+func newAuthors(ctx context.Context, jc *jira.Client, config *Config) *Authors {
+	mapping := make(map[string]string)
+	for author, name := range config.Authors {
+		mapping[strings.ToLower(author)] = name
+	}
+	return &Authors{
+		ctx:     ctx,
+		jc:      jc,
+		mapping: mapping,
+		cache:   make(map[string]*jira.User),
+	}
+}
+
+func (a *Authors) find(query string) (*jira.User, error) {
+	users, _, err := a.jc.User.FindWithContext(a.ctx, "", jira.WithUsername(query))
+	if err != nil {
+		return nil, fmt.Errorf("error finding user: %w", err)
+	}
+	if len(users) != 1 {
+		return nil, nil
+	}
+	return &users[0], nil
+}
+
+// user looks up a Server user by username, go-jira's Get only takes Cloud
+// account ids.
+func (a *Authors) user(name string) (*jira.User, error) {
+	req, err := a.jc.NewRequestWithContext(a.ctx, "GET", "/rest/api/2/user?username="+url.QueryEscape(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	user := &jira.User{}
+	if _, err := a.jc.Do(req, user); err != nil {
+		return nil, fmt.Errorf("error getting user %s: %w", name, err)
+	}
+	return user, nil
+}
+
+func (a *Authors) resolve(commit *Commit) (*jira.User, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	email := strings.ToLower(commit.Email)
+	if user, ok := a.cache[email]; ok {
+		return user, nil
+	}
+
+	var user *jira.User
+	var err error
+
+	if name, ok := a.mapping[email]; ok {
+		user, err = a.user(name)
+	} else if name, ok := a.mapping[strings.ToLower(commit.Author)]; ok {
+		user, err = a.user(name)
+	} else if !strings.HasSuffix(email, "@users.noreply.github.com") {
+		user, err = a.find(email)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error resolving %s <%s>: %w", commit.Author, commit.Email, err)
+	}
+
+	if user == nil {
+		log.Printf("no jira user for %s <%s>", commit.Author, commit.Email)
+	}
+
+	a.cache[email] = user
+
+	return user, nil
+}
+
+func (a *Authors) assign(issue *jira.Issue, commit *Commit, options *Options) error {
+	if issue.Fields.Assignee != nil {
+		return nil
+	}
+
+	user, err := a.resolve(commit)
+	if err != nil || user == nil {
+		return err
+	}
+
+	echoIssueActionMessage(fmt.Sprintf("assigning %s", user.DisplayName), issue)
+
+	if options.DryRun {
+		return nil
+	}
+
+	if _, err := a.jc.Issue.UpdateAssigneeWithContext(a.ctx, issue.Key, &jira.User{Name: user.Name, AccountID: user.AccountID}); err != nil {
+		return fmt.Errorf("error assigning: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+)
+
+// AutomationAction does one thing to a matching issue. Notify sends its
+// message to the Slack channel or mailing list given, or to --notify.
+// Messages and comments may use {key}, {summary}, {status}, {assignee} and
+// {url}.
+type AutomationAction struct {
+	Transition string `json:"transition,omitempty"`
+	Label      string `json:"label,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	Notify     string `json:"notify,omitempty"`
+	Slack      string `json:"slack,omitempty"`
+	Mail       string `json:"mail,omitempty"`
+}
+
+type AutomationRule struct {
+	Name string              `json:"name"`
+	When string              `json:"when"`
+	Then []*AutomationAction `json:"then"`
+}
+
+// AutomationState remembers the issues each rule has acted on. An issue is
+// acted on when it starts matching a rule and forgotten once it stops, so
+// it's acted on again if it matches again later. Pending counts the actions
+// already taken on issues whose later actions failed, so a retry doesn't
+// repeat comments or notifications.
+type AutomationState struct {
+	Matched map[string]map[string]time.Time `json:"matched"`
+	Pending map[string]map[string]int       `json:"pending,omitempty"`
+}
+
+func automationStatePath() string {
+	return path.Join(stateDirectory(), "automation.json")
+}
+
+func loadAutomationState() (*AutomationState, error) {
+	state := &AutomationState{Matched: make(map[string]map[string]time.Time), Pending: make(map[string]map[string]int)}
+
+	data, err := ioutil.ReadFile(automationStatePath())
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", automationStatePath(), err)
+	}
+
+	if state.Matched == nil {
+		state.Matched = make(map[string]map[string]time.Time)
+	}
+	if state.Pending == nil {
+		state.Pending = make(map[string]map[string]int)
+	}
+
+	return state, nil
+}
+
+func (s *AutomationState) save() error {
+	if err := os.MkdirAll(stateDirectory(), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(automationStatePath(), data, 0644)
+}
+
+func (a *AutomationAction) kinds() []string {
+	kinds := make([]string, 0)
+	if a.Transition != "" {
+		kinds = append(kinds, "transition")
+	}
+	if a.Label != "" {
+		kinds = append(kinds, "label")
+	}
+	if a.Comment != "" {
+		kinds = append(kinds, "comment")
+	}
+	if a.Notify != "" {
+		kinds = append(kinds, "notify")
+	}
+	return kinds
+}
+
+func loadAutomationRules(filename string) ([]*AutomationRule, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filename, err)
+	}
+
+	rules := make([]*AutomationRule, 0)
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filename, err)
+	}
+
+	names := make(map[string]bool)
+	for _, r := range rules {
+		if r.Name == "" || r.When == "" || len(r.Then) == 0 {
+			return nil, fmt.Errorf("%s: rules need a name, when and then", filename)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("%s: duplicate rule '%s'", filename, r.Name)
+		}
+		names[r.Name] = true
+		for _, a := range r.Then {
+			if len(a.kinds()) != 1 {
+				return nil, fmt.Errorf("rule %s: each action needs exactly one of transition, label, comment or notify", r.Name)
+			}
+			if (a.Slack != "" || a.Mail != "") && a.Notify == "" {
+				return nil, fmt.Errorf("rule %s: slack and mail only apply to notify", r.Name)
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+func expandAutomationText(text string, issue *jira.Issue) string {
+	status, assignee := "", "unassigned"
+	if issue.Fields.Status != nil {
+		status = issue.Fields.Status.Name
+	}
+	if issue.Fields.Assignee != nil {
+		assignee = issue.Fields.Assignee.DisplayName
+	}
+	return strings.NewReplacer(
+		"{key}", issue.Key,
+		"{summary}", issue.Fields.Summary,
+		"{status}", status,
+		"{assignee}", assignee,
+		"{url}", issueURL(issue.Key),
+	).Replace(text)
+}
+
+type Automation struct {
+	issues   client.Issues
+	config   *Config
+	options  *Options
+	rules    []*AutomationRule
+	state    *AutomationState
+	notifier Notifier
+	audit    *AuditLog
+}
+
+func (a *Automation) notifierFor(action *AutomationAction) (Notifier, error) {
+	switch {
+	case action.Slack != "":
+		notifier, err := a.config.slack(action.Slack)
+		if err == nil && notifier == nil {
+			err = fmt.Errorf("no slack channel for '%s'", action.Slack)
+		}
+		return notifier, err
+	case action.Mail != "":
+		return a.config.mailer(action.Mail, false)
+	case a.notifier != nil:
+		return a.notifier, nil
+	}
+	return nil, fmt.Errorf("notify requires slack, mail or --notify")
+}
+
+func (a *Automation) act(ctx context.Context, rule *AutomationRule, action *AutomationAction, issue *jira.Issue) error {
+	rules := []string{"automate:" + rule.Name}
+
+	switch {
+	case action.Transition != "":
+		if issue.Fields.Status != nil && strings.EqualFold(issue.Fields.Status.Name, action.Transition) {
+			return nil
+		}
+		log.Printf("[%s] %s: transition to %s", issue.Key, rule.Name, action.Transition)
+		if a.options.DryRun {
+			return nil
+		}
+		before := ""
+		if issue.Fields.Status != nil {
+			before = issue.Fields.Status.Name
+		}
+		if err := changeIssueStatus(ctx, a.issues, issue, action.Transition); err != nil {
+			return err
+		}
+		return a.audit.record(issue.Key, "status", rules, before, action.Transition)
+	case action.Label != "":
+		if hasLabel(issue, action.Label) {
+			return nil
+		}
+		log.Printf("[%s] %s: label %s", issue.Key, rule.Name, action.Label)
+		if a.options.DryRun {
+			return nil
+		}
+		if err := addLabel(ctx, a.issues, issue, action.Label); err != nil {
+			return err
+		}
+		before := strings.Join(issue.Fields.Labels, " ")
+		return a.audit.record(issue.Key, "labels", rules, before, strings.TrimSpace(before+" "+action.Label))
+	case action.Comment != "":
+		body := expandAutomationText(action.Comment, issue)
+		log.Printf("[%s] %s: comment", issue.Key, rule.Name)
+		if a.options.DryRun {
+			return nil
+		}
+		if _, _, err := a.issues.AddCommentWithContext(ctx, issue.Key, &jira.Comment{Body: body}); err != nil {
+			return fmt.Errorf("error adding comment: %w", err)
+		}
+		return a.audit.record(issue.Key, "comment", rules, "", body)
+	case action.Notify != "":
+		log.Printf("[%s] %s: notify", issue.Key, rule.Name)
+		notifier, err := a.notifierFor(action)
+		if err != nil {
+			return err
+		}
+		if a.options.DryRun {
+			return nil
+		}
+		return notifier.Notify(ctx, fmt.Sprintf("%s %s", issue.Key, issue.Fields.Summary), expandAutomationText(action.Notify, issue))
+	}
+
+	return nil
+}
+
+func (a *Automation) run(ctx context.Context) error {
+	for _, rule := range a.rules {
+		previous := a.state.Matched[rule.Name]
+		done := a.state.Pending[rule.Name]
+		matched := make(map[string]time.Time)
+		pending := make(map[string]int)
+
+		err := pages.Each(ctx, a.issues, rule.When, &jira.SearchOptions{MaxResults: 100, Fields: listingFields}, func(issue *jira.Issue) error {
+			if when, ok := previous[issue.Key]; ok {
+				matched[issue.Key] = when
+				return nil
+			}
+
+			// Failed issues are tried again next run, from the failed action.
+			for n := done[issue.Key]; n < len(rule.Then); n++ {
+				if err := a.act(ctx, rule, rule.Then[n], issue); err != nil {
+					log.Printf("[%s] %s: %v", issue.Key, rule.Name, err)
+					if n > 0 && !a.options.DryRun {
+						pending[issue.Key] = n
+					}
+					return nil
+				}
+			}
+
+			matched[issue.Key] = time.Now()
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+
+		a.state.Matched[rule.Name] = matched
+		if len(pending) > 0 {
+			a.state.Pending[rule.Name] = pending
+		} else {
+			delete(a.state.Pending, rule.Name)
+		}
+	}
+
+	if a.options.DryRun {
+		return nil
+	}
+
+	return a.state.save()
+}
+
+func automateRunCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("automate run", flag.ExitOnError)
+	filename := flags.String("rules", "automation.json", "file of when/then rules")
+	interval := flags.Duration("interval", 0, "keep running, evaluating the rules this often")
+	flags.Parse(args)
+
+	rules, err := loadAutomationRules(*filename)
+	if err != nil {
+		return err
+	}
+
+	state, err := loadAutomationState()
+	if err != nil {
+		return err
+	}
+
+	notifier, err := newNotifier(options)
+	if err != nil {
+		return err
+	}
+
+	a := &Automation{
+		issues:   jc.Issue,
+		config:   config,
+		options:  options,
+		rules:    rules,
+		state:    state,
+		notifier: notifier,
+	}
+
+	for _, rule := range rules {
+		for _, action := range rule.Then {
+			if action.Notify == "" {
+				continue
+			}
+			if _, err := a.notifierFor(action); err != nil {
+				return fmt.Errorf("rule %s: %w", rule.Name, err)
+			}
+		}
+	}
+
+	if !options.DryRun {
+		a.audit, err = openAuditLog()
+		if err != nil {
+			return err
+		}
+
+		defer a.audit.close()
+	}
+
+	if err := a.run(options.ctx); err != nil || *interval == 0 {
+		return err
+	}
+
+	log.Printf("automate: evaluating %d rules every %v", len(rules), *interval)
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-options.ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := a.run(options.ctx); err != nil && options.ctx.Err() == nil {
+				log.Printf("automate: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func assigneeName(issue *jira.Issue) string {
+	if issue.Fields.Assignee == nil {
+		return "(unassigned)"
+	}
+	return issue.Fields.Assignee.DisplayName
+}
+
+func reportBlockedCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report blocked", flag.ExitOnError)
+	jql := flags.String("jql", "", "restrict to issues matching this query")
+	flags.Parse(args)
+
+	search := reportSearch(options, `(resolution IS EMPTY) AND (issueLinkType = "is blocked by" OR Flagged IS NOT EMPTY)`, *jql)
+
+	issues := make([]*jira.Issue, 0)
+	err := searchIssues(jc, options, search+" ORDER BY priority DESC, updated ASC", &jira.SearchOptions{MaxResults: 100, Fields: withFields(listingFields, "issuelinks")}, func(issue jira.Issue) error {
+		issues = append(issues, listed(&issue))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	blockerKeys := make(map[string]bool)
+	for _, issue := range issues {
+		for _, key := range openBlockers(issue) {
+			blockerKeys[key] = true
+		}
+	}
+
+	blockers := make(map[string]*jira.Issue)
+	if options.Cached {
+		for key := range blockerKeys {
+			if b, err := options.cache.get(key); err == nil && b != nil {
+				blockers[key] = b
+			}
+		}
+	} else if len(blockerKeys) > 0 {
+		found, _, err := jc.Issue.SearchWithContext(options.ctx, fmt.Sprintf("key IN (%s)", strings.Join(sortedKeys(blockerKeys), ", ")), &jira.SearchOptions{MaxResults: len(blockerKeys), Fields: listingFields})
+		if err != nil {
+			return fmt.Errorf("error getting blockers: %w", err)
+		}
+		for i := range found {
+			blockers[found[i].Key] = &found[i]
+		}
+	}
+
+	for _, issue := range issues {
+		keys := openBlockers(issue)
+
+		fmt.Printf("%-8s %-20s %s\n", issue.Key, assigneeName(issue), issue.Fields.Summary)
+
+		if len(keys) == 0 {
+			fmt.Printf("%8s flagged\n", "")
+		}
+
+		for _, key := range keys {
+			if b, ok := blockers[key]; ok {
+				fmt.Printf("%8s blocked by %-8s %-20s %-16s %s\n", "", key, assigneeName(b), b.Fields.Status.Name, b.Fields.Summary)
+			} else {
+				fmt.Printf("%8s blocked by %s\n", "", key)
+			}
+		}
+	}
+
+	fmt.Printf("\n%d blocked issues\n", len(issues))
+
+	return nil
+}
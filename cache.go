@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+	bolt "go.etcd.io/bbolt"
+)
+
+var issuesBucket = []byte("issues")
+var searchesBucket = []byte("searches")
+var metaBucket = []byte("meta")
+
+var syncedKey = []byte("synced")
+
+type Cache struct {
+	db *bolt.DB
+}
+
+type CachedSearch struct {
+	Keys []string  `json:"keys"`
+	Time time.Time `json:"time"`
+}
+
+func cachePath() string {
+	return path.Join(stateDirectory(), "cache.db")
+}
+
+// openCache opens the cache read only when nothing will be saved to it,
+// which shares the file with other readers rather than locking them out.
+func openCache(create, readOnly bool) (*Cache, error) {
+	if _, err := os.Stat(cachePath()); os.IsNotExist(err) && !create {
+		return nil, nil
+	}
+
+	if readOnly {
+		db, err := bolt.Open(cachePath(), 0644, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: true})
+		if err != nil {
+			return nil, fmt.Errorf("opening cache: %w", err)
+		}
+		return &Cache{db: db}, nil
+	}
+
+	if err := os.MkdirAll(stateDirectory(), 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(cachePath(), 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening cache: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{issuesBucket, searchesBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening cache: %w", err)
+	}
+
+	return &Cache{db: db}, nil
+}
+
+func (c *Cache) close() error {
+	if c == nil {
+		return nil
+	}
+	return c.db.Close()
+}
+
+func (c *Cache) put(issues []jira.Issue) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(issuesBucket)
+		for _, issue := range issues {
+			data, err := json.Marshal(issue)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(issue.Key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *Cache) get(key string) (*jira.Issue, error) {
+	var issue *jira.Issue
+	err := c.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(issuesBucket).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		issue = &jira.Issue{}
+		return json.Unmarshal(data, issue)
+	})
+	return issue, err
+}
+
+func (c *Cache) has(key string) bool {
+	found := false
+	c.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(issuesBucket).Get([]byte(key)) != nil
+		return nil
+	})
+	return found
+}
+
+func (c *Cache) synced() time.Time {
+	var synced time.Time
+	c.db.View(func(tx *bolt.Tx) error {
+		if data := tx.Bucket(metaBucket).Get(syncedKey); data != nil {
+			synced.UnmarshalText(data)
+		}
+		return nil
+	})
+	return synced
+}
+
+func (c *Cache) setSynced(t time.Time) error {
+	data, err := t.MarshalText()
+	if err != nil {
+		return err
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Put(syncedKey, data)
+	})
+}
+
+func (c *Cache) search(jql string) (*CachedSearch, error) {
+	var search *CachedSearch
+	err := c.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(searchesBucket).Get([]byte(jql))
+		if data == nil {
+			return nil
+		}
+		search = &CachedSearch{}
+		return json.Unmarshal(data, search)
+	})
+	return search, err
+}
+
+func (c *Cache) saveSearch(jql string, search *CachedSearch) error {
+	data, err := json.Marshal(search)
+	if err != nil {
+		return err
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(searchesBucket).Put([]byte(jql), data)
+	})
+}
+
+func (c *Cache) searches() (map[string]*CachedSearch, error) {
+	searches := make(map[string]*CachedSearch)
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(searchesBucket).ForEach(func(k, v []byte) error {
+			search := &CachedSearch{}
+			if err := json.Unmarshal(v, search); err != nil {
+				return err
+			}
+			searches[string(k)] = search
+			return nil
+		})
+	})
+	return searches, err
+}
+
+func searchIssues(jc *jira.Client, options *Options, jql string, searchOptions *jira.SearchOptions, fn func(jira.Issue) error) error {
+	if options.Cached {
+		return options.cache.read(jql, fn)
+	}
+
+	keys := make([]string, 0)
+	err := pages.Each(options.ctx, jc.Issue, jql, searchOptions, func(issue *jira.Issue) error {
+		keys = append(keys, issue.Key)
+		return fn(*issue)
+	})
+	if err != nil {
+		return err
+	}
+
+	if options.cache != nil {
+		if err := options.cache.saveSearch(jql, &CachedSearch{Keys: keys, Time: time.Now()}); err != nil {
+			log.Printf("cache: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Cache) read(jql string, fn func(jira.Issue) error) error {
+	if c == nil {
+		return fmt.Errorf("no cache, run sync first")
+	}
+
+	search, err := c.search(jql)
+	if err != nil {
+		return err
+	}
+	if search == nil {
+		return fmt.Errorf("query not cached, run it once online and then sync: %s", jql)
+	}
+
+	stop := make(chan bool)
+	defer close(stop)
+
+	for r := range c.decode(search.Keys, runtime.NumCPU(), stop) {
+		if r.err != nil {
+			return r.err
+		}
+		if r.issue == nil {
+			continue
+		}
+		if err := fn(*r.issue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type decodedIssue struct {
+	issue *jira.Issue
+	err   error
+}
+
+func (c *Cache) decode(keys []string, workers int, stop chan bool) <-chan *decodedIssue {
+	decoded := make(chan *decodedIssue)
+	slots := make(chan chan *decodedIssue, workers*4)
+
+	go func() {
+		defer close(slots)
+		running := make(chan bool, workers)
+		for _, key := range keys {
+			slot := make(chan *decodedIssue, 1)
+			select {
+			case running <- true:
+			case <-stop:
+				return
+			}
+			select {
+			case slots <- slot:
+			case <-stop:
+				return
+			}
+			go func(key string) {
+				defer func() { <-running }()
+				issue, err := c.get(key)
+				slot <- &decodedIssue{issue: issue, err: err}
+			}(key)
+		}
+	}()
+
+	go func() {
+		defer close(decoded)
+		for slot := range slots {
+			select {
+			case decoded <- <-slot:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return decoded
+}
+
+func getIssue(jc *jira.Client, options *Options, key string) (*jira.Issue, error) {
+	if options.Cached {
+		issue, err := options.cache.get(key)
+		if err != nil {
+			return nil, err
+		}
+		if issue == nil {
+			return nil, fmt.Errorf("%s is not cached", key)
+		}
+		return issue, nil
+	}
+
+	issue, _, err := jc.Issue.GetWithContext(options.ctx, key, &jira.GetQueryOptions{Expand: "changelog"})
+	if err != nil {
+		return nil, fmt.Errorf("error getting issue: %w", err)
+	}
+
+	return issue, nil
+}
+
+func servesOffline(command *Command, options *Options) bool {
+	if command != nil {
+		return command.Cached
+	}
+	return !options.Upkeep && !options.Mirror && options.Pull == "" && !options.DeployedPortal && !options.DeployedApp && options.Version == ""
+}
+
+func syncIssues(ctx context.Context, jc *jira.Client, cache *Cache, jql string) (int, error) {
+	batch := make([]jira.Issue, 0)
+	total := 0
+
+	err := pages.Each(ctx, jc.Issue, jql, &jira.SearchOptions{Expand: "changelog", MaxResults: 100, Fields: []string{"*all"}}, func(issue *jira.Issue) error {
+		batch = append(batch, *issue)
+		total++
+		if len(batch) == 100 {
+			if err := cache.put(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+		return nil
+	})
+	if err != nil {
+		if interrupted(err) {
+			if err := cache.put(batch); err != nil {
+				return total, err
+			}
+		}
+		return total, fmt.Errorf("error getting issues: %w", err)
+	}
+
+	return total, cache.put(batch)
+}
+
+func syncCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	jql := flags.String("jql", "", "also restrict the synced issues to this query")
+	full := flags.Bool("full", false, "sync every issue, not just those updated since the last sync")
+	flags.Parse(args)
+
+	cache := options.cache
+	if cache == nil {
+		created, err := openCache(true, false)
+		if err != nil {
+			return err
+		}
+		defer created.close()
+		cache = created
+	}
+
+	started := time.Now()
+
+	search := fmt.Sprintf("(project IN (%s))", quoteList(config.projects(options)))
+	if *jql != "" {
+		search += fmt.Sprintf(" AND (%s)", *jql)
+	}
+
+	if synced := cache.synced(); !synced.IsZero() && !*full {
+		search += fmt.Sprintf(" AND (updated >= '%s')", synced.Add(-time.Minute).Format(jqlTimeLayout))
+	}
+
+	log.Printf("sync: %s", search)
+
+	total, err := syncIssues(options.ctx, jc, cache, search+" ORDER BY updated ASC")
+	if err != nil {
+		if interrupted(err) {
+			log.Printf("sync: interrupted, %d issues updated", total)
+		}
+		return err
+	}
+
+	log.Printf("sync: %d issues updated", total)
+
+	searches, err := cache.searches()
+	if err != nil {
+		return err
+	}
+
+	missing := make(map[string]bool)
+	for query := range searches {
+		keys := make([]string, 0)
+		err := pages.Each(options.ctx, jc.Issue, query, &jira.SearchOptions{MaxResults: 100, Fields: []string{"key"}}, func(issue *jira.Issue) error {
+			keys = append(keys, issue.Key)
+			if !cache.has(issue.Key) {
+				missing[issue.Key] = true
+			}
+			return nil
+		})
+		if interrupted(err) {
+			return err
+		}
+		if err != nil {
+			log.Printf("sync: %v (%s)", err, query)
+			continue
+		}
+		if err := cache.saveSearch(query, &CachedSearch{Keys: keys, Time: started}); err != nil {
+			return err
+		}
+	}
+
+	keys := sortedKeys(missing)
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > 100 {
+			n = 100
+		}
+		if _, err := syncIssues(options.ctx, jc, cache, fmt.Sprintf("key IN (%s)", strings.Join(keys[:n], ", "))); err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+
+	log.Printf("sync: %d searches refreshed, %d issues added", len(searches), len(missing))
+
+	return cache.setSynced(started)
+}
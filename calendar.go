@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const icsDateLayout = "20060102"
+const icsTimeLayout = "20060102T150405Z"
+
+type CalendarEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	End         time.Time
+}
+
+var icsEscaper = strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n")
+
+// icsLine folds content lines longer than 75 octets, without splitting
+// utf-8 sequences.
+func icsLine(b *bytes.Buffer, line string) {
+	for len(line) > 75 {
+		cut := 75
+		for cut > 0 && line[cut]&0xc0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line + "\r\n")
+}
+
+func makeCalendar(name string, events []*CalendarEvent, now time.Time) []byte {
+	var b bytes.Buffer
+	icsLine(&b, "BEGIN:VCALENDAR")
+	icsLine(&b, "VERSION:2.0")
+	icsLine(&b, "PRODID:-//jira-ops//calendar//EN")
+	icsLine(&b, "CALSCALE:GREGORIAN")
+	icsLine(&b, "X-WR-CALNAME:"+icsEscaper.Replace(name))
+	for _, e := range events {
+		icsLine(&b, "BEGIN:VEVENT")
+		icsLine(&b, "UID:"+e.UID)
+		icsLine(&b, "DTSTAMP:"+now.UTC().Format(icsTimeLayout))
+		icsLine(&b, "DTSTART;VALUE=DATE:"+e.Start.Format(icsDateLayout))
+		icsLine(&b, "DTEND;VALUE=DATE:"+e.End.Format(icsDateLayout))
+		icsLine(&b, "SUMMARY:"+icsEscaper.Replace(e.Summary))
+		if e.Description != "" {
+			icsLine(&b, "DESCRIPTION:"+icsEscaper.Replace(e.Description))
+		}
+		if e.URL != "" {
+			icsLine(&b, "URL:"+e.URL)
+		}
+		icsLine(&b, "END:VEVENT")
+	}
+	icsLine(&b, "END:VCALENDAR")
+	return b.Bytes()
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func dueDateEvents(jc *jira.Client, options *Options, jql string) ([]*CalendarEvent, error) {
+	events := make([]*CalendarEvent, 0)
+	search := reportSearch(options, "(duedate IS NOT EMPTY) AND (resolution IS EMPTY)", jql) + " ORDER BY duedate ASC"
+	err := searchIssues(jc, options, search, &jira.SearchOptions{MaxResults: 100, Fields: withFields(listingFields, "duedate")}, func(issue jira.Issue) error {
+		due := day(time.Time(issue.Fields.Duedate))
+		if due.IsZero() {
+			return nil
+		}
+		assignee := "unassigned"
+		if issue.Fields.Assignee != nil {
+			assignee = issue.Fields.Assignee.DisplayName
+		}
+		events = append(events, &CalendarEvent{
+			UID:         fmt.Sprintf("due-%s@jira-ops", issue.Key),
+			Summary:     fmt.Sprintf("%s due: %s", issue.Key, issue.Fields.Summary),
+			Description: fmt.Sprintf("%s, %s", issue.Fields.Status.Name, assignee),
+			URL:         issueURL(issue.Key),
+			Start:       due,
+			End:         due.AddDate(0, 0, 1),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting issues: %w", err)
+	}
+	return events, nil
+}
+
+func sprintEvents(ctx context.Context, jc *jira.Client, board int) ([]*CalendarEvent, error) {
+	events := make([]*CalendarEvent, 0)
+	options := &jira.GetAllSprintsOptions{State: "active,future,closed"}
+	for {
+		sprints, _, err := jc.Board.GetAllSprintsWithOptionsWithContext(ctx, board, options)
+		if err != nil {
+			return nil, fmt.Errorf("error getting sprints: %w", err)
+		}
+		for _, s := range sprints.Values {
+			if s.StartDate == nil || s.EndDate == nil {
+				continue
+			}
+			events = append(events, &CalendarEvent{
+				UID:     fmt.Sprintf("sprint-%d@jira-ops", s.ID),
+				Summary: s.Name,
+				Start:   day(*s.StartDate),
+				End:     day(*s.EndDate).AddDate(0, 0, 1),
+			})
+		}
+		if sprints.IsLast || len(sprints.Values) == 0 {
+			break
+		}
+		options.StartAt += len(sprints.Values)
+	}
+	return events, nil
+}
+
+func releaseEvents(ctx context.Context, jc *jira.Client, projects []string) ([]*CalendarEvent, error) {
+	events := make([]*CalendarEvent, 0)
+	for _, key := range projects {
+		project, _, err := jc.Project.GetWithContext(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("error getting project %s: %w", key, err)
+		}
+		for _, v := range project.Versions {
+			if v.ReleaseDate == "" || (v.Archived != nil && *v.Archived) {
+				continue
+			}
+			date, err := time.Parse("2006-01-02", v.ReleaseDate)
+			if err != nil {
+				log.Printf("calendar: %s %s: %v", key, v.Name, err)
+				continue
+			}
+			summary := fmt.Sprintf("%s %s release", key, v.Name)
+			if v.Released != nil && *v.Released {
+				summary = fmt.Sprintf("%s %s released", key, v.Name)
+			}
+			events = append(events, &CalendarEvent{
+				UID:         fmt.Sprintf("version-%s@jira-ops", v.ID),
+				Summary:     summary,
+				Description: v.Description,
+				Start:       date,
+				End:         date.AddDate(0, 0, 1),
+			})
+		}
+	}
+	return events, nil
+}
+
+func buildCalendar(jc *jira.Client, config *Config, options *Options, board int, jql string) ([]byte, error) {
+	events, err := dueDateEvents(jc, options, jql)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sprints and releases aren't in the cache, offline calendars only have
+	// due dates.
+	if options.Cached {
+		log.Printf("calendar: sprints and releases are left out when reading from the cache")
+		return makeCalendar("Jira", events, time.Now()), nil
+	}
+
+	if board != 0 {
+		sprints, err := sprintEvents(options.ctx, jc, board)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, sprints...)
+	}
+
+	releases, err := releaseEvents(options.ctx, jc, config.projects(options))
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, releases...)
+
+	return makeCalendar("Jira", events, time.Now()), nil
+}
+
+func calendarCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("calendar", flag.ExitOnError)
+	out := flags.String("out", "", "file to write the calendar to, defaults to stdout")
+	board := flags.Int("board", config.Board, "agile board whose sprints are included, 0 for none")
+	jql := flags.String("jql", "", "restrict due dates to issues matching this query")
+	listen := flags.String("listen", "", "serve the calendar over http on this address instead of writing it")
+	refresh := flags.Duration("refresh", 15*time.Minute, "how long a served calendar is reused before it's rebuilt")
+	flags.Parse(args)
+
+	if *listen != "" {
+		return serveCalendar(*listen, *refresh, options, func() ([]byte, error) {
+			return buildCalendar(jc, config, options, *board, *jql)
+		})
+	}
+
+	data, err := buildCalendar(jc, config, options, *board, *jql)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	return ioutil.WriteFile(*out, data, 0644)
+}
+
+// cachedHandler serves what build returns, rebuilding it at most every
+// refresh and serving the previous copy when a rebuild fails.
+func cachedHandler(name, contentType string, refresh time.Duration, build func() ([]byte, error)) http.HandlerFunc {
+	var lock sync.Mutex
+	var data []byte
+	var built time.Time
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if data == nil || time.Since(built) > refresh {
+			fresh, err := build()
+			if err != nil {
+				log.Printf("%s: %v", name, err)
+				if data == nil {
+					http.Error(w, name+" unavailable", http.StatusBadGateway)
+					return
+				}
+			} else {
+				data, built = fresh, time.Now()
+			}
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write(data)
+	}
+}
+
+func serveCalendar(listen string, refresh time.Duration, options *Options, build func() ([]byte, error)) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/calendar.ics", cachedHandler("calendar", "text/calendar; charset=utf-8", refresh, build))
+
+	hs := &http.Server{Addr: listen, Handler: mux}
+
+	go func() {
+		<-options.ctx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hs.Shutdown(ctx)
+	}()
+
+	log.Printf("calendar: serving http://%s/calendar.ics", listen)
+
+	if err := hs.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
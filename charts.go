@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path"
+	"strings"
+)
+
+const chartWidth = 640
+const chartHeight = 320
+const chartMargin = 40
+
+var chartColors = []color.RGBA{
+	{0x4a, 0x90, 0xd9, 0xff},
+	{0xe0, 0x6c, 0x3c, 0xff},
+	{0x5c, 0xb8, 0x5c, 0xff},
+	{0x99, 0x66, 0xcc, 0xff},
+}
+
+type ChartSeries struct {
+	Name   string
+	Values []float64
+	Line   bool
+}
+
+type Chart struct {
+	Title  string
+	Labels []string
+	Series []*ChartSeries
+}
+
+func (c *Chart) maximum() float64 {
+	maximum := 0.0
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			if v > maximum {
+				maximum = v
+			}
+		}
+	}
+	if maximum == 0 {
+		maximum = 1
+	}
+	return maximum
+}
+
+func (c *Chart) bars() []*ChartSeries {
+	bars := make([]*ChartSeries, 0)
+	for _, s := range c.Series {
+		if !s.Line {
+			bars = append(bars, s)
+		}
+	}
+	return bars
+}
+
+type chartGeometry struct {
+	slot    float64
+	bar     float64
+	scale   float64
+	plotted float64
+}
+
+func (c *Chart) geometry() *chartGeometry {
+	slot := float64(chartWidth-2*chartMargin) / float64(len(c.Labels))
+	bars := len(c.bars())
+	if bars == 0 {
+		bars = 1
+	}
+	plotted := float64(chartHeight - 2*chartMargin)
+	return &chartGeometry{
+		slot:    slot,
+		bar:     slot * 0.8 / float64(bars),
+		scale:   plotted / c.maximum(),
+		plotted: plotted,
+	}
+}
+
+func (g *chartGeometry) x(index int) float64 {
+	return chartMargin + g.slot*float64(index)
+}
+
+func (g *chartGeometry) y(value float64) float64 {
+	return chartMargin + g.plotted - value*g.scale
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (c *Chart) svg() string {
+	if len(c.Labels) == 0 {
+		return ""
+	}
+
+	g := c.geometry()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="10">`+"\n", chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<text x="%d" y="20" font-size="14">%s</text>`+"\n", chartMargin, html.EscapeString(c.Title))
+	fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#888"/>`+"\n", chartMargin, g.y(0), chartWidth-chartMargin, g.y(0))
+	fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%.0f</text>`+"\n", chartMargin-4, g.y(c.maximum()), c.maximum())
+
+	for i, s := range c.bars() {
+		for j, v := range s.Values {
+			x := g.x(j) + g.slot*0.1 + g.bar*float64(i)
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s %s: %.1f</title></rect>`+"\n",
+				x, g.y(v), g.bar, v*g.scale, svgColor(chartColors[i%len(chartColors)]), html.EscapeString(s.Name), html.EscapeString(c.Labels[j]), v)
+		}
+	}
+
+	for i, s := range c.Series {
+		if !s.Line {
+			continue
+		}
+		points := make([]string, 0)
+		for j, v := range s.Values {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", g.x(j)+g.slot/2, g.y(v)))
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n", strings.Join(points, " "), svgColor(chartColors[(i+1)%len(chartColors)]))
+	}
+
+	step := 1 + len(c.Labels)/12
+	for j, label := range c.Labels {
+		if j%step != 0 {
+			continue
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", g.x(j)+g.slot/2, chartHeight-chartMargin+14, html.EscapeString(label))
+	}
+
+	b.WriteString("</svg>\n")
+
+	return b.String()
+}
+
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	steps := int(x1-x0) + int(y1-y0)
+	if steps < 0 {
+		steps = -steps
+	}
+	steps++
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		x, y := int(x0+(x1-x0)*t), int(y0+(y1-y0)*t)
+		img.Set(x, y, c)
+		img.Set(x, y+1, c)
+	}
+}
+
+func (c *Chart) image() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	if len(c.Labels) == 0 {
+		return img
+	}
+
+	g := c.geometry()
+	axis := color.RGBA{0x88, 0x88, 0x88, 0xff}
+	drawLine(img, chartMargin, g.y(0), chartWidth-chartMargin, g.y(0), axis)
+
+	for i, s := range c.bars() {
+		fill := image.NewUniform(chartColors[i%len(chartColors)])
+		for j, v := range s.Values {
+			x := g.x(j) + g.slot*0.1 + g.bar*float64(i)
+			r := image.Rect(int(x), int(g.y(v)), int(x+g.bar), int(g.y(0)))
+			draw.Draw(img, r, fill, image.Point{}, draw.Src)
+		}
+	}
+
+	for i, s := range c.Series {
+		if !s.Line {
+			continue
+		}
+		for j := 1; j < len(s.Values); j++ {
+			drawLine(img, g.x(j-1)+g.slot/2, g.y(s.Values[j-1]), g.x(j)+g.slot/2, g.y(s.Values[j]), chartColors[(i+1)%len(chartColors)])
+		}
+	}
+
+	return img
+}
+
+func writeChart(chart *Chart, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".svg":
+		_, err = file.WriteString(chart.svg())
+	case ".png":
+		err = png.Encode(file, chart.image())
+	default:
+		return fmt.Errorf("unknown chart format: %s", filename)
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", filename, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type CIEnvironment struct {
+	Name        string
+	Version     string
+	Commit      string
+	BuildURL    string
+	Environment string
+	Target      string
+}
+
+func detectCI() *CIEnvironment {
+	ci := &CIEnvironment{}
+
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		ci.Name = "github"
+		ci.Commit = os.Getenv("GITHUB_SHA")
+		if os.Getenv("GITHUB_REF_TYPE") == "tag" {
+			ci.Version = os.Getenv("GITHUB_REF_NAME")
+		}
+		if os.Getenv("GITHUB_RUN_ID") != "" {
+			ci.BuildURL = fmt.Sprintf("%s/%s/actions/runs/%s", os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"))
+		}
+	case os.Getenv("GITLAB_CI") != "":
+		ci.Name = "gitlab"
+		ci.Commit = os.Getenv("CI_COMMIT_SHA")
+		ci.Version = os.Getenv("CI_COMMIT_TAG")
+		ci.BuildURL = os.Getenv("CI_PIPELINE_URL")
+		ci.Environment = os.Getenv("CI_ENVIRONMENT_NAME")
+	case os.Getenv("JENKINS_URL") != "":
+		ci.Name = "jenkins"
+		ci.Commit = os.Getenv("GIT_COMMIT")
+		ci.Version = os.Getenv("TAG_NAME")
+		ci.BuildURL = os.Getenv("BUILD_URL")
+	default:
+		return nil
+	}
+
+	if v := os.Getenv("JIRA_OPS_VERSION"); v != "" {
+		ci.Version = v
+	}
+	if v := os.Getenv("JIRA_OPS_ENV"); v != "" {
+		ci.Environment = v
+	}
+	ci.Target = os.Getenv("JIRA_OPS_DEPLOY_TARGET")
+	ci.Version = strings.TrimPrefix(ci.Version, "v")
+
+	return ci
+}
+
+func (d *Deployment) fillFromCI() error {
+	ci := detectCI()
+	if ci == nil {
+		return fmt.Errorf("--from-ci: no supported CI environment detected")
+	}
+
+	if d.Target == "" {
+		d.Target = ci.Target
+	}
+	if d.Version == "" {
+		d.Version = ci.Version
+	}
+	if d.Commit == "" {
+		d.Commit = ci.Commit
+	}
+	if d.BuildURL == "" {
+		d.BuildURL = ci.BuildURL
+	}
+	if d.Environment == "" {
+		d.Environment = ci.Environment
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"path"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+)
+
+func httpCacheDirectory() string {
+	return path.Join(stateDirectory(), "http")
+}
+
+func clientOptions(config *Config, options *Options) *client.Options {
+	o := &client.Options{
+		URL:      JiraUrl,
+		Username: JiraUsername,
+		Password: JiraPassword,
+		Rate:     options.Rate,
+		Burst:    options.Burst,
+		Retries:  options.Retries,
+		Timing:   options.timing,
+		HTTP:     config.HTTP,
+		Record:   options.Record,
+		Replay:   options.Replay,
+		Events:   options.events,
+	}
+	if options.HttpCache && options.Replay == "" {
+		o.CacheDirectory = httpCacheDirectory()
+	}
+	return o
+}
+
+func newClient(config *Config, options *Options) (*jira.Client, error) {
+	return client.NewWithContext(options.ctx, clientOptions(config, options))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type CommandFunc func(jc *jira.Client, config *Config, options *Options, args []string) error
+
+type Command struct {
+	Name        string
+	Description string
+	Offline     bool
+	Cached      bool
+	Run         CommandFunc
+	Subcommands []*Command
+}
+
+var commands = []*Command{
+	&Command{Name: "upkeep", Description: "apply upkeep rules", Run: upkeepCommand, Subcommands: []*Command{
+		&Command{Name: "audit", Description: "review changes made by past upkeep runs", Offline: true, Run: auditCommand},
+	}},
+	&Command{Name: "deploy", Description: "transition issues for a configured deploy target", Run: deployCommand, Subcommands: []*Command{
+		&Command{Name: "check", Description: "verify issues pending deploy pass the target's gates", Run: deployCheckCommand},
+		&Command{Name: "report", Description: "list issues deployed to one environment but not another", Run: deployReportCommand},
+		&Command{Name: "rollback", Description: "return issues moved by earlier deploy runs", Run: deployRollbackCommand},
+	}},
+	&Command{Name: "report", Description: "run a report defined in the configuration", Cached: true, Run: reportCommand, Subcommands: []*Command{
+		&Command{Name: "deploys", Description: "list past deploy runs", Offline: true, Run: reportDeploysCommand},
+		&Command{Name: "cycletime", Description: "cycle and lead time per component and issue type", Cached: true, Run: reportCycleTimeCommand},
+		&Command{Name: "aging", Description: "in-progress issues ordered by time in their current status", Cached: true, Run: reportAgingCommand},
+		&Command{Name: "blocked", Description: "issues blocked by open links or flagged", Cached: true, Run: reportBlockedCommand},
+		&Command{Name: "first-response", Description: "time from creation to first response by someone other than the reporter", Cached: true, Run: reportFirstResponseCommand},
+		&Command{Name: "components", Description: "per-component open issues, ages and resolution rate", Cached: true, Run: reportComponentsCommand},
+		&Command{Name: "epics", Description: "open epics without recent child activity or past their due date", Cached: true, Run: reportEpicsCommand},
+		&Command{Name: "qa", Description: "awaiting qa queue size, ages and originating deploy runs", Cached: true, Run: reportQACommand},
+		&Command{Name: "sprint", Description: "sprint commitment against completion and scope added mid-sprint", Run: reportSprintCommand},
+		&Command{Name: "estimates", Description: "estimates against logged time, or points against cycle time", Cached: true, Run: reportEstimatesCommand},
+		&Command{Name: "priorities", Description: "open issues per priority with age percentiles", Cached: true, Run: reportPrioritiesCommand},
+		&Command{Name: "reopened", Description: "resolved issues that were reopened, per component and fix version", Cached: true, Run: reportReopenedCommand},
+		&Command{Name: "throughput", Description: "issues resolved per week with a trend line", Cached: true, Run: reportThroughputCommand},
+	}},
+	&Command{Name: "sync", Description: "update the local issue cache", Run: syncCommand},
+	&Command{Name: "digest", Description: "summary of recent activity for slack or email", Cached: true, Run: digestCommand},
+	&Command{Name: "dashboard", Description: "dashboards", Subcommands: []*Command{
+		&Command{Name: "build", Description: "render configured reports and listings to static html", Cached: true, Run: dashboardBuildCommand},
+	}},
+	&Command{Name: "branch", Description: "create and check out a git branch for an issue", Run: branchCommand},
+	&Command{Name: "pull", Description: "start work on an issue, defaults to the current branch's issue", Run: pullCommand},
+	&Command{Name: "done", Description: "finish work on an issue, defaults to the current branch's issue", Run: doneCommand},
+	&Command{Name: "comment", Description: "comment on an issue, defaults to the current branch's issue", Run: commentCommand},
+	&Command{Name: "log", Description: "log time against an issue, defaults to the current branch's issue", Run: logCommand},
+	&Command{Name: "wip", Description: "compare local issue branches with jira statuses", Run: wipCommand},
+	&Command{Name: "commit-msg", Description: "print a commit message skeleton for an issue", Run: commitMsgCommand},
+	&Command{Name: "hooks", Description: "git hooks", Subcommands: []*Command{
+		&Command{Name: "install", Description: "install a commit-msg hook requiring issue keys", Offline: true, Run: hooksInstallCommand},
+		&Command{Name: "check", Description: "check a commit message file for an issue key", Offline: true, Run: hooksCheckCommand},
+	}},
+	&Command{Name: "pr", Description: "pull requests", Subcommands: []*Command{
+		&Command{Name: "link", Description: "link a pull request to an issue", Run: prLinkCommand},
+	}},
+	&Command{Name: "timeline", Description: "time an issue spent in each status", Cached: true, Run: timelineCommand},
+	&Command{Name: "dev", Description: "open an issue's pull request or print its branch", Run: devCommand},
+	&Command{Name: "weblink", Description: "web links", Subcommands: []*Command{
+		&Command{Name: "add", Description: "link a url to an issue", Run: webLinkAddCommand},
+		&Command{Name: "list", Description: "list urls linked to an issue", Run: webLinkListCommand},
+		&Command{Name: "delete", Description: "remove a linked url by id or url", Run: webLinkDeleteCommand},
+	}},
+	&Command{Name: "release-notes", Description: "release notes combining commit history with jira issues", Run: releaseNotesCommand},
+	&Command{Name: "merged", Description: "transition issues referenced by merged commits", Run: mergedCommand},
+	&Command{Name: "fixversion", Description: "add a fix version to issues referenced by commits", Run: fixVersionCommand},
+	&Command{Name: "dupes", Description: "report likely duplicate issues", Run: dupesCommand},
+	&Command{Name: "incident", Description: "incidents", Subcommands: []*Command{
+		&Command{Name: "import", Description: "create an issue from a pagerduty or opsgenie incident and link it back", Run: incidentImportCommand},
+	}},
+	&Command{Name: "automate", Description: "automate", Subcommands: []*Command{
+		&Command{Name: "run", Description: "transition, label, comment or notify as issues match when/then rules", Run: automateRunCommand},
+	}},
+	&Command{Name: "export", Description: "export", Subcommands: []*Command{
+		&Command{Name: "markdown", Description: "write a markdown file, with front matter and comments, per issue", Run: exportMarkdownCommand},
+	}},
+	&Command{Name: "import", Description: "import", Subcommands: []*Command{
+		&Command{Name: "github", Description: "create issues, with their comments, from a github repository's issues", Run: githubImportCommand},
+	}},
+	&Command{Name: "sentry", Description: "sentry", Subcommands: []*Command{
+		&Command{Name: "link", Description: "create a bug from a sentry issue, or link one to an existing issue", Run: sentryLinkCommand},
+	}},
+	&Command{Name: "calendar", Description: "write or serve an icalendar feed of due dates, sprints and releases", Cached: true, Run: calendarCommand},
+	&Command{Name: "feed", Description: "write an atom feed of recent issue updates and comments", Run: feedCommand},
+	&Command{Name: "notify", Description: "desktop notifications as issues assigned to you change", Run: notifyCommand},
+	&Command{Name: "serve", Description: "mirror, apply upkeep or post to slack as jira webhooks arrive", Run: serveCommand},
+}
+
+func findCommand(available []*Command, args []string) (*Command, []string) {
+	if len(args) == 0 {
+		return nil, args
+	}
+	for _, c := range available {
+		if c.Name == args[0] {
+			if sub, rest := findCommand(c.Subcommands, args[1:]); sub != nil {
+				return sub, rest
+			}
+			return c, args[1:]
+		}
+	}
+	return nil, args
+}
+
+func parseArgs(flags *flag.FlagSet, args []string) []string {
+	positional := make([]string, 0)
+	for {
+		flags.Parse(args)
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func printCommands(available []*Command, prefix string) {
+	for _, c := range available {
+		if c.Run != nil {
+			fmt.Fprintf(flag.CommandLine.Output(), "  %-24s %s\n", prefix+c.Name, c.Description)
+		}
+		printCommands(c.Subcommands, prefix+c.Name+" ")
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] [command]\n\nCommands:\n", os.Args[0])
+	printCommands(commands, "")
+	fmt.Fprintf(flag.CommandLine.Output(), "\nOptions:\n")
+	flag.PrintDefaults()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const defaultCommitTemplate = "{{ .Key }}: {{ .Summary }}\n\n"
+
+func makeCommitMessage(config *Config, issue *jira.Issue) (string, error) {
+	text := config.CommitTemplate
+	if text == "" {
+		text = defaultCommitTemplate
+	}
+
+	t, err := template.New("commit").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("commit template: %w", err)
+	}
+
+	data := struct {
+		Key     string
+		Summary string
+		Type    string
+		URL     string
+	}{
+		Key:     issue.Key,
+		Summary: issue.Fields.Summary,
+		Type:    issue.Fields.Type.Name,
+		URL:     issueURL(issue.Key),
+	}
+
+	var message bytes.Buffer
+	if err := t.Execute(&message, data); err != nil {
+		return "", fmt.Errorf("commit template: %w", err)
+	}
+
+	return message.String(), nil
+}
+
+func commitMsgCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("commit-msg", flag.ExitOnError)
+	file := flags.String("file", "", "prepend the message to this commit message file (prepare-commit-msg hook)")
+	positional := parseArgs(flags, args)
+
+	issue, _, err := getResolvedIssue(jc, options, positional)
+	if err != nil {
+		return err
+	}
+
+	message, err := makeCommitMessage(config, issue)
+	if err != nil {
+		return err
+	}
+
+	if *file == "" {
+		fmt.Print(message)
+		return nil
+	}
+
+	existing, err := ioutil.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	if strings.Contains(string(existing), issue.Key) {
+		return nil
+	}
+
+	return ioutil.WriteFile(*file, append([]byte(message), existing...), 0644)
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+)
+
+const commitSeparator = "\x1e"
+const fieldSeparator = "\x1f"
+
+type Commit struct {
+	Hash    string
+	Author  string
+	Email   string
+	Time    time.Time
+	Subject string
+	Body    string
+	Files   []string
+}
+
+func readCommits(rng string, files bool) ([]*Commit, error) {
+	args := []string{"log", "--format=" + commitSeparator + "%H" + fieldSeparator + "%an" + fieldSeparator + "%ae" + fieldSeparator + "%at" + fieldSeparator + "%s" + fieldSeparator + "%b" + fieldSeparator}
+	if files {
+		args = append(args, "--name-only")
+	}
+	args = append(args, rng)
+
+	output, err := git(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]*Commit, 0)
+	for _, entry := range strings.Split(output, commitSeparator) {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		fields := strings.SplitN(entry, fieldSeparator, 7)
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("unexpected git log output")
+		}
+
+		seconds, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected git log time: %w", err)
+		}
+
+		commit := &Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Email:   fields[2],
+			Time:    time.Unix(seconds, 0),
+			Subject: fields[4],
+			Body:    fields[5],
+			Files:   make([]string, 0),
+		}
+
+		for _, line := range strings.Split(fields[6], "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				commit.Files = append(commit.Files, line)
+			}
+		}
+
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+func (c *Config) projects(options *Options) []string {
+	if len(c.Projects) > 0 {
+		return c.Projects
+	}
+	return []string{options.Project}
+}
+
+func (c *Commit) issueKeys(projects []string) []string {
+	keys := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, m := range issueKeyRegexp.FindAllStringSubmatch(c.Subject+"\n"+c.Body, -1) {
+		key := strings.ToUpper(m[1])
+		project := strings.Split(key, "-")[0]
+		if seen[key] {
+			continue
+		}
+		for _, p := range projects {
+			if strings.EqualFold(p, project) {
+				seen[key] = true
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	return keys
+}
+
+func commitsByIssue(commits []*Commit, projects []string) map[string][]*Commit {
+	byIssue := make(map[string][]*Commit)
+	for _, c := range commits {
+		for _, key := range c.issueKeys(projects) {
+			byIssue[key] = append(byIssue[key], c)
+		}
+	}
+	return byIssue
+}
+
+func issueKeysInCommits(commits []*Commit, projects []string) []string {
+	seen := make(map[string]bool)
+	for _, c := range commits {
+		for _, key := range c.issueKeys(projects) {
+			seen[key] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+func mergedCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("merged", flag.ExitOnError)
+	rng := flags.String("range", "", "git revision range to scan (origin/main@{1.week.ago}..origin/main)")
+	to := flags.String("to", "Ready for Deploy", "status to move referenced issues to")
+	assign := flags.Bool("assign", false, "assign unassigned issues to the jira user of the commit's author")
+	components := flags.String("components", "", "check issue components against the paths commits touched (verify, set)")
+	flags.Parse(args)
+
+	if *rng == "" {
+		return fmt.Errorf("usage: merged --range <revisions> [--to <status>]")
+	}
+
+	if *components != "" && *components != "verify" && *components != "set" {
+		return fmt.Errorf("unknown components mode: %s", *components)
+	}
+
+	commits, err := readCommits(*rng, *components != "")
+	if err != nil {
+		return err
+	}
+
+	byIssue := commitsByIssue(commits, config.projects(options))
+	authors := newAuthors(options.ctx, jc, config)
+
+	log.Printf("%d commits reference %d issues", len(commits), len(byIssue))
+
+	failed := 0
+	for _, key := range issueKeysInCommits(commits, config.projects(options)) {
+		issue, _, err := jc.Issue.GetWithContext(options.ctx, key, nil)
+		if err != nil {
+			log.Printf("[%s] error getting issue: %v", key, err)
+			failed++
+			continue
+		}
+
+		if *assign {
+			if err := authors.assign(issue, byIssue[key][0], options); err != nil {
+				log.Printf("[%s] %v", key, err)
+				failed++
+			}
+		}
+
+		if *components != "" {
+			if err := applyComponents(jc, config, options, issue, byIssue[key], *components); err != nil {
+				log.Printf("[%s] %v", key, err)
+				failed++
+			}
+		}
+
+		if issue.Fields.Status.Name == *to || issue.Fields.Resolution != nil {
+			continue
+		}
+
+		if options.DryRun {
+			echoIssueActionMessage("would change", issue)
+			continue
+		}
+
+		if err := changeIssueStatus(options.ctx, jc.Issue, issue, *to); err != nil {
+			log.Printf("[%s] unable to move to '%s': %v", key, *to, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d failures across %d issues: %w", failed, len(byIssue), client.ErrPartialFailure)
+	}
+
+	return nil
+}
+
+func addFixVersion(ctx context.Context, jc *jira.Client, issue *jira.Issue, version *jira.Version) (bool, error) {
+	for _, fv := range issue.Fields.FixVersions {
+		if fv.ID == version.ID {
+			return false, nil
+		}
+	}
+
+	update := map[string]interface{}{
+		"update": map[string]interface{}{
+			"fixVersions": []map[string]interface{}{{"add": map[string]string{"id": version.ID}}},
+		},
+	}
+	if _, err := jc.Issue.UpdateIssueWithContext(ctx, issue.Key, update); err != nil {
+		return false, fmt.Errorf("error adding fix version: %w", err)
+	}
+
+	return true, nil
+}
+
+func fixVersionCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("fixversion", flag.ExitOnError)
+	rng := flags.String("range", "", "git revision range to scan (release/1.2..main)")
+	name := flags.String("version", options.Version, "version to add to referenced issues")
+	flags.Parse(args)
+
+	if *rng == "" || *name == "" {
+		return fmt.Errorf("usage: fixversion --range <revisions> --version <version>")
+	}
+
+	commits, err := readCommits(*rng, false)
+	if err != nil {
+		return err
+	}
+
+	keys := issueKeysInCommits(commits, config.projects(options))
+
+	log.Printf("%d commits reference %d issues", len(commits), len(keys))
+
+	// Versions belong to a project, so they're resolved for each project
+	// the keys are in.
+	versions := make(map[string]*jira.Version)
+
+	failed := 0
+	for _, key := range keys {
+		project := strings.SplitN(key, "-", 2)[0]
+		version, ok := versions[project]
+		if !ok {
+			version, err = findVersion(options.ctx, jc, project, *name)
+			if err != nil {
+				log.Printf("[%s] %v", key, err)
+			}
+			versions[project] = version
+		}
+		if version == nil {
+			failed++
+			continue
+		}
+
+		issue, _, err := jc.Issue.GetWithContext(options.ctx, key, nil)
+		if err != nil {
+			log.Printf("[%s] error getting issue: %v", key, err)
+			failed++
+			continue
+		}
+
+		if options.DryRun {
+			echoIssueActionMessage(fmt.Sprintf("would add %s", version.Name), issue)
+			continue
+		}
+
+		added, err := addFixVersion(options.ctx, jc, issue, version)
+		if err != nil {
+			return err
+		}
+		if added {
+			echoIssueActionMessage(fmt.Sprintf("added %s", version.Name), issue)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("unable to add %s to %d of %d issues: %w", *name, failed, len(keys), client.ErrPartialFailure)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/reports"
+)
+
+type ComponentHealth struct {
+	Name      string
+	Open      int
+	OpenBugs  int
+	Ages      []time.Duration
+	Oldest    *jira.Issue
+	OldestAge time.Duration
+	Created   int
+	Resolved  int
+}
+
+func (h *ComponentHealth) rate() string {
+	if h.Created == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", 100*float64(h.Resolved)/float64(h.Created))
+}
+
+func (h *ComponentHealth) oldestKey() string {
+	if h.Oldest == nil {
+		return "-"
+	}
+	return h.Oldest.Key
+}
+
+func reportComponentsCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report components", flag.ExitOnError)
+	jql := flags.String("jql", "", "restrict to issues matching this query")
+	since := flags.String("since", "90d", "window for the resolution rate")
+	asCSV := flags.Bool("csv", false, "write csv instead of a table")
+	flags.Parse(args)
+
+	now := time.Now()
+	after, err := parseSince(*since, now)
+	if err != nil {
+		return err
+	}
+
+	health := make(map[string]*ComponentHealth)
+	component := func(name string) *ComponentHealth {
+		if health[name] == nil {
+			health[name] = &ComponentHealth{Name: name}
+		}
+		return health[name]
+	}
+
+	searchOptions := &jira.SearchOptions{MaxResults: 100, Fields: []string{"components", "issuetype", "created", "resolutiondate", "summary"}}
+
+	err = searchIssues(jc, options, reportSearch(options, "(resolution IS EMPTY)", *jql), searchOptions, func(issue jira.Issue) error {
+		age := now.Sub(time.Time(issue.Fields.Created))
+		for _, name := range issueGroups(&issue, "component") {
+			h := component(name)
+			h.Open++
+			if issue.Fields.Type.Name == "Bug" {
+				h.OpenBugs++
+			}
+			h.Ages = append(h.Ages, age)
+			if age > h.OldestAge {
+				i := issue
+				h.Oldest = &i
+				h.OldestAge = age
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	window := jqlSince(*since)
+
+	err = searchIssues(jc, options, reportSearch(options, fmt.Sprintf("(created >= %s OR resolved >= %s)", window, window), *jql), searchOptions, func(issue jira.Issue) error {
+		for _, name := range issueGroups(&issue, "component") {
+			h := component(name)
+			if time.Time(issue.Fields.Created).After(after) {
+				h.Created++
+			}
+			if time.Time(issue.Fields.Resolutiondate).After(after) {
+				h.Resolved++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	names := make([]string, 0)
+	for name := range health {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if *asCSV {
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"component", "open", "open_bugs", "average_age_days", "oldest", "oldest_age_days", "created", "resolved", "resolution_rate"})
+		for _, name := range names {
+			h := health[name]
+			w.Write([]string{
+				name,
+				strconv.Itoa(h.Open),
+				strconv.Itoa(h.OpenBugs),
+				fmt.Sprintf("%.1f", reports.Average(h.Ages).Hours()/24),
+				h.oldestKey(),
+				fmt.Sprintf("%.1f", h.OldestAge.Hours()/24),
+				strconv.Itoa(h.Created),
+				strconv.Itoa(h.Resolved),
+				h.rate(),
+			})
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	fmt.Printf("%-20s %5s %5s %8s %-8s %8s %7s %8s %5s\n", "COMPONENT", "OPEN", "BUGS", "AVG AGE", "OLDEST", "AGE", "CREATED", "RESOLVED", "RATE")
+	for _, name := range names {
+		h := health[name]
+		fmt.Printf("%-20s %5d %5d %8s %-8s %8s %7d %8d %5s\n", name, h.Open, h.OpenBugs, reports.FormatDays(reports.Average(h.Ages)), h.oldestKey(), reports.FormatDays(h.OldestAge), h.Created, h.Resolved, h.rate())
+	}
+
+	return nil
+}
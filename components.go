@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func (c *Config) componentForPath(filename string) string {
+	best := ""
+	component := ""
+	for prefix, name := range c.ComponentPaths {
+		if strings.HasPrefix(filename, prefix) && len(prefix) > len(best) {
+			best = prefix
+			component = name
+		}
+	}
+	return component
+}
+
+func (c *Config) componentsForCommits(commits []*Commit) []string {
+	components := make(map[string]bool)
+	for _, commit := range commits {
+		for _, f := range commit.Files {
+			if name := c.componentForPath(f); name != "" {
+				components[name] = true
+			}
+		}
+	}
+	return sortedKeys(components)
+}
+
+func missingComponents(issue *jira.Issue, expected []string) []string {
+	missing := make([]string, 0)
+	for _, name := range expected {
+		found := false
+		for _, c := range issue.Fields.Components {
+			if strings.EqualFold(c.Name, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func applyComponents(jc *jira.Client, config *Config, options *Options, issue *jira.Issue, commits []*Commit, mode string) error {
+	missing := missingComponents(issue, config.componentsForCommits(commits))
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if mode == "verify" {
+		log.Printf("[%s] commits touch components missing from the issue: %s", issue.Key, strings.Join(missing, ", "))
+		return nil
+	}
+
+	echoIssueActionMessage(fmt.Sprintf("adding components %s", strings.Join(missing, ", ")), issue)
+
+	if options.DryRun {
+		return nil
+	}
+
+	adds := make([]map[string]interface{}, 0)
+	for _, name := range missing {
+		adds = append(adds, map[string]interface{}{"add": map[string]string{"name": name}})
+	}
+
+	update := map[string]interface{}{
+		"update": map[string]interface{}{
+			"components": adds,
+		},
+	}
+	if _, err := jc.Issue.UpdateIssueWithContext(options.ctx, issue.Key, update); err != nil {
+		return fmt.Errorf("error adding components: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+)
+
+type RuleConfig struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Pattern  string   `json:"pattern"`
+	Replace  string   `json:"replace"`
+	Enabled  *bool    `json:"enabled"`
+	Projects []string `json:"projects"`
+	JQL      string   `json:"jql"`
+}
+
+type StaleConfig struct {
+	Status    string `json:"status"`
+	Days      int    `json:"days"`
+	Action    string `json:"action"`
+	Comment   string `json:"comment"`
+	Label     string `json:"label"`
+	FlagField string `json:"flagField"`
+}
+
+type LabelConfig struct {
+	Label    string   `json:"label"`
+	Patterns []string `json:"patterns"`
+}
+
+type NoiseConfig struct {
+	Name     string   `json:"name"`
+	Authors  []string `json:"authors"`
+	Patterns []string `json:"patterns"`
+	Action   string   `json:"action"`
+}
+
+type TemplateConfig struct {
+	Type     string   `json:"type"`
+	Sections []string `json:"sections"`
+	Action   string   `json:"action"`
+}
+
+type AttachmentNamesConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Patterns []string `json:"patterns"`
+	Rename   bool     `json:"rename"`
+}
+
+type DeployGatesConfig struct {
+	FixVersion bool     `json:"fixVersion"`
+	Blockers   bool     `json:"blockers"`
+	Fields     []string `json:"fields"`
+}
+
+type DeployTargetConfig struct {
+	Status        string             `json:"status"`
+	Components    []string           `json:"components"`
+	Destination   string             `json:"destination"`
+	VersionPrefix string             `json:"versionPrefix"`
+	Gates         *DeployGatesConfig `json:"gates"`
+}
+
+type DeploymentsConfig struct {
+	CloudID      string `json:"cloudId"`
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	URL          string `json:"url"`
+}
+
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+}
+
+// Channels maps a notification (deploy, qa or digest) to the channel it's
+// posted to, notifications without a channel aren't sent.
+type SlackConfig struct {
+	Webhook  string            `json:"webhook"`
+	Token    string            `json:"token"`
+	Channels map[string]string `json:"channels"`
+}
+
+// Webhooks maps the same notifications as SlackConfig's channels to Teams
+// incoming webhooks.
+type TeamsConfig struct {
+	Webhooks map[string]string `json:"webhooks"`
+}
+
+// Events are issue_created, issue_updated and attachment_added, an action
+// without events runs for all of them. Channel names a slack channel.
+type ServeActionConfig struct {
+	Events  []string `json:"events"`
+	Action  string   `json:"action"`
+	Channel string   `json:"channel"`
+}
+
+type ServeConfig struct {
+	Listen  string               `json:"listen"`
+	Secret  string               `json:"secret"`
+	Actions []*ServeActionConfig `json:"actions"`
+}
+
+// FeedConfig is the feed command's defaults and, when set, what serve
+// publishes as /feed.xml?secret=. Since defaults to 7d and Limit to 100 entries.
+type FeedConfig struct {
+	JQL   string `json:"jql"`
+	Since string `json:"since"`
+	Title string `json:"title"`
+	Limit *int   `json:"limit"`
+}
+
+// ConfluenceConfig defaults to the wiki on the Jira site, with the same
+// credentials.
+type ConfluenceConfig struct {
+	URL string `json:"url"`
+}
+
+// IncidentsConfig maps incident severities (P1, or high and low urgency) to
+// Jira priorities, on top of the defaults. From is the PagerDuty user notes
+// are posted as.
+type IncidentsConfig struct {
+	Provider   string            `json:"provider"`
+	Token      string            `json:"token"`
+	From       string            `json:"from"`
+	URL        string            `json:"url"`
+	Project    string            `json:"project"`
+	IssueType  string            `json:"issueType"`
+	Labels     []string          `json:"labels"`
+	Priorities map[string]string `json:"priorities"`
+}
+
+// SentryConfig's URL overrides the api's host, which is otherwise the host
+// of the linked issue.
+type SentryConfig struct {
+	Token     string   `json:"token"`
+	URL       string   `json:"url"`
+	Project   string   `json:"project"`
+	IssueType string   `json:"issueType"`
+	Labels    []string `json:"labels"`
+}
+
+// GitHubConfig's token defaults to $GITHUB_TOKEN and URL to the public
+// api, set it for GitHub Enterprise.
+type GitHubConfig struct {
+	Token     string   `json:"token"`
+	URL       string   `json:"url"`
+	Repo      string   `json:"repo"`
+	Project   string   `json:"project"`
+	IssueType string   `json:"issueType"`
+	Labels    []string `json:"labels"`
+}
+
+// TempoConfig switches worklogs to Tempo Cloud. Account is the default
+// account key logged against, stored in the AccountAttribute work attribute
+// (_Account_ unless configured), and Attributes are defaults for the rest.
+type TempoConfig struct {
+	Token            string            `json:"token"`
+	URL              string            `json:"url"`
+	Account          string            `json:"account"`
+	AccountAttribute string            `json:"accountAttribute"`
+	Attributes       map[string]string `json:"attributes"`
+}
+
+type HandoffConfig struct {
+	SlackWebhook   string   `json:"slackWebhook"`
+	Email          []string `json:"email"`
+	TestNotesField string   `json:"testNotesField"`
+}
+
+type ReportConfig struct {
+	Description  string   `json:"description"`
+	JQL          string   `json:"jql"`
+	GroupBy      []string `json:"groupBy"`
+	Aggregations []string `json:"aggregations"`
+	Format       string   `json:"format"`
+}
+
+type ListingConfig struct {
+	Title string `json:"title"`
+	JQL   string `json:"jql"`
+}
+
+type DashboardConfig struct {
+	Title    string           `json:"title"`
+	Reports  []string         `json:"reports"`
+	Listings []*ListingConfig `json:"listings"`
+}
+
+// DownloaderConfig mirrors links matching Pattern with a provider
+// (http, http-bearer, s3-presigned, jira-attachment). Token and Headers
+// expand environment variables, URL and SaveAs expand Pattern's groups.
+type DownloaderConfig struct {
+	Name     string            `json:"name"`
+	Provider string            `json:"provider"`
+	Pattern  string            `json:"pattern"`
+	URL      string            `json:"url"`
+	SaveAs   string            `json:"saveAs"`
+	Token    string            `json:"token"`
+	Headers  map[string]string `json:"headers"`
+}
+
+type EventHookConfig struct {
+	Event   string `json:"event"`
+	Command string `json:"command"`
+	Timeout string `json:"timeout"`
+}
+
+type Config struct {
+	Projects []string `json:"projects"`
+	// Authors maps commit emails or author names to Jira usernames.
+	Authors map[string]string `json:"authors"`
+
+	ComponentPaths map[string]string `json:"componentPaths"`
+
+	PointsField string                   `json:"pointsField"`
+	Board       int                      `json:"board"`
+	Reports     map[string]*ReportConfig `json:"reports"`
+	Dashboard   *DashboardConfig         `json:"dashboard"`
+
+	BranchTemplate string `json:"branchTemplate"`
+	DoneStatus     string `json:"doneStatus"`
+	CommitTemplate string `json:"commitTemplate"`
+
+	OptOutLabel string            `json:"optOutLabel"`
+	Skip        []string          `json:"skip"`
+	Rules       []*RuleConfig     `json:"rules"`
+	Stale       []*StaleConfig    `json:"stale"`
+	Labels      []*LabelConfig    `json:"labels"`
+	Noise       []*NoiseConfig    `json:"noise"`
+	Templates   []*TemplateConfig `json:"templates"`
+
+	AttachmentNames *AttachmentNamesConfig `json:"attachmentNames"`
+
+	Deploy      map[string]*DeployTargetConfig `json:"deploy"`
+	Deployments *DeploymentsConfig             `json:"deployments"`
+
+	EnvironmentLabelPrefix string `json:"environmentLabelPrefix"`
+
+	Handoff *HandoffConfig      `json:"handoff"`
+	SMTP    *SMTPConfig         `json:"smtp"`
+	Mail    map[string][]string `json:"mail"`
+	Slack   *SlackConfig        `json:"slack"`
+	Teams   *TeamsConfig        `json:"teams"`
+
+	HTTP *client.HTTPConfig `json:"http"`
+
+	Events []*EventHookConfig `json:"events"`
+
+	Downloaders []*DownloaderConfig `json:"downloaders"`
+
+	Serve *ServeConfig `json:"serve"`
+
+	Confluence *ConfluenceConfig `json:"confluence"`
+
+	Incidents *IncidentsConfig `json:"incidents"`
+	Sentry    *SentryConfig    `json:"sentry"`
+	GitHub    *GitHubConfig    `json:"github"`
+	Tempo     *TempoConfig     `json:"tempo"`
+	Feed      *FeedConfig      `json:"feed"`
+}
+
+func (c *Config) skipped(issue *jira.Issue) bool {
+	label := c.OptOutLabel
+	if label == "" {
+		label = "no-bot-edit"
+	}
+
+	if hasLabel(issue, label) {
+		return true
+	}
+
+	for _, key := range c.Skip {
+		if strings.EqualFold(key, issue.Key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func defaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return path.Join(home, ".jira-ops", "config.json")
+}
+
+func loadConfig(filename string) (*Config, error) {
+	config := &Config{}
+
+	if filename == "" {
+		return config, nil
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filename, err)
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filename, err)
+	}
+
+	return config, nil
+}
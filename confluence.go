@@ -0,0 +1,58 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/jlewallen/jira-ops/jiraops/client"
+	"github.com/jlewallen/jira-ops/jiraops/confluence"
+)
+
+func newConfluence(config *Config, options *Options) (*confluence.Client, error) {
+	o := clientOptions(config, options)
+	o.CacheDirectory, o.Record, o.Replay, o.Events = "", "", "", nil
+
+	hc, err := client.NewHTTPClient(o)
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimSuffix(JiraUrl, "/") + "/wiki"
+	if config.Confluence != nil && config.Confluence.URL != "" {
+		url = config.Confluence.URL
+	}
+
+	return &confluence.Client{URL: url, Username: JiraUsername, Password: JiraPassword, HTTP: hc}, nil
+}
+
+// publishConfluence saves body, in storage format, as the page title at
+// location, given as SPACE/Parent Page.
+func publishConfluence(config *Config, options *Options, location, title, body string) error {
+	space, parent, err := confluence.ParseLocation(location)
+	if err != nil {
+		return err
+	}
+
+	if options.DryRun {
+		log.Printf("confluence: would publish '%s' to %s", title, location)
+		return nil
+	}
+
+	cc, err := newConfluence(config, options)
+	if err != nil {
+		return err
+	}
+
+	page, changed, err := cc.Publish(options.ctx, space, parent, title, body)
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		log.Printf("confluence: published '%s' %s", title, page.WebURL(cc.URL))
+	} else {
+		log.Printf("confluence: '%s' unchanged", title)
+	}
+
+	return nil
+}
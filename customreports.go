@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/reports"
+	"github.com/jlewallen/jira-ops/jiraops/schema"
+)
+
+type ReportRow struct {
+	Group  []string  `json:"group"`
+	Values []float64 `json:"values"`
+	issues int
+	ages   []time.Duration
+	sums   map[string]float64
+	counts map[string]int
+}
+
+func numericField(issue *jira.Issue, field string) (float64, bool) {
+	value, ok := issue.Fields.Unknowns[field].(float64)
+	return value, ok
+}
+
+func (c *Config) aggregationField(aggregation string) (string, string) {
+	if aggregation == "points" {
+		return "sum", c.PointsField
+	}
+	parts := strings.SplitN(aggregation, ":", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return aggregation, ""
+}
+
+func (r *ReportRow) add(config *Config, report *ReportConfig, issue *jira.Issue, now time.Time) {
+	r.issues++
+	r.ages = append(r.ages, now.Sub(time.Time(issue.Fields.Created)))
+	for _, a := range report.Aggregations {
+		_, field := config.aggregationField(a)
+		if field == "" {
+			continue
+		}
+		if value, ok := numericField(issue, field); ok {
+			r.sums[field] += value
+			r.counts[field]++
+		}
+	}
+}
+
+func (r *ReportRow) finish(config *Config, report *ReportConfig) error {
+	for _, a := range report.Aggregations {
+		kind, field := config.aggregationField(a)
+		switch kind {
+		case "count":
+			r.Values = append(r.Values, float64(r.issues))
+		case "avg-age":
+			r.Values = append(r.Values, reports.Average(r.ages).Hours()/24)
+		case "max-age":
+			r.Values = append(r.Values, reports.Percentile(r.ages, 100).Hours()/24)
+		case "sum":
+			r.Values = append(r.Values, r.sums[field])
+		case "avg":
+			if r.counts[field] == 0 {
+				r.Values = append(r.Values, 0)
+			} else {
+				r.Values = append(r.Values, r.sums[field]/float64(r.counts[field]))
+			}
+		default:
+			return fmt.Errorf("unknown aggregation: %s", a)
+		}
+	}
+	return nil
+}
+
+func groupCombinations(issue *jira.Issue, fields []string) [][]string {
+	combinations := [][]string{{}}
+	for _, field := range fields {
+		next := make([][]string, 0)
+		for _, c := range combinations {
+			for _, value := range issueGroups(issue, field) {
+				next = append(next, append(append([]string{}, c...), value))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+func runReport(jc *jira.Client, config *Config, options *Options, report *ReportConfig) ([]*ReportRow, error) {
+	if report.JQL == "" {
+		return nil, fmt.Errorf("report is missing jql")
+	}
+
+	if len(report.Aggregations) == 0 {
+		report.Aggregations = []string{"count"}
+	}
+
+	rows := make(map[string]*ReportRow)
+	now := time.Now()
+
+	fields := withFields([]string{"created"}, report.GroupBy...)
+	for _, a := range report.Aggregations {
+		_, field := config.aggregationField(a)
+		fields = withFields(fields, field)
+	}
+
+	err := searchIssues(jc, options, reportSearch(options, report.JQL, ""), &jira.SearchOptions{MaxResults: 100, Fields: fields}, func(issue jira.Issue) error {
+		for _, group := range groupCombinations(&issue, report.GroupBy) {
+			id := strings.Join(group, "\x00")
+			if rows[id] == nil {
+				rows[id] = &ReportRow{Group: group, sums: make(map[string]float64), counts: make(map[string]int)}
+			}
+			rows[id].add(config, report, &issue, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting issues: %w", err)
+	}
+
+	sorted := make([]*ReportRow, 0, len(rows))
+	for _, id := range sortedKeys(keysOfRows(rows)) {
+		if err := rows[id].finish(config, report); err != nil {
+			return nil, err
+		}
+		sorted = append(sorted, rows[id])
+	}
+
+	return sorted, nil
+}
+
+func keysOfRows(rows map[string]*ReportRow) map[string]bool {
+	keys := make(map[string]bool)
+	for k := range rows {
+		keys[k] = true
+	}
+	return keys
+}
+
+func formatReportValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func writeReport(w io.Writer, name string, report *ReportConfig, rows []*ReportRow, format string) error {
+	header := append(append([]string{}, report.GroupBy...), report.Aggregations...)
+
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		for _, r := range rows {
+			record := append([]string{}, r.Group...)
+			for _, v := range r.Values {
+				record = append(record, formatReportValue(v))
+			}
+			cw.Write(record)
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json":
+		doc := schema.NewDocument(schema.KindReport)
+		doc.Report = &schema.Report{
+			Name:         name,
+			GroupBy:      append([]string{}, report.GroupBy...),
+			Aggregations: append([]string{}, report.Aggregations...),
+			Rows:         make([]*schema.ReportRow, 0, len(rows)),
+		}
+		for _, r := range rows {
+			doc.Report.Rows = append(doc.Report.Rows, &schema.ReportRow{Group: r.Group, Values: r.Values})
+		}
+		return doc.Write(w)
+	case "table", "":
+		for _, h := range header {
+			fmt.Fprintf(w, "%-20s ", strings.ToUpper(h))
+		}
+		fmt.Fprintln(w)
+		for _, r := range rows {
+			for _, g := range r.Group {
+				fmt.Fprintf(w, "%-20s ", g)
+			}
+			for _, v := range r.Values {
+				fmt.Fprintf(w, "%-20s ", strconv.FormatFloat(v, 'f', 1, 64))
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown report format: %s", format)
+}
+
+func reportCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	if len(args) == 0 {
+		names := make([]string, 0)
+		for name := range config.Reports {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("  %-24s %s\n", name, config.Reports[name].Description)
+		}
+		return nil
+	}
+
+	report, ok := config.Reports[args[0]]
+	if !ok {
+		return fmt.Errorf("no such report: %s", args[0])
+	}
+
+	flags := flag.NewFlagSet("report "+args[0], flag.ExitOnError)
+	format := flags.String("format", report.Format, "output format (table, csv, json)")
+	mail := flags.String("mail", "", "email the report to a configured mailing list or comma separated addresses")
+	flags.Parse(args[1:])
+
+	if options.JSON {
+		*format = "json"
+	}
+
+	rows, err := runReport(jc, config, options, report)
+	if err != nil {
+		return err
+	}
+
+	if *mail == "" {
+		return writeReport(os.Stdout, args[0], report, rows, *format)
+	}
+
+	var body bytes.Buffer
+	if err := writeReport(&body, args[0], report, rows, *format); err != nil {
+		return err
+	}
+
+	mailer, err := config.mailer(*mail, false)
+	if err != nil {
+		return err
+	}
+
+	title := fmt.Sprintf("%s report %s", args[0], time.Now().Format("2006/01/02"))
+	if report.Description != "" {
+		title = fmt.Sprintf("%s (%s)", title, report.Description)
+	}
+
+	return mailer.Notify(options.ctx, title, body.String())
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/reports"
+)
+
+type CycleTimes struct {
+	Cycle []time.Duration
+	Lead  []time.Duration
+}
+
+func issueGroups(issue *jira.Issue, by string) []string {
+	switch by {
+	case "component":
+		groups := make([]string, 0)
+		for _, c := range issue.Fields.Components {
+			groups = append(groups, c.Name)
+		}
+		if len(groups) == 0 {
+			groups = append(groups, "(none)")
+		}
+		return groups
+	case "type":
+		return []string{issue.Fields.Type.Name}
+	case "assignee":
+		if issue.Fields.Assignee == nil {
+			return []string{"(unassigned)"}
+		}
+		return []string{issue.Fields.Assignee.DisplayName}
+	case "status":
+		return []string{issue.Fields.Status.Name}
+	case "priority":
+		if issue.Fields.Priority == nil {
+			return []string{"(none)"}
+		}
+		return []string{issue.Fields.Priority.Name}
+	case "label", "labels":
+		if len(issue.Fields.Labels) == 0 {
+			return []string{"(none)"}
+		}
+		return issue.Fields.Labels
+	case "fixVersion", "fixVersions":
+		groups := make([]string, 0)
+		for _, v := range issue.Fields.FixVersions {
+			groups = append(groups, v.Name)
+		}
+		if len(groups) == 0 {
+			groups = append(groups, "(none)")
+		}
+		return groups
+	case "":
+		return []string{"all"}
+	}
+	return []string{customFieldText(issue, by)}
+}
+
+func customFieldText(issue *jira.Issue, field string) string {
+	switch value := issue.Fields.Unknowns[field].(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return value
+	case map[string]interface{}:
+		for _, key := range []string{"value", "name", "displayName"} {
+			if s, ok := value[key].(string); ok {
+				return s
+			}
+		}
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", issue.Fields.Unknowns[field])
+}
+
+func reportSearch(options *Options, base, jql string) string {
+	search := base
+	if jql != "" {
+		search = fmt.Sprintf("(%s) AND (%s)", search, jql)
+	}
+	if options.projectSet {
+		search += fmt.Sprintf(" AND (project = '%s')", options.Project)
+	}
+	return search
+}
+
+func reportCycleTimeCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report cycletime", flag.ExitOnError)
+	jql := flags.String("jql", "", "restrict to issues matching this query")
+	since := flags.String("since", "90d", "only include issues resolved within this window")
+	start := flags.String("start", "In Progress", "status that starts the cycle")
+	flags.Parse(args)
+
+	after, err := parseSince(*since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	search := reportSearch(options, fmt.Sprintf("(resolution IS NOT EMPTY) AND (resolved >= %s)", jqlSince(*since)), *jql)
+
+	groups := map[string]map[string]*CycleTimes{
+		"component": make(map[string]*CycleTimes),
+		"type":      make(map[string]*CycleTimes),
+	}
+
+	total := 0
+	err = searchIssues(jc, options, search, &jira.SearchOptions{Expand: "changelog", MaxResults: 100, Fields: withFields([]string{"created", "resolution", "resolutiondate", "status"}, "component", "type")}, func(issue jira.Issue) error {
+		resolved := time.Time(issue.Fields.Resolutiondate)
+		lead := resolved.Sub(time.Time(issue.Fields.Created))
+		started, ok := reports.FirstEntered(&issue, *start)
+
+		total++
+
+		for by, times := range groups {
+			for _, g := range issueGroups(&issue, by) {
+				if times[g] == nil {
+					times[g] = &CycleTimes{}
+				}
+				times[g].Lead = append(times[g].Lead, lead)
+				if ok && started.Before(resolved) {
+					times[g].Cycle = append(times[g].Cycle, resolved.Sub(started))
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	for _, by := range []string{"component", "type"} {
+		names := make([]string, 0)
+		for name := range groups[by] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Printf("%-24s %6s %10s %10s %10s %10s\n", by, "N", "CYCLE p50", "CYCLE p85", "LEAD p50", "LEAD p85")
+		for _, name := range names {
+			t := groups[by][name]
+			fmt.Printf("%-24s %6d %10s %10s %10s %10s\n", name, len(t.Lead),
+				reports.FormatDays(reports.Percentile(t.Cycle, 50)), reports.FormatDays(reports.Percentile(t.Cycle, 85)),
+				reports.FormatDays(reports.Percentile(t.Lead, 50)), reports.FormatDays(reports.Percentile(t.Lead, 85)))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%d issues resolved since %s\n", total, after.Format("2006/01/02"))
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const dashboardTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { padding: 0.25em 0.75em; text-align: left; border-bottom: 1px solid #ddd; }
+td.number { text-align: right; }
+.bar { background: #4a90d9; height: 0.8em; }
+.generated { color: #888; font-size: small; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p class="generated">Generated {{ .Generated }}</p>
+{{ range .Reports }}
+<h2>{{ .Name }}</h2>
+{{ if .Description }}<p>{{ .Description }}</p>{{ end }}
+{{ .Chart }}
+<table>
+<tr>{{ range .Columns }}<th>{{ . }}</th>{{ end }}<th></th></tr>
+{{ range .Rows }}<tr>{{ range .Group }}<td>{{ . }}</td>{{ end }}{{ range .Values }}<td class="number">{{ . }}</td>{{ end }}<td><div class="bar" style="width: {{ .Width }}px"></div></td></tr>
+{{ end }}
+</table>
+{{ end }}
+{{ range .Listings }}
+<h2>{{ .Title }}</h2>
+<table>
+<tr><th>Issue</th><th>Status</th><th>Assignee</th><th>Summary</th></tr>
+{{ range .Issues }}<tr><td><a href="{{ .URL }}">{{ .Key }}</a></td><td>{{ .Status }}</td><td>{{ .Assignee }}</td><td>{{ .Summary }}</td></tr>
+{{ end }}
+</table>
+{{ end }}
+</body>
+</html>
+`
+
+// Confluence's storage format has no room for the styles and charts, just
+// the tables.
+const confluenceDashboardTemplate = `<p><em>Generated {{ .Generated }}</em></p>
+{{ range .Reports }}<h2>{{ .Name }}</h2>
+{{ if .Description }}<p>{{ .Description }}</p>
+{{ end }}<table><tbody>
+<tr>{{ range .Columns }}<th>{{ . }}</th>{{ end }}</tr>
+{{ range .Rows }}<tr>{{ range .Group }}<td>{{ . }}</td>{{ end }}{{ range .Values }}<td>{{ . }}</td>{{ end }}</tr>
+{{ end }}</tbody></table>
+{{ end }}{{ range .Listings }}<h2>{{ .Title }}</h2>
+<table><tbody>
+<tr><th>Issue</th><th>Status</th><th>Assignee</th><th>Summary</th></tr>
+{{ range .Issues }}<tr><td><a href="{{ .URL }}">{{ .Key }}</a></td><td>{{ .Status }}</td><td>{{ .Assignee }}</td><td>{{ .Summary }}</td></tr>
+{{ end }}</tbody></table>
+{{ end }}`
+
+const dashboardBarWidth = 200
+
+type DashboardRow struct {
+	Group  []string
+	Values []string
+	Width  int
+}
+
+type DashboardReport struct {
+	Name        string
+	Description string
+	Columns     []string
+	Rows        []*DashboardRow
+	Chart       template.HTML
+}
+
+type DashboardIssue struct {
+	Key      string
+	URL      string
+	Status   string
+	Assignee string
+	Summary  string
+}
+
+type DashboardListing struct {
+	Title  string
+	Issues []*DashboardIssue
+}
+
+type Dashboard struct {
+	Title     string
+	Generated string
+	Reports   []*DashboardReport
+	Listings  []*DashboardListing
+}
+
+func makeDashboardReport(name string, report *ReportConfig, rows []*ReportRow) *DashboardReport {
+	maximum := 0.0
+	for _, r := range rows {
+		if len(r.Values) > 0 && r.Values[0] > maximum {
+			maximum = r.Values[0]
+		}
+	}
+
+	dr := &DashboardReport{
+		Name:        name,
+		Description: report.Description,
+		Columns:     append(append([]string{}, report.GroupBy...), report.Aggregations...),
+	}
+
+	chart := &Chart{Title: name}
+	if len(report.Aggregations) > 0 {
+		chart.Series = []*ChartSeries{{Name: report.Aggregations[0]}}
+	}
+
+	for _, r := range rows {
+		if len(r.Values) > 0 {
+			chart.Labels = append(chart.Labels, strings.Join(r.Group, " / "))
+			chart.Series[0].Values = append(chart.Series[0].Values, r.Values[0])
+		}
+
+		row := &DashboardRow{Group: r.Group}
+		for _, v := range r.Values {
+			row.Values = append(row.Values, fmt.Sprintf("%.1f", v))
+		}
+		if maximum > 0 && len(r.Values) > 0 {
+			row.Width = int(dashboardBarWidth * r.Values[0] / maximum)
+		}
+		dr.Rows = append(dr.Rows, row)
+	}
+
+	if len(rows) > 1 {
+		dr.Chart = template.HTML(chart.svg())
+	}
+
+	return dr
+}
+
+func makeDashboard(jc *jira.Client, config *Config, options *Options) (*Dashboard, error) {
+	dashboard := config.Dashboard
+	if dashboard == nil {
+		dashboard = &DashboardConfig{}
+	}
+
+	names := dashboard.Reports
+	if len(names) == 0 {
+		for name := range config.Reports {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	data := &Dashboard{
+		Title:     valueOr(dashboard.Title, "Jira"),
+		Generated: time.Now().Format("2006/01/02 15:04"),
+	}
+
+	for _, name := range names {
+		report, ok := config.Reports[name]
+		if !ok {
+			return nil, fmt.Errorf("no such report: %s", name)
+		}
+
+		log.Printf("dashboard: report %s", name)
+
+		rows, err := runReport(jc, config, options, report)
+		if err != nil {
+			return nil, err
+		}
+
+		data.Reports = append(data.Reports, makeDashboardReport(name, report, rows))
+	}
+
+	for _, l := range dashboard.Listings {
+		log.Printf("dashboard: listing %s", l.Title)
+
+		listing := &DashboardListing{Title: l.Title}
+		err := searchIssues(jc, options, l.JQL, &jira.SearchOptions{MaxResults: 100, Fields: listingFields}, func(issue jira.Issue) error {
+			listing.Issues = append(listing.Issues, &DashboardIssue{
+				Key:      issue.Key,
+				URL:      issueURL(issue.Key),
+				Status:   issue.Fields.Status.Name,
+				Assignee: assigneeName(&issue),
+				Summary:  issue.Fields.Summary,
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error getting issues: %w", err)
+		}
+
+		data.Listings = append(data.Listings, listing)
+	}
+
+	return data, nil
+}
+
+func dashboardBuildCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("dashboard build", flag.ExitOnError)
+	out := flags.String("out", "site", "directory to write the dashboard to")
+	publish := flags.String("publish-confluence", "", "also publish the dashboard as a confluence page beneath SPACE/Parent Page")
+	flags.Parse(args)
+
+	data, err := makeDashboard(jc, config, options)
+	if err != nil {
+		return err
+	}
+
+	t, err := template.New("dashboard").Parse(dashboardTemplate)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", *out, err)
+	}
+
+	filename := path.Join(*out, "index.html")
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	if err := t.Execute(file, data); err != nil {
+		return fmt.Errorf("rendering dashboard: %w", err)
+	}
+
+	log.Printf("dashboard: wrote %s", filename)
+
+	if *publish != "" {
+		t, err := template.New("confluence").Parse(confluenceDashboardTemplate)
+		if err != nil {
+			return err
+		}
+		var body bytes.Buffer
+		if err := t.Execute(&body, data); err != nil {
+			return fmt.Errorf("rendering dashboard: %w", err)
+		}
+		return publishConfluence(config, options, *publish, data.Title, body.String())
+	}
+
+	return nil
+}
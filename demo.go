@@ -0,0 +1,221 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+	"github.com/jlewallen/jira-ops/jiraops/jiratest"
+)
+
+const demoUser = "demo"
+
+var demoStatuses = []string{"To Do", "In Progress", "Awaiting QA", "Done"}
+
+type demoIssue struct {
+	Project   string
+	Type      string
+	Summary   string
+	Status    string
+	Assignee  string
+	Component string
+	Labels    []string
+	Age       int
+	InStatus  int
+	Priority  string
+}
+
+var demoIssues = []*demoIssue{
+	{"FK", "Bug", "Station stops uploading after firmware update", "In Progress", "demo", "firmware", nil, 21, 9, "High"},
+	{"FK", "Story", "Show battery history on the station page", "Awaiting QA", "alice", "portal", nil, 30, 4, "Medium"},
+	{"FK", "Bug", "Export fails for stations with no readings", "To Do", "", "portal", []string{"export"}, 12, 12, "High"},
+	{"FK", "Task", "Rotate staging certificates", "Done", "bob", "infrastructure", nil, 45, 20, "Low"},
+	{"FK", "Story", "Offline map tiles in the app", "In Progress", "alice", "app", nil, 60, 35, "Medium"},
+	{"FK", "Bug", "Crash when pairing over bluetooth on older phones", "Awaiting QA", "demo", "app", []string{"regression"}, 8, 2, "Highest"},
+	{"FK", "Task", "Document calibration steps for water modules", "To Do", "bob", "firmware", []string{"docs"}, 90, 90, "Low"},
+	{"FK", "Story", "Notify followers when a station goes quiet", "To Do", "", "portal", nil, 5, 5, "Medium"},
+	{"FK", "Bug", "Chart tooltip shows UTC instead of local time", "Done", "alice", "portal", nil, 25, 6, "Low"},
+	{"FK", "Story", "Bulk edit station names", "In Progress", "demo", "portal", nil, 14, 3, "Medium"},
+	{"APP", "Bug", "Login button unresponsive on first launch", "Awaiting QA", "bob", "app", nil, 10, 1, "High"},
+	{"APP", "Story", "Dark mode", "To Do", "", "app", []string{"design"}, 120, 120, "Lowest"},
+}
+
+func demoHistory(status string, age, inStatus int, now time.Time) *jira.Changelog {
+	changelog := &jira.Changelog{}
+
+	index := 0
+	for i, s := range demoStatuses {
+		if s == status {
+			index = i
+		}
+	}
+	if index == 0 {
+		return changelog
+	}
+
+	for i := 1; i <= index; i++ {
+		days := age - (age-inStatus)*i/index
+		if i == index {
+			days = inStatus
+		}
+		changelog.Histories = append(changelog.Histories, jira.ChangelogHistory{
+			Id:      fmt.Sprintf("%d", i),
+			Author:  jira.User{Name: demoUser, AccountID: demoUser},
+			Created: now.Add(-time.Duration(days) * 24 * time.Hour).Format("2006-01-02T15:04:05.000-0700"),
+			Items:   []jira.ChangelogItems{{Field: "status", FieldType: "jira", FromString: demoStatuses[i-1], ToString: demoStatuses[i]}},
+		})
+	}
+
+	return changelog
+}
+
+func demoFake() *jiratest.Fake {
+	fake := jiratest.NewFake()
+	fake.User = demoUser
+
+	now := time.Now()
+	numbers := make(map[string]int)
+
+	for _, d := range demoIssues {
+		numbers[d.Project]++
+
+		fields := &jira.IssueFields{
+			Project:     jira.Project{Key: d.Project, Name: d.Project},
+			Type:        jira.IssueType{Name: d.Type},
+			Summary:     d.Summary,
+			Description: fmt.Sprintf("%s.\n\nSee https://example.com/%s for details.   \n\n\n", d.Summary, strings.ToLower(d.Project)),
+			Status:      &jira.Status{Name: d.Status},
+			Priority:    &jira.Priority{Name: d.Priority},
+			Labels:      d.Labels,
+			Components:  []*jira.Component{{Name: d.Component}},
+			Created:     jira.Time(now.Add(-time.Duration(d.Age) * 24 * time.Hour)),
+			Updated:     jira.Time(now.Add(-time.Duration(d.InStatus) * 24 * time.Hour)),
+			Reporter:    &jira.User{Name: demoUser, AccountID: demoUser, DisplayName: "Demo User"},
+			Comments:    &jira.Comments{},
+		}
+		if d.Assignee != "" {
+			fields.Assignee = &jira.User{Name: d.Assignee, AccountID: d.Assignee, DisplayName: strings.Title(d.Assignee)}
+		}
+		if d.Status == "Done" {
+			fields.Resolution = &jira.Resolution{Name: "Done"}
+			fields.Resolutiondate = fields.Updated
+		}
+
+		fake.Add(&jira.Issue{
+			Key:       fmt.Sprintf("%s-%d", d.Project, numbers[d.Project]),
+			Fields:    fields,
+			Changelog: demoHistory(d.Status, d.Age, d.InStatus, now),
+		})
+	}
+
+	blocks := jira.IssueLinkType{Name: "Blocks", Inward: "is blocked by", Outward: "blocks"}
+	blocked, blocker := fake.Issue("FK-3"), fake.Issue("FK-1")
+	blocked.Fields.IssueLinks = []*jira.IssueLink{{Type: blocks, InwardIssue: &jira.Issue{Key: blocker.Key, Fields: &jira.IssueFields{Summary: blocker.Fields.Summary, Status: blocker.Fields.Status}}}}
+	blocker.Fields.IssueLinks = []*jira.IssueLink{{Type: blocks, OutwardIssue: &jira.Issue{Key: blocked.Key, Fields: &jira.IssueFields{Summary: blocked.Fields.Summary, Status: blocked.Fields.Status}}}}
+
+	for _, d := range []struct {
+		key  string
+		days int
+	}{{"FK-1", 3}, {"FK-5", 10}, {"APP-1", -2}} {
+		fake.Issue(d.key).Fields.Duedate = jira.Date(now.AddDate(0, 0, d.days))
+	}
+
+	released, unreleased := true, false
+	for _, key := range []string{"FK", "APP"} {
+		fake.Projects[key] = &jira.Project{Key: key, Name: key, Versions: []jira.Version{
+			{ID: key + "-1", Name: "1.0", Released: &released, ReleaseDate: now.AddDate(0, 0, -30).Format("2006-01-02")},
+			{ID: key + "-2", Name: "1.1", Released: &unreleased, ReleaseDate: now.AddDate(0, 0, 14).Format("2006-01-02")},
+		}}
+	}
+
+	for i, s := range demoStatuses {
+		fake.Transitions = append(fake.Transitions, jira.Transition{ID: fmt.Sprintf("%d", (i+1)*10), Name: s, To: jira.Status{Name: s}})
+	}
+
+	return fake
+}
+
+func demoConfig() *Config {
+	return &Config{
+		Projects:   []string{"FK", "APP"},
+		DoneStatus: "Done",
+		Stale: []*StaleConfig{
+			{Status: "To Do", Days: 60, Action: "label", Label: "stale"},
+		},
+		Reports: map[string]*ReportConfig{
+			"status": {Description: "open issues by status and component", JQL: "resolution IS EMPTY", GroupBy: []string{"status", "component"}},
+		},
+	}
+}
+
+// demo is registered here rather than in commands because it looks up the
+// command it runs there.
+func init() {
+	commands = append(commands, &Command{Name: "demo", Description: "run a command against a built-in mock jira with sample issues", Offline: true, Run: demoCommand})
+}
+
+// demoCommand runs another command against an embedded Jira seeded with
+// sample issues. State is kept in a temporary directory, so nothing the
+// demo does touches the real cache, journals or Jira.
+func demoCommand(_ *jira.Client, _ *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("demo", flag.ExitOnError)
+	serve := flags.Bool("serve", false, "keep the mock jira running until interrupted")
+	flags.Parse(args)
+	args = flags.Args()
+
+	command, rest := findCommand(commands, args)
+	if command == nil && !*serve {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s demo [-serve] <command> [args]\n\nCommands:\n", os.Args[0])
+		printCommands(commands, "")
+		return nil
+	}
+	if command != nil && (command.Run == nil || command.Name == "demo") {
+		return fmt.Errorf("demo: unknown command '%s'", strings.Join(args, " "))
+	}
+
+	dir, err := ioutil.TempDir("", "jira-ops-demo")
+	if err != nil {
+		return fmt.Errorf("error creating demo state: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.Setenv("JIRA_OPS_STATE", dir); err != nil {
+		return err
+	}
+
+	options.cache.close()
+	options.cache, err = openCache(true, false)
+	if err != nil {
+		return err
+	}
+	options.Cached = false
+	options.Offline = false
+
+	server := jiratest.NewServer(demoFake())
+	defer server.Close()
+
+	log.Printf("demo jira at %s", server.URL)
+
+	jc, err := client.NewWithContext(options.ctx, &client.Options{URL: server.URL, Username: demoUser, Password: demoUser, Timing: options.timing})
+	if err != nil {
+		return err
+	}
+
+	if command != nil {
+		if err := command.Run(jc, demoConfig(), options, rest); err != nil {
+			return err
+		}
+	}
+
+	if *serve {
+		<-options.ctx.Done()
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+)
+
+func defaultDeployTargets() map[string]*DeployTargetConfig {
+	return map[string]*DeployTargetConfig{
+		"portal": &DeployTargetConfig{
+			Status:      "Ready for Deploy",
+			Components:  []string{"Portal", "Backend"},
+			Destination: "Awaiting QA",
+		},
+		"app": &DeployTargetConfig{
+			Status:      "Ready for Deploy",
+			Components:  []string{"Mobile App"},
+			Destination: "Awaiting QA",
+		},
+		"firmware": &DeployTargetConfig{
+			Status:      "Ready for Deploy",
+			Components:  []string{"Firmware"},
+			Destination: "Awaiting QA",
+		},
+	}
+}
+
+func (c *Config) deployTargets() map[string]*DeployTargetConfig {
+	targets := defaultDeployTargets()
+	for name, target := range c.Deploy {
+		targets[name] = target
+	}
+	return targets
+}
+
+func (c *Config) deployTarget(name string) (*DeployTargetConfig, error) {
+	target, ok := c.deployTargets()[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown deploy target: %s", name)
+	}
+	return target, nil
+}
+
+func quoteList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", v))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (t *DeployTargetConfig) search(version string) string {
+	search := fmt.Sprintf(`status IN (%s) AND component IN (%s)`, quoteList([]string{t.Status}), quoteList(t.Components))
+	if version != "" {
+		search += fmt.Sprintf(` AND fixVersion = %q`, t.VersionPrefix+version)
+	}
+	return search
+}
+
+func pendingSearch(config *Config) string {
+	statuses := make(map[string]bool)
+	components := make(map[string]bool)
+	for _, t := range config.deployTargets() {
+		statuses[t.Status] = true
+		for _, c := range t.Components {
+			components[c] = true
+		}
+	}
+	return fmt.Sprintf(`status IN (%s) AND component IN (%s)`, quoteList(sortedKeys(statuses)), quoteList(sortedKeys(components)))
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type Deployment struct {
+	Target      string
+	Version     string
+	Commit      string
+	Environment string
+	BuildURL    string
+	AnyVersion  bool
+	Handoff     bool
+}
+
+func (d *Deployment) fixVersion() string {
+	if d.AnyVersion {
+		return ""
+	}
+	return d.Version
+}
+
+func (d *Deployment) comment(run string) string {
+	lines := []string{
+		fmt.Sprintf("Deployed *%s*", d.Target),
+	}
+	if d.Environment != "" {
+		lines[0] += fmt.Sprintf(" to *%s*", d.Environment)
+	}
+	if d.Version != "" {
+		lines = append(lines, fmt.Sprintf("* Version: {{%s}}", d.Version))
+	}
+	if d.Commit != "" {
+		lines = append(lines, fmt.Sprintf("* Commit: {{%s}}", d.Commit))
+	}
+	if d.BuildURL != "" {
+		lines = append(lines, fmt.Sprintf("* Build: [%s]", d.BuildURL))
+	}
+	lines = append(lines, fmt.Sprintf("* Deploy: {{%s}}", run))
+	return strings.Join(lines, "\n")
+}
+
+func deploy(issues client.Issues, config *Config, options *Options, deployment *Deployment) error {
+	target, err := config.deployTarget(deployment.Target)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	run := now.UTC().Format("20060102T150405Z")
+
+	changed, err := changeStatus(issues, options, target.search(deployment.fixVersion()), target.Destination)
+
+	record := &DeployRecord{
+		Run:         run,
+		Time:        now,
+		Target:      deployment.Target,
+		Version:     deployment.Version,
+		Commit:      deployment.Commit,
+		Environment: deployment.Environment,
+		BuildURL:    deployment.BuildURL,
+		From:        target.Status,
+		To:          target.Destination,
+		Issues:      make([]string, 0),
+	}
+	for _, i := range changed {
+		record.Issues = append(record.Issues, i.Key)
+	}
+
+	if options.DryRun {
+		return err
+	}
+
+	if len(record.Issues) > 0 {
+		if err := appendDeployRecord(record); err != nil {
+			return err
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	announceDeploy(options.ctx, config, deployment, target, changed)
+
+	if deployment.Environment != "" {
+		if err := labelEnvironment(options.ctx, issues, config, changed, deployment.Environment); err != nil {
+			return err
+		}
+	}
+
+	if config.Deployments != nil {
+		if err := publishDeployment(options.ctx, config.Deployments, deployment, run, record.Issues); err != nil {
+			return err
+		}
+	}
+
+	if deployment.Handoff {
+		if err := sendHandoff(options.ctx, config, deployment, changed); err != nil {
+			return err
+		}
+	}
+
+	if deployment.Version == "" && deployment.Commit == "" && deployment.Environment == "" && deployment.BuildURL == "" {
+		return nil
+	}
+
+	body := deployment.comment(run)
+	for _, i := range changed {
+		if _, _, err := issues.AddCommentWithContext(options.ctx, i.Key, &jira.Comment{Body: body}); err != nil {
+			return fmt.Errorf("error adding comment: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func deployCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	deployment := &Deployment{}
+
+	flags := flag.NewFlagSet("deploy", flag.ExitOnError)
+	flags.StringVar(&deployment.Version, "version", "", "version that was deployed, limits issues to this fixVersion")
+	flags.StringVar(&deployment.Commit, "commit", "", "commit that was deployed")
+	flags.StringVar(&deployment.Environment, "env", "", "environment deployed to")
+	flags.StringVar(&deployment.BuildURL, "build-url", "", "url of the build that was deployed")
+	flags.BoolVar(&deployment.AnyVersion, "any-version", false, "transition issues regardless of their fixVersion")
+	flags.BoolVar(&deployment.Handoff, "handoff", false, "send a qa handoff summary of the transitioned issues")
+	fromCI := flags.Bool("from-ci", false, "fill in target, version, commit, build url and environment from CI variables")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deploy <%s> [options]\n", strings.Join(deployTargetNames(config), "|"))
+		flags.PrintDefaults()
+	}
+
+	positional := parseArgs(flags, args)
+	if len(positional) > 1 {
+		flags.Usage()
+		return fmt.Errorf("too many arguments")
+	}
+
+	if len(positional) == 1 {
+		deployment.Target = positional[0]
+	}
+
+	if *fromCI {
+		if err := deployment.fillFromCI(); err != nil {
+			return err
+		}
+	}
+
+	if deployment.Target == "" {
+		flags.Usage()
+		return fmt.Errorf("deploy target required")
+	}
+
+	return deploy(jc.Issue, config, options, deployment)
+}
+
+func deployTargetNames(config *Config) []string {
+	names := make([]string, 0)
+	for name := range config.deployTargets() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
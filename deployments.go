@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const atlassianTokenURL = "https://api.atlassian.com/oauth/token"
+const deploymentsURL = "https://api.atlassian.com/jira/deployments/0.1/cloud/%s/bulk"
+
+var environmentTypes = map[string]string{
+	"prod": "production",
+	"stag": "staging",
+	"test": "testing",
+	"qa":   "testing",
+	"dev":  "development",
+}
+
+type deploymentAssociation struct {
+	AssociationType string   `json:"associationType"`
+	Values          []string `json:"values"`
+}
+
+type deploymentPipeline struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	URL         string `json:"url"`
+}
+
+type deploymentEnvironment struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Type        string `json:"type"`
+}
+
+type cloudDeployment struct {
+	DeploymentSequenceNumber int64                    `json:"deploymentSequenceNumber"`
+	UpdateSequenceNumber     int64                    `json:"updateSequenceNumber"`
+	Associations             []*deploymentAssociation `json:"associations"`
+	DisplayName              string                   `json:"displayName"`
+	URL                      string                   `json:"url"`
+	Description              string                   `json:"description"`
+	LastUpdated              string                   `json:"lastUpdated"`
+	Label                    string                   `json:"label,omitempty"`
+	State                    string                   `json:"state"`
+	Pipeline                 *deploymentPipeline      `json:"pipeline"`
+	Environment              *deploymentEnvironment   `json:"environment"`
+}
+
+func environmentType(name string) string {
+	lower := strings.ToLower(name)
+	for prefix, t := range environmentTypes {
+		if strings.HasPrefix(lower, prefix) {
+			return t
+		}
+	}
+	return "unmapped"
+}
+
+func acquireCloudToken(ctx context.Context, dc *DeploymentsConfig) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"audience":      "api.atlassian.com",
+		"grant_type":    "client_credentials",
+		"client_id":     dc.ClientID,
+		"client_secret": dc.ClientSecret,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", atlassianTokenURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("acquiring token: %w", err)
+	}
+
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("acquiring token: %s", r.Status)
+	}
+
+	token := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("acquiring token: %w", err)
+	}
+
+	return token.AccessToken, nil
+}
+
+func publishDeployment(ctx context.Context, dc *DeploymentsConfig, deployment *Deployment, run string, issues []string) error {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	token, err := acquireCloudToken(ctx, dc)
+	if err != nil {
+		return err
+	}
+
+	environment := deployment.Environment
+	if environment == "" {
+		environment = "production"
+	}
+
+	url := dc.URL
+	if deployment.BuildURL != "" {
+		url = deployment.BuildURL
+	}
+
+	displayName := deployment.Target
+	if deployment.Version != "" {
+		displayName += " " + deployment.Version
+	}
+
+	now := time.Now()
+	payload := map[string]interface{}{
+		"deployments": []*cloudDeployment{
+			&cloudDeployment{
+				DeploymentSequenceNumber: now.Unix(),
+				UpdateSequenceNumber:     now.Unix(),
+				Associations: []*deploymentAssociation{
+					&deploymentAssociation{AssociationType: "issueIdOrKeys", Values: issues},
+				},
+				DisplayName: displayName,
+				URL:         url,
+				Description: deployment.comment(run),
+				LastUpdated: now.UTC().Format(time.RFC3339),
+				Label:       deployment.Version,
+				State:       "successful",
+				Pipeline: &deploymentPipeline{
+					ID:          deployment.Target,
+					DisplayName: deployment.Target,
+					URL:         dc.URL,
+				},
+				Environment: &deploymentEnvironment{
+					ID:          environment,
+					DisplayName: environment,
+					Type:        environmentType(environment),
+				},
+			},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf(deploymentsURL, dc.CloudID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("publishing deployment: %w", err)
+	}
+
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusAccepted && r.StatusCode != http.StatusOK {
+		data, _ := ioutil.ReadAll(r.Body)
+		return fmt.Errorf("publishing deployment: %s %s", r.Status, string(data))
+	}
+
+	log.Printf("published deployment %s (%d issues)", displayName, len(issues))
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("open", url)
+	} else {
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("opening %s: %w", url, err)
+	}
+	return nil
+}
+
+func isPullRequestLink(link *jira.RemoteLink) bool {
+	if link.Object == nil {
+		return false
+	}
+	if link.Application != nil && link.Application.Type == "jira-ops.pull-request" {
+		return true
+	}
+	return strings.Contains(link.Object.URL, "/pull/") || strings.Contains(link.Object.URL, "/merge_requests/")
+}
+
+func findIssueBranches(key string) ([]string, error) {
+	output, err := git("for-each-ref", "--format=%(refname:short)", "refs/heads/", "refs/remotes/")
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make([]string, 0)
+	for _, ref := range strings.Split(output, "\n") {
+		m := issueKeyRegexp.FindStringSubmatch(ref)
+		if m != nil && strings.EqualFold(m[1], key) {
+			branches = append(branches, ref)
+		}
+	}
+
+	return branches, nil
+}
+
+func devCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("dev", flag.ExitOnError)
+	printOnly := flags.Bool("print", false, "print pull requests instead of opening them")
+	positional := parseArgs(flags, args)
+
+	key, _, err := resolveIssueKey(options, positional)
+	if err != nil {
+		return err
+	}
+
+	links, _, err := jc.Issue.GetRemoteLinksWithContext(options.ctx, key)
+	if err != nil {
+		return fmt.Errorf("error getting remote links: %w", err)
+	}
+
+	pulls := make([]*jira.RemoteLink, 0)
+	for i := range *links {
+		if isPullRequestLink(&(*links)[i]) {
+			pulls = append(pulls, &(*links)[i])
+		}
+	}
+
+	if len(pulls) > 0 {
+		for _, pr := range pulls {
+			fmt.Printf("%s %s\n", pr.Object.URL, pr.Object.Title)
+		}
+		if *printOnly {
+			return nil
+		}
+		return openBrowser(pulls[len(pulls)-1].Object.URL)
+	}
+
+	branches, err := findIssueBranches(key)
+	if err == nil && len(branches) > 0 {
+		fmt.Println(strings.Join(branches, "\n"))
+		return nil
+	}
+
+	issue, _, err := jc.Issue.GetWithContext(options.ctx, key, nil)
+	if err != nil {
+		return fmt.Errorf("error getting issue: %w", err)
+	}
+
+	name, err := makeBranchName(config, issue)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s (no pull request or branch yet)\n", name)
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type diffLine struct {
+	kind byte
+	text string
+	a    int
+	b    int
+}
+
+func diffLines(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0)
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i], i, j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i], i, j})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j], i, j})
+			j++
+		}
+	}
+	return lines
+}
+
+func unifiedDiff(name, before, after string, context int) string {
+	if before == after {
+		return ""
+	}
+
+	lines := diffLines(strings.Split(before, "\n"), strings.Split(after, "\n"))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", name, name)
+
+	for start := 0; start < len(lines); {
+		if lines[start].kind == ' ' {
+			start++
+			continue
+		}
+
+		first := start - context
+		if first < 0 {
+			first = 0
+		}
+
+		last := start
+		for k := start; k < len(lines) && k <= last+2*context; k++ {
+			if lines[k].kind != ' ' {
+				last = k
+			}
+		}
+		end := last + context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		removed, added := 0, 0
+		for _, l := range lines[first:end] {
+			if l.kind != '+' {
+				removed++
+			}
+			if l.kind != '-' {
+				added++
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", lines[first].a+1, removed, lines[first].b+1, added)
+		for _, l := range lines[first:end] {
+			fmt.Fprintf(&sb, "%c%s\n", l.kind, l.text)
+		}
+
+		start = end
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/reports"
+)
+
+type DigestItem struct {
+	Key     string
+	Summary string
+	Note    string
+}
+
+type DigestSection struct {
+	Title string
+	Items []*DigestItem
+}
+
+type Digest struct {
+	Title    string
+	Sections []*DigestSection
+}
+
+func (d *Digest) markdown() string {
+	lines := []string{fmt.Sprintf("# %s", d.Title), ""}
+	for _, s := range d.Sections {
+		lines = append(lines, fmt.Sprintf("## %s (%d)", s.Title, len(s.Items)), "")
+		for _, i := range s.Items {
+			line := fmt.Sprintf("* [%s](%s) %s", i.Key, issueURL(i.Key), i.Summary)
+			if i.Note != "" {
+				line += fmt.Sprintf(" _%s_", i.Note)
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (d *Digest) slack() string {
+	lines := make([]string, 0)
+	for _, s := range d.Sections {
+		lines = append(lines, fmt.Sprintf("*%s* (%d)", s.Title, len(s.Items)))
+		for _, i := range s.Items {
+			line := fmt.Sprintf("• <%s|%s> %s", issueURL(i.Key), i.Key, i.Summary)
+			if i.Note != "" {
+				line += fmt.Sprintf(" _%s_", i.Note)
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (d *Digest) html() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", html.EscapeString(d.Title))
+	for _, s := range d.Sections {
+		fmt.Fprintf(&b, "<h2>%s (%d)</h2>\n<ul>\n", html.EscapeString(s.Title), len(s.Items))
+		for _, i := range s.Items {
+			fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a> %s", issueURL(i.Key), i.Key, html.EscapeString(i.Summary))
+			if i.Note != "" {
+				fmt.Fprintf(&b, " <em>%s</em>", html.EscapeString(i.Note))
+			}
+			b.WriteString("</li>\n")
+		}
+		b.WriteString("</ul>\n")
+	}
+	return b.String()
+}
+
+func digestSection(jc *jira.Client, options *Options, title, search string, searchOptions *jira.SearchOptions, note func(*jira.Issue) (string, bool)) (*DigestSection, error) {
+	section := &DigestSection{Title: title}
+	err := searchIssues(jc, options, search, searchOptions, func(issue jira.Issue) error {
+		text := ""
+		if note != nil {
+			var ok bool
+			if text, ok = note(&issue); !ok {
+				return nil
+			}
+		}
+		section.Items = append(section.Items, &DigestItem{Key: issue.Key, Summary: issue.Fields.Summary, Note: text})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting issues: %w", err)
+	}
+	return section, nil
+}
+
+func makeDigest(jc *jira.Client, options *Options, since, jql string) (*Digest, error) {
+	after, err := parseSince(since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	window := jqlSince(since)
+
+	digest := &Digest{
+		Title: fmt.Sprintf("Jira digest %s - %s", after.Format("2006/01/02"), time.Now().Format("2006/01/02")),
+	}
+
+	page := &jira.SearchOptions{MaxResults: 100, Fields: listingFields}
+
+	resolved, err := digestSection(jc, options, "Resolved", reportSearch(options, fmt.Sprintf("(resolved >= %s)", window), jql)+" ORDER BY resolved ASC", page, func(i *jira.Issue) (string, bool) {
+		return assigneeName(i), true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := digestSection(jc, options, "Created", reportSearch(options, fmt.Sprintf("(created >= %s)", window), jql)+" ORDER BY created ASC", page, func(i *jira.Issue) (string, bool) {
+		return i.Fields.Type.Name, true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := digestSection(jc, options, "Blocked", reportSearch(options, `(resolution IS EMPTY) AND (issueLinkType = "is blocked by" OR Flagged IS NOT EMPTY)`, jql), page, func(i *jira.Issue) (string, bool) {
+		if blockers := openBlockers(i); len(blockers) > 0 {
+			return fmt.Sprintf("blocked by %s", strings.Join(blockers, ", ")), true
+		}
+		return "flagged", true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	moves := &jira.SearchOptions{MaxResults: 100, Expand: "changelog", Fields: listingFields}
+	moved, err := digestSection(jc, options, "Status changes", reportSearch(options, fmt.Sprintf("(status CHANGED AFTER %s) AND (resolution IS EMPTY)", window), jql), moves, func(i *jira.Issue) (string, bool) {
+		from := ""
+		for _, c := range reports.StatusChanges(i) {
+			if c.Time.After(after) {
+				from = c.From
+				break
+			}
+		}
+		if from == "" || from == i.Fields.Status.Name {
+			return "", false
+		}
+		return fmt.Sprintf("%s → %s", from, i.Fields.Status.Name), true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	digest.Sections = []*DigestSection{resolved, created, blocked, moved}
+
+	return digest, nil
+}
+
+func digestCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("digest", flag.ExitOnError)
+	since := flags.String("since", "7d", "period covered by the digest")
+	jql := flags.String("jql", "", "restrict to issues matching this query")
+	format := flags.String("format", "markdown", "output format (markdown, html, slack)")
+	post := flags.Bool("post", false, "post the digest to the configured slack and teams digest channels, or send it with --notify")
+	mail := flags.String("mail", "", "email the digest as html to a configured mailing list or comma separated addresses")
+	flags.Parse(args)
+
+	digest, err := makeDigest(jc, options, *since, *jql)
+	if err != nil {
+		return err
+	}
+
+	if *mail != "" {
+		mailer, err := config.mailer(*mail, true)
+		if err != nil {
+			return err
+		}
+		if err := mailer.Notify(options.ctx, digest.Title, digest.html()); err != nil {
+			return err
+		}
+		if !*post {
+			return nil
+		}
+	}
+
+	var text string
+	switch *format {
+	case "markdown":
+		text = digest.markdown()
+	case "html":
+		text = digest.html()
+	case "slack":
+		text = digest.slack()
+	default:
+		return fmt.Errorf("unknown digest format: %s", *format)
+	}
+
+	if !*post {
+		fmt.Print(text)
+		return nil
+	}
+
+	teams := config.teams(slackDigest)
+	if teams != nil {
+		if err := teams.post(options.ctx, digest.teams()); err != nil {
+			return err
+		}
+	}
+
+	notifier, err := config.slack(slackDigest)
+	if err != nil {
+		return err
+	}
+	if notifier != nil {
+		return notifier.Notify(options.ctx, digest.Title, digest.slack())
+	}
+	if teams != nil {
+		return nil
+	}
+
+	notifier, err = newNotifier(options)
+	if err != nil {
+		return err
+	}
+	if notifier == nil {
+		return fmt.Errorf("--post requires a slack or teams digest channel or --notify")
+	}
+
+	return notifier.Notify(options.ctx, digest.Title, text)
+}
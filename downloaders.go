@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/mirror"
+)
+
+var diagnosticsURL = regexp.MustCompile("https://code.conservify.org/diagnostics/?\\?id=([a-zA-Z0-9-]+)")
+
+// defaultDownloaders are used when none are configured.
+func defaultDownloaders() []*DownloaderConfig {
+	return []*DownloaderConfig{
+		{
+			Name:     "diagnostics",
+			Provider: "http",
+			Pattern:  diagnosticsURL.String(),
+			URL:      "https://code.conservify.org/diagnostics/archives/${1}.zip?token=" + url.QueryEscape(DiagnosticsToken),
+			SaveAs:   "${1}.zip",
+		},
+	}
+}
+
+func newDownloaders(jc *jira.Client, config *Config) (*mirror.Registry, error) {
+	registry := mirror.NewRegistry(nil)
+	registry.Register("jira-attachment", mirror.JiraAttachment(jc.Issue))
+
+	configured := config.Downloaders
+	if len(configured) == 0 {
+		configured = defaultDownloaders()
+	}
+
+	for _, dc := range configured {
+		pattern, err := regexp.Compile(dc.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("downloader %s: %w", dc.Name, err)
+		}
+
+		headers := make(map[string]string)
+		for name, value := range dc.Headers {
+			headers[name] = os.ExpandEnv(value)
+		}
+
+		source := &mirror.Source{
+			Name:     dc.Name,
+			Provider: dc.Provider,
+			Pattern:  pattern,
+			URL:      dc.URL,
+			SaveAs:   dc.SaveAs,
+			Token:    os.ExpandEnv(dc.Token),
+			Headers:  headers,
+		}
+		if err := registry.Add(source); err != nil {
+			return nil, fmt.Errorf("downloader %w", err)
+		}
+	}
+
+	return registry, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+)
+
+var wordsRegexp = regexp.MustCompile(`[a-z0-9]+`)
+
+type DuplicatePair struct {
+	A     *jira.Issue
+	B     *jira.Issue
+	Score float64
+}
+
+func summaryTokens(summary string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, word := range wordsRegexp.FindAllString(strings.ToLower(summary), -1) {
+		tokens[word] = true
+	}
+	return tokens
+}
+
+func summaryTrigrams(summary string) map[string]bool {
+	trigrams := make(map[string]bool)
+	normalized := " " + strings.Join(wordsRegexp.FindAllString(strings.ToLower(summary), -1), " ") + " "
+	for i := 0; i+3 <= len(normalized); i++ {
+		trigrams[normalized[i:i+3]] = true
+	}
+	return trigrams
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for k := range a {
+		if b[k] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func summarySimilarity(a, b string) float64 {
+	tokens := jaccard(summaryTokens(a), summaryTokens(b))
+	trigrams := jaccard(summaryTrigrams(a), summaryTrigrams(b))
+	if tokens > trigrams {
+		return tokens
+	}
+	return trigrams
+}
+
+func findDuplicates(issues []jira.Issue, threshold float64) []*DuplicatePair {
+	pairs := make([]*DuplicatePair, 0)
+	for i := 0; i < len(issues); i++ {
+		for j := i + 1; j < len(issues); j++ {
+			score := summarySimilarity(issues[i].Fields.Summary, issues[j].Fields.Summary)
+			if score >= threshold {
+				pairs = append(pairs, &DuplicatePair{A: &issues[i], B: &issues[j], Score: score})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Score > pairs[j].Score
+	})
+	return pairs
+}
+
+func issueURL(key string) string {
+	return fmt.Sprintf("%s/browse/%s", strings.TrimSuffix(JiraUrl, "/"), key)
+}
+
+func commentPossibleDuplicate(ctx context.Context, jc *jira.Client, pair *DuplicatePair) error {
+	older, newer := pair.A, pair.B
+	if time.Time(newer.Fields.Created).Before(time.Time(older.Fields.Created)) {
+		older, newer = newer, older
+	}
+
+	body := fmt.Sprintf("Possible duplicate of %s", older.Key)
+
+	issue, _, err := jc.Issue.GetWithContext(ctx, newer.Key, &jira.GetQueryOptions{Fields: "comment"})
+	if err != nil {
+		return fmt.Errorf("error getting issue: %w", err)
+	}
+
+	if issue.Fields.Comments != nil {
+		for _, c := range issue.Fields.Comments.Comments {
+			if strings.Contains(c.Body, body) {
+				return nil
+			}
+		}
+	}
+
+	log.Printf("[%s] commenting possible duplicate of %s", newer.Key, older.Key)
+
+	if _, _, err := jc.Issue.AddCommentWithContext(ctx, newer.Key, &jira.Comment{Body: body}); err != nil {
+		return fmt.Errorf("error adding comment: %w", err)
+	}
+
+	return nil
+}
+
+func dupesCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("dupes", flag.ExitOnError)
+	jql := flags.String("jql", fmt.Sprintf("(project = '%s') AND (resolution IS EMPTY)", options.Project), "issues to compare")
+	threshold := flags.Float64("threshold", 0.6, "minimum similarity to report")
+	comment := flags.Bool("comment", false, "comment on the newer issue of each pair")
+	flags.Parse(args)
+
+	issues, err := pages.All(options.ctx, jc.Issue, *jql, &jira.SearchOptions{Fields: []string{"summary", "created", "status"}})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	for _, pair := range findDuplicates(issues, *threshold) {
+		fmt.Printf("%.2f %-8s %s\n", pair.Score, pair.A.Key, pair.A.Fields.Summary)
+		fmt.Printf("     %-8s %s\n", pair.B.Key, pair.B.Fields.Summary)
+		fmt.Printf("     %s %s\n\n", issueURL(pair.A.Key), issueURL(pair.B.Key))
+
+		if *comment && !options.DryRun {
+			if err := commentPossibleDuplicate(options.ctx, jc, pair); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+)
+
+const defaultEnvironmentLabelPrefix = "deployed-"
+
+func (c *Config) environmentLabel(environment string) string {
+	prefix := c.EnvironmentLabelPrefix
+	if prefix == "" {
+		prefix = defaultEnvironmentLabelPrefix
+	}
+	return prefix + strings.ToLower(environment)
+}
+
+func labelEnvironment(ctx context.Context, issues client.Issues, config *Config, changed []jira.Issue, environment string) error {
+	label := config.environmentLabel(environment)
+	for _, i := range changed {
+		if hasLabel(&i, label) {
+			continue
+		}
+		if err := addLabel(ctx, issues, &i, label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lastDeployedTimes(records []*DeployRecord, environment string) map[string]time.Time {
+	times := make(map[string]time.Time)
+	for _, r := range records {
+		if !strings.EqualFold(r.Environment, environment) {
+			continue
+		}
+		for _, key := range r.Issues {
+			if r.Time.After(times[key]) {
+				times[key] = r.Time
+			}
+		}
+	}
+	return times
+}
+
+func deployReportCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("deploy report", flag.ExitOnError)
+	in := flags.String("in", "staging", "environment issues have reached")
+	missing := flags.String("missing", "production", "environment issues have not reached")
+	days := flags.Int("days", 5, "only show issues waiting longer than this")
+	flags.Parse(args)
+
+	search := fmt.Sprintf(`labels = %q AND labels != %q ORDER BY updated ASC`, config.environmentLabel(*in), config.environmentLabel(*missing))
+
+	issues, err := pages.All(options.ctx, jc.Issue, search, &jira.SearchOptions{Fields: listingFields})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	records, err := readDeployJournal()
+	if err != nil {
+		return err
+	}
+
+	deployed := lastDeployedTimes(records, *in)
+	cutoff := time.Now().AddDate(0, 0, -*days)
+
+	for _, i := range issues {
+		when, ok := deployed[i.Key]
+		if ok && when.After(cutoff) {
+			continue
+		}
+
+		age := "unknown"
+		if ok {
+			age = fmt.Sprintf("%dd", int(time.Since(when).Hours()/24))
+		}
+
+		fmt.Printf("%-8s %-18s %-8s %s\n", i.Key, i.Fields.Status.Name, age, i.Fields.Summary)
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strconv"
@@ -8,13 +9,13 @@ import (
 	"github.com/andygrunwald/go-jira"
 )
 
-func findEpic(jc *jira.Client, project, epic string) (string, error) {
+func findEpic(ctx context.Context, jc *jira.Client, project, epic string) (string, error) {
 	number, err := strconv.Atoi(epic)
 	if err == nil {
 		return fmt.Sprintf("%s-%d", project, number), nil
 	}
 
-	epics, _, err := jc.Issue.Search(fmt.Sprintf("type = 'Epic' AND resolution IS EMPTY AND summary ~ \"%s*\" ORDER BY dueDate DESC", epic), nil)
+	epics, _, err := jc.Issue.SearchWithContext(ctx, fmt.Sprintf("type = 'Epic' AND resolution IS EMPTY AND summary ~ \"%s*\" ORDER BY dueDate DESC", epic), nil)
 	if err != nil {
 		log.Fatalf("error getting issues: %+v", err)
 	}
@@ -27,15 +28,15 @@ func findEpic(jc *jira.Client, project, epic string) (string, error) {
 	return "", fmt.Errorf("unable to find Epic matching '%s'", epic)
 }
 
-func linkEpics(jc *jira.Client, project, epic string) error {
-	desiredKey, err := findEpic(jc, project, epic)
+func linkEpics(ctx context.Context, jc *jira.Client, project, epic string) error {
+	desiredKey, err := findEpic(ctx, jc, project, epic)
 	if err != nil {
 		return err
 	}
 
-	types, _, _ := jc.IssueLinkType.GetList()
+	types, _, _ := jc.IssueLinkType.GetListWithContext(ctx)
 	for _, a := range types {
-		log.Printf("OK: ", a)
+		log.Printf("OK: %v", a)
 	}
 
 	for _, issueNumber := range []string{ /* issues */ } {
@@ -43,9 +44,9 @@ func linkEpics(jc *jira.Client, project, epic string) error {
 
 		log.Printf("moving %s to epic %s", issueKey, desiredKey)
 
-		issue, _, err := jc.Issue.Get(issueKey, nil)
+		issue, _, err := jc.Issue.GetWithContext(ctx, issueKey, nil)
 		if err != nil {
-			return fmt.Errorf("error getting issue: %+v", err)
+			return fmt.Errorf("error getting issue: %w", err)
 		}
 
 		linked := false
@@ -57,9 +58,9 @@ func linkEpics(jc *jira.Client, project, epic string) error {
 				} else {
 					if link.InwardIssue.Fields.Type.Name == "Epic" {
 						log.Printf("removing (inward) link to: %s", link.InwardIssue.Key)
-						err := deleteLink(jc, link.ID)
+						err := deleteLink(ctx, jc, link.ID)
 						if err != nil {
-							return fmt.Errorf("error: %v", err)
+							return fmt.Errorf("error: %w", err)
 						}
 					}
 				}
@@ -70,9 +71,9 @@ func linkEpics(jc *jira.Client, project, epic string) error {
 				} else {
 					if link.OutwardIssue.Fields.Type.Name == "Epic" {
 						log.Printf("removing (outward) link to: %s", link.OutwardIssue.Key)
-						err := deleteLink(jc, link.ID)
+						err := deleteLink(ctx, jc, link.ID)
 						if err != nil {
-							return fmt.Errorf("error: %v", err)
+							return fmt.Errorf("error: %w", err)
 						}
 					}
 				}
@@ -93,9 +94,9 @@ func linkEpics(jc *jira.Client, project, epic string) error {
 					Key: issueKey,
 				},
 			}
-			_, err := jc.Issue.AddLink(newLink)
+			_, err := jc.Issue.AddLinkWithContext(ctx, newLink)
 			if err != nil {
-				return fmt.Errorf("error: %v", err)
+				return fmt.Errorf("error: %w", err)
 			}
 		}
 	}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/jlewallen/jira-ops/jiraops/client"
+	"github.com/jlewallen/jira-ops/jiraops/schema"
+)
+
+// Exit codes for failures a wrapper may want to tell apart, anything else
+// exits with 1.
+var exitErrors = []struct {
+	err  error
+	kind string
+	code int
+}{
+	{client.ErrAuth, "auth", 3},
+	{client.ErrNotFound, "not-found", 4},
+	{client.ErrTransitionUnavailable, "transition-unavailable", 5},
+	{client.ErrRateLimited, "rate-limited", 6},
+	{client.ErrPartialFailure, "partial-failure", 7},
+	{context.DeadlineExceeded, "timeout", 124},
+}
+
+func classifyError(err error) (string, int) {
+	if interrupted(err) {
+		return "interrupted", 130
+	}
+	for _, e := range exitErrors {
+		if errors.Is(err, e.err) {
+			return e.kind, e.code
+		}
+	}
+	return "error", 1
+}
+
+func fail(options *Options, err error) {
+	kind, code := classifyError(err)
+
+	if options.JSON {
+		json.NewEncoder(os.Stderr).Encode(&schema.Error{SchemaVersion: schema.Version, Error: err.Error(), Kind: kind, Code: code})
+	} else if kind != "interrupted" {
+		log.Printf("error: %v", err)
+	}
+
+	options.timing.Report(os.Stderr)
+	options.cache.close()
+	os.Exit(code)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/reports"
+)
+
+type EstimateAccuracy struct {
+	Issues    int
+	Estimated time.Duration
+	Spent     time.Duration
+	Ratios    []float64
+}
+
+func medianRatio(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	if len(sorted)%2 == 0 {
+		return (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	return sorted[len(sorted)/2]
+}
+
+func reportEstimatesCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report estimates", flag.ExitOnError)
+	jql := flags.String("jql", "", "restrict to issues matching this query")
+	since := flags.String("since", "90d", "only include issues resolved within this window")
+	points := flags.Bool("points", false, "compare story points against cycle time instead of estimates against logged time")
+	start := flags.String("start", "In Progress", "status that starts the cycle when using --points")
+	flags.Parse(args)
+
+	if *points && config.PointsField == "" {
+		return fmt.Errorf("--points requires pointsField in the configuration")
+	}
+
+	_, err := parseSince(*since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	search := reportSearch(options, fmt.Sprintf("(resolution IS NOT EMPTY) AND (resolved >= %s)", jqlSince(*since)), *jql)
+
+	groups := map[string]map[string]*EstimateAccuracy{
+		"type":     make(map[string]*EstimateAccuracy),
+		"assignee": make(map[string]*EstimateAccuracy),
+	}
+	order := []string{"type", "assignee"}
+
+	// With Tempo, logged time is read from Tempo's worklogs and also
+	// grouped by the account it was logged against.
+	var tempo *tempoWorklogs
+	if config.Tempo != nil && !*points {
+		if tempo, err = newTempo(jc, config); err != nil {
+			return err
+		}
+		groups["account"] = make(map[string]*EstimateAccuracy)
+		order = append(order, "account")
+	}
+
+	searchOptions := &jira.SearchOptions{MaxResults: 100, Fields: withFields([]string{"created", "resolution", "resolutiondate", "status", "timeoriginalestimate", "timespent"}, "type", "assignee", config.PointsField)}
+	if *points {
+		searchOptions.Expand = "changelog"
+	}
+
+	skipped := 0
+	err = searchIssues(jc, options, search, searchOptions, func(issue jira.Issue) error {
+		var estimated, spent time.Duration
+		account := ""
+
+		if *points {
+			value, ok := numericField(&issue, config.PointsField)
+			started, entered := reports.FirstEntered(&issue, *start)
+			if !ok || value == 0 || !entered {
+				skipped++
+				return nil
+			}
+			estimated = time.Duration(value * float64(24*time.Hour))
+			spent = time.Time(issue.Fields.Resolutiondate).Sub(started)
+		} else {
+			estimated = time.Duration(issue.Fields.TimeOriginalEstimate) * time.Second
+			spent = time.Duration(issue.Fields.TimeSpent) * time.Second
+			if tempo != nil && estimated != 0 {
+				worklogs, err := tempo.Worklogs(options.ctx, &issue)
+				if err != nil {
+					return err
+				}
+				spent = 0
+				for _, w := range worklogs {
+					spent += w.Spent
+				}
+				account = tempo.account(worklogs)
+			}
+			if estimated == 0 || spent == 0 {
+				skipped++
+				return nil
+			}
+		}
+
+		for by, accuracy := range groups {
+			names := []string{account}
+			if by != "account" {
+				names = issueGroups(&issue, by)
+			}
+			for _, g := range names {
+				if accuracy[g] == nil {
+					accuracy[g] = &EstimateAccuracy{}
+				}
+				a := accuracy[g]
+				a.Issues++
+				a.Estimated += estimated
+				a.Spent += spent
+				a.Ratios = append(a.Ratios, float64(spent)/float64(estimated))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	estimateTitle, spentTitle := "ESTIMATED", "LOGGED"
+	if *points {
+		estimateTitle, spentTitle = "POINTS", "CYCLE"
+	}
+
+	for _, by := range order {
+		names := make([]string, 0)
+		for name := range groups[by] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Printf("%-24s %6s %10s %10s %8s %8s\n", by, "N", estimateTitle, spentTitle, "RATIO", "MEDIAN")
+		for _, name := range names {
+			a := groups[by][name]
+			estimated := reports.FormatDays(a.Estimated)
+			if *points {
+				estimated = fmt.Sprintf("%.1f", a.Estimated.Hours()/24)
+			}
+			fmt.Printf("%-24s %6d %10s %10s %8.2f %8.2f\n", name, a.Issues, estimated, reports.FormatDays(a.Spent), float64(a.Spent)/float64(a.Estimated), medianRatio(a.Ratios))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%d resolved issues skipped without estimates\n", skipped)
+
+	return nil
+}
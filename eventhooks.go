@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jlewallen/jira-ops/jiraops/events"
+)
+
+var eventNames = []string{
+	events.BeforeTransition, events.AfterTransition,
+	events.BeforeUpdate, events.AfterUpdate,
+	events.BeforeComment, events.AfterComment,
+	events.AfterDownload, events.All,
+}
+
+func newEventBus(config *Config) (*events.Bus, error) {
+	if len(config.Events) == 0 {
+		return nil, nil
+	}
+
+	bus := events.NewBus()
+	for _, ec := range config.Events {
+		known := false
+		for _, name := range eventNames {
+			if ec.Event == name {
+				known = true
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("events: unknown event '%s'", ec.Event)
+		}
+
+		var timeout time.Duration
+		if ec.Timeout != "" {
+			d, err := time.ParseDuration(ec.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("events: %s: %w", ec.Event, err)
+			}
+			timeout = d
+		}
+
+		bus.On(ec.Event, events.Command(ec.Command, timeout))
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/markup"
+)
+
+var exportFields = []string{"summary", "status", "issuetype", "priority", "assignee", "reporter", "labels", "components", "fixVersions", "resolution", "parent", "created", "updated", "description", "comment"}
+
+func frontMatterList(b *bytes.Buffer, name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", name)
+	for _, v := range values {
+		fmt.Fprintf(b, "  - %s\n", strconv.Quote(v))
+	}
+}
+
+func frontMatterValue(b *bytes.Buffer, name, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s: %s\n", name, strconv.Quote(value))
+	}
+}
+
+func displayName(user *jira.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.DisplayName
+}
+
+// makeIssueMarkdown writes front matter that static site generators and
+// grep can both use, followed by the description and comment thread.
+func makeIssueMarkdown(issue *jira.Issue) []byte {
+	f := issue.Fields
+
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	frontMatterValue(&b, "key", issue.Key)
+	frontMatterValue(&b, "title", f.Summary)
+	frontMatterValue(&b, "url", issueURL(issue.Key))
+	frontMatterValue(&b, "type", f.Type.Name)
+	if f.Status != nil {
+		frontMatterValue(&b, "status", f.Status.Name)
+	}
+	if f.Priority != nil {
+		frontMatterValue(&b, "priority", f.Priority.Name)
+	}
+	if f.Resolution != nil {
+		frontMatterValue(&b, "resolution", f.Resolution.Name)
+	}
+	frontMatterValue(&b, "assignee", displayName(f.Assignee))
+	frontMatterValue(&b, "reporter", displayName(f.Reporter))
+	if f.Parent != nil {
+		frontMatterValue(&b, "parent", f.Parent.Key)
+	}
+	frontMatterList(&b, "labels", f.Labels)
+	components := make([]string, 0)
+	for _, c := range f.Components {
+		components = append(components, c.Name)
+	}
+	frontMatterList(&b, "components", components)
+	versions := make([]string, 0)
+	for _, v := range f.FixVersions {
+		versions = append(versions, v.Name)
+	}
+	frontMatterList(&b, "fixVersions", versions)
+	frontMatterValue(&b, "created", time.Time(f.Created).UTC().Format(time.RFC3339))
+	frontMatterValue(&b, "updated", time.Time(f.Updated).UTC().Format(time.RFC3339))
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s: %s\n", issue.Key, f.Summary)
+
+	if description := strings.TrimSpace(f.Description); description != "" {
+		fmt.Fprintf(&b, "\n%s\n", markup.ToMarkdown(description))
+	}
+
+	if f.Comments != nil && len(f.Comments.Comments) > 0 {
+		b.WriteString("\n## Comments\n")
+		for _, c := range f.Comments.Comments {
+			fmt.Fprintf(&b, "\n### %s, %s\n\n%s\n", c.Author.DisplayName, c.Created, markup.ToMarkdown(strings.TrimSpace(c.Body)))
+		}
+	}
+
+	return b.Bytes()
+}
+
+func exportMarkdownCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("export markdown", flag.ExitOnError)
+	jql := flags.String("jql", "", "issues to export")
+	out := flags.String("out", "issues", "directory to write a markdown file per issue to")
+	flags.Parse(args)
+
+	if *jql == "" {
+		return fmt.Errorf("export markdown requires --jql")
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+
+	written, unchanged := 0, 0
+	err := searchIssues(jc, options, *jql, &jira.SearchOptions{MaxResults: 100, Fields: exportFields}, func(issue jira.Issue) error {
+		path := filepath.Join(*out, issue.Key+".md")
+		data := makeIssueMarkdown(&issue)
+
+		// Leaving identical files alone keeps mirrors in git free of noise.
+		if existing, err := ioutil.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+			unchanged++
+			return nil
+		}
+
+		log.Printf("[%s] writing %s", issue.Key, path)
+
+		if options.DryRun {
+			return nil
+		}
+
+		written++
+
+		return ioutil.WriteFile(path, data, 0644)
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	log.Printf("export: %d written, %d unchanged", written, unchanged)
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+const feedLimit = 100
+const feedRefresh = 5 * time.Minute
+
+var feedFields = []string{"summary", "status", "created", "reporter", "updated", "comment"}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type AtomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  *AtomPerson `xml:"author"`
+	Link    *AtomLink   `xml:"link"`
+	Content *AtomText   `xml:"content,omitempty"`
+	time    time.Time
+}
+
+type AtomFeed struct {
+	XMLName xml.Name     `xml:"feed"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	ID      string       `xml:"id"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+	Link    *AtomLink    `xml:"link"`
+	Entries []*AtomEntry `xml:"entry"`
+}
+
+func newAtomEntry(issue *jira.Issue, id, link, title, author string, when time.Time) *AtomEntry {
+	return &AtomEntry{
+		ID:      id,
+		Title:   fmt.Sprintf("%s %s: %s", issue.Key, issue.Fields.Summary, title),
+		Updated: when.UTC().Format(time.RFC3339),
+		Author:  &AtomPerson{Name: valueOr(author, "Jira")},
+		Link:    &AtomLink{Href: link},
+		time:    when,
+	}
+}
+
+// issueEntries has an entry for the issue's creation, each change to its
+// fields and each comment made after after.
+func issueEntries(issue *jira.Issue, after time.Time) []*AtomEntry {
+	entries := make([]*AtomEntry, 0)
+	link := issueURL(issue.Key)
+
+	if created := time.Time(issue.Fields.Created); created.After(after) {
+		entries = append(entries, newAtomEntry(issue, link, link, "created", displayName(issue.Fields.Reporter), created))
+	}
+
+	if issue.Changelog != nil {
+		for _, h := range issue.Changelog.Histories {
+			when, err := h.CreatedTime()
+			if err != nil || !when.After(after) || len(h.Items) == 0 {
+				continue
+			}
+			changes := make([]string, 0)
+			for _, item := range h.Items {
+				changes = append(changes, fmt.Sprintf("%s %s → %s", item.Field, valueOr(item.FromString, "none"), valueOr(item.ToString, "none")))
+			}
+			entry := newAtomEntry(issue, link+"#history-"+h.Id, link, strings.Join(changes, ", "), h.Author.DisplayName, when)
+			entries = append(entries, entry)
+		}
+	}
+
+	if issue.Fields.Comments != nil {
+		for _, c := range issue.Fields.Comments.Comments {
+			when, err := time.Parse(jiraTimeLayout, c.Created)
+			if err != nil || !when.After(after) {
+				continue
+			}
+			comment := fmt.Sprintf("%s?focusedCommentId=%s", link, c.ID)
+			entry := newAtomEntry(issue, comment, comment, "comment by "+c.Author.DisplayName, c.Author.DisplayName, when)
+			entry.Content = &AtomText{Type: "text", Body: c.Body}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+func buildFeed(jc *jira.Client, options *Options, title, jql string, after time.Time, limit int) ([]byte, error) {
+	search := fmt.Sprintf("(updated >= '%s')", after.Format(jqlTimeLayout))
+	if jql != "" {
+		search += fmt.Sprintf(" AND (%s)", jql)
+	}
+	search += " ORDER BY updated DESC"
+
+	entries := make([]*AtomEntry, 0)
+	// Not searchIssues, every build has a new window and would add another
+	// query for sync to refresh.
+	err := pages.Each(options.ctx, jc.Issue, search, &jira.SearchOptions{MaxResults: 100, Expand: "changelog", Fields: feedFields}, func(issue *jira.Issue) error {
+		entries = append(entries, issueEntries(issue, after)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting issues: %w", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].time.After(entries[j].time)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	updated := after
+	if len(entries) > 0 {
+		updated = entries[0].time
+	}
+
+	feed := &AtomFeed{
+		XMLNS:   atomNamespace,
+		ID:      strings.TrimSuffix(JiraUrl, "/") + "/issues/?jql=" + url.QueryEscape(jql),
+		Title:   title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Link:    &AtomLink{Href: strings.TrimSuffix(JiraUrl, "/")},
+		Entries: entries,
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+func (fc *FeedConfig) limit() int {
+	if fc.Limit == nil {
+		return feedLimit
+	}
+	return *fc.Limit
+}
+
+func (fc *FeedConfig) build(jc *jira.Client, options *Options) ([]byte, error) {
+	after, err := parseSince(valueOr(fc.Since, "7d"), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return buildFeed(jc, options, valueOr(fc.Title, "Jira activity"), fc.JQL, after, fc.limit())
+}
+
+func feedCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	fc := config.Feed
+	if fc == nil {
+		fc = &FeedConfig{}
+	}
+
+	flags := flag.NewFlagSet("feed", flag.ExitOnError)
+	jql := flags.String("jql", fc.JQL, "restrict to issues matching this query")
+	since := flags.String("since", valueOr(fc.Since, "7d"), "period of activity included")
+	title := flags.String("title", valueOr(fc.Title, "Jira activity"), "title of the feed")
+	limit := flags.Int("limit", fc.limit(), "most entries included, 0 for all")
+	out := flags.String("out", "", "file to write the feed to, defaults to stdout")
+	flags.Parse(args)
+
+	data, err := (&FeedConfig{JQL: *jql, Since: *since, Title: *title, Limit: limit}).build(jc, options)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	return ioutil.WriteFile(*out, data, 0644)
+}
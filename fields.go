@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/andygrunwald/go-jira"
+)
+
+var listingFields = []string{"summary", "status", "assignee", "issuetype", "priority", "labels", "updated"}
+
+var groupingFields = map[string]string{
+	"component":  "components",
+	"type":       "issuetype",
+	"assignee":   "assignee",
+	"status":     "status",
+	"priority":   "priority",
+	"label":      "labels",
+	"fixVersion": "fixVersions",
+}
+
+func withFields(fields []string, more ...string) []string {
+	combined := append([]string{}, fields...)
+	for _, f := range more {
+		if f == "" {
+			continue
+		}
+		if field, ok := groupingFields[f]; ok {
+			f = field
+		}
+		combined = append(combined, f)
+	}
+	return combined
+}
+
+// listed copies the fields shown in listings, so reports that sort before
+// printing don't hold every changelog and comment until the end of a run.
+func listed(issue *jira.Issue) *jira.Issue {
+	return &jira.Issue{
+		Key: issue.Key,
+		Fields: &jira.IssueFields{
+			Summary:    issue.Fields.Summary,
+			Status:     issue.Fields.Status,
+			Assignee:   issue.Fields.Assignee,
+			Type:       issue.Fields.Type,
+			Priority:   issue.Fields.Priority,
+			Labels:     issue.Fields.Labels,
+			IssueLinks: issue.Fields.IssueLinks,
+			Created:    issue.Fields.Created,
+			Updated:    issue.Fields.Updated,
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+)
+
+func (t *DeployTargetConfig) gates() *DeployGatesConfig {
+	if t.Gates != nil {
+		return t.Gates
+	}
+	return &DeployGatesConfig{FixVersion: true, Blockers: true}
+}
+
+func openBlockers(issue *jira.Issue) []string {
+	blockers := make([]string, 0)
+	for _, link := range issue.Fields.IssueLinks {
+		if link.Type.Name != "Blocks" || link.InwardIssue == nil {
+			continue
+		}
+		blocker := link.InwardIssue
+		if blocker.Fields != nil && blocker.Fields.Status != nil && blocker.Fields.Status.StatusCategory.Key == "done" {
+			continue
+		}
+		blockers = append(blockers, blocker.Key)
+	}
+	return blockers
+}
+
+func checkGates(gates *DeployGatesConfig, issue *jira.Issue) []string {
+	failures := make([]string, 0)
+
+	if gates.FixVersion && len(issue.Fields.FixVersions) == 0 {
+		failures = append(failures, "no fixVersion")
+	}
+
+	if gates.Blockers {
+		if blockers := openBlockers(issue); len(blockers) > 0 {
+			failures = append(failures, fmt.Sprintf("blocked by %s", strings.Join(blockers, ", ")))
+		}
+	}
+
+	for _, field := range gates.Fields {
+		value, ok := issue.Fields.Unknowns[field]
+		if !ok || value == nil || value == "" {
+			failures = append(failures, fmt.Sprintf("%s not set", field))
+		}
+	}
+
+	return failures
+}
+
+func deployCheckCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("deploy check", flag.ExitOnError)
+	version := flags.String("version", "", "only check issues with this fixVersion")
+	positional := parseArgs(flags, args)
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: deploy check <%s>", strings.Join(deployTargetNames(config), "|"))
+	}
+
+	target, err := config.deployTarget(positional[0])
+	if err != nil {
+		return err
+	}
+
+	gates := target.gates()
+
+	issues, err := pages.All(options.ctx, jc.Issue, target.search(*version), &jira.SearchOptions{Fields: withFields([]string{"summary", "fixVersions", "issuelinks"}, gates.Fields...)})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	failed := 0
+
+	reporter := newCheckReporter(options, fmt.Sprintf("Deploy gates: %s", positional[0]))
+
+	for _, i := range issues {
+		failures := checkGates(gates, &i)
+		if len(failures) == 0 {
+			reporter.pass(i.Key, i.Fields.Summary)
+			continue
+		}
+
+		failed++
+		reporter.fail(i.Key, i.Fields.Summary, failures)
+	}
+
+	if err := reporter.close(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d issues failed deploy gates", failed, len(issues))
+	}
+
+	return nil
+}
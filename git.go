@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const defaultBranchTemplate = "{{ .Key | lower }}-{{ .Summary | slug }}"
+const maximumSlugLength = 48
+
+var slugRegexp = regexp.MustCompile("[^a-z0-9]+")
+
+func git(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func slugify(value string) string {
+	slug := strings.Trim(slugRegexp.ReplaceAllString(strings.ToLower(value), "-"), "-")
+	if len(slug) > maximumSlugLength {
+		slug = strings.TrimRight(slug[:maximumSlugLength], "-")
+	}
+	return slug
+}
+
+func issueKeyFromArg(options *Options, arg string) string {
+	if strings.Contains(arg, "-") {
+		return strings.ToUpper(arg)
+	}
+	return fmt.Sprintf("%s-%s", options.Project, arg)
+}
+
+func makeBranchName(config *Config, issue *jira.Issue) (string, error) {
+	text := config.BranchTemplate
+	if text == "" {
+		text = defaultBranchTemplate
+	}
+
+	t, err := template.New("branch").Funcs(template.FuncMap{
+		"lower": strings.ToLower,
+		"slug":  slugify,
+	}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("branch template: %w", err)
+	}
+
+	data := struct {
+		Key     string
+		Summary string
+		Type    string
+	}{
+		Key:     issue.Key,
+		Summary: issue.Fields.Summary,
+		Type:    issue.Fields.Type.Name,
+	}
+
+	var name bytes.Buffer
+	if err := t.Execute(&name, data); err != nil {
+		return "", fmt.Errorf("branch template: %w", err)
+	}
+
+	return name.String(), nil
+}
+
+func branchCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("branch", flag.ExitOnError)
+	pull := flags.Bool("pull", false, "also move the issue to In Progress")
+	positional := parseArgs(flags, args)
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: branch <issue> [--pull]")
+	}
+
+	issue, _, err := jc.Issue.GetWithContext(options.ctx, issueKeyFromArg(options, positional[0]), nil)
+	if err != nil {
+		return fmt.Errorf("error getting issue: %w", err)
+	}
+
+	name, err := makeBranchName(config, issue)
+	if err != nil {
+		return err
+	}
+
+	if _, err := git("rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+		if _, err := git("checkout", name); err != nil {
+			return err
+		}
+	} else if _, err := git("checkout", "-b", name); err != nil {
+		return err
+	}
+
+	log.Printf("[%s] on branch %s", issue.Key, name)
+
+	if *pull {
+		return pullIssue(options.ctx, jc, issue)
+	}
+
+	return nil
+}
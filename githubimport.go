@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/markup"
+)
+
+const githubAPIURL = "https://api.github.com"
+const githubPageSize = 100
+
+var githubReferenceRegexp = regexp.MustCompile(`(^|[^\w/&\[])#(\d+)\b`)
+
+type GitHubUser struct {
+	Login string `json:"login"`
+}
+
+type GitHubComment struct {
+	User    GitHubUser `json:"user"`
+	Body    string     `json:"body"`
+	Created time.Time  `json:"created_at"`
+}
+
+type GitHubIssue struct {
+	Number      int        `json:"number"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	State       string     `json:"state"`
+	URL         string     `json:"html_url"`
+	User        GitHubUser `json:"user"`
+	Created     time.Time  `json:"created_at"`
+	Comments    int        `json:"comments"`
+	PullRequest *struct{}  `json:"pull_request"`
+	Labels      []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+type GitHub struct {
+	url   string
+	token string
+	repo  string
+}
+
+func (g *GitHub) headers() map[string]string {
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	if g.token != "" {
+		headers["Authorization"] = "Bearer " + g.token
+	}
+	return headers
+}
+
+func (g *GitHub) request(ctx context.Context, method, path string, body interface{}, into interface{}) error {
+	if err := incidentRequest(ctx, method, g.url+"/repos/"+g.repo+path, g.headers(), body, into); err != nil {
+		return fmt.Errorf("github: %w", err)
+	}
+	return nil
+}
+
+// issues returns the repository's issues oldest first, so that references
+// to earlier issues can point at their imported copies.
+func (g *GitHub) issues(ctx context.Context, state, labels string) ([]*GitHubIssue, error) {
+	all := make([]*GitHubIssue, 0)
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("state", state)
+		query.Set("sort", "created")
+		query.Set("direction", "asc")
+		query.Set("per_page", fmt.Sprintf("%d", githubPageSize))
+		query.Set("page", fmt.Sprintf("%d", page))
+		if labels != "" {
+			query.Set("labels", labels)
+		}
+
+		found := make([]*GitHubIssue, 0)
+		if err := g.request(ctx, "GET", "/issues?"+query.Encode(), nil, &found); err != nil {
+			return nil, err
+		}
+		for _, issue := range found {
+			if issue.PullRequest == nil {
+				all = append(all, issue)
+			}
+		}
+		if len(found) < githubPageSize {
+			return all, nil
+		}
+	}
+}
+
+func (g *GitHub) comments(ctx context.Context, number int) ([]*GitHubComment, error) {
+	all := make([]*GitHubComment, 0)
+	for page := 1; ; page++ {
+		found := make([]*GitHubComment, 0)
+		path := fmt.Sprintf("/issues/%d/comments?per_page=%d&page=%d", number, githubPageSize, page)
+		if err := g.request(ctx, "GET", path, nil, &found); err != nil {
+			return nil, err
+		}
+		all = append(all, found...)
+		if len(found) < githubPageSize {
+			return all, nil
+		}
+	}
+}
+
+func (g *GitHub) comment(ctx context.Context, number int, body string) error {
+	return g.request(ctx, "POST", fmt.Sprintf("/issues/%d/comments", number), map[string]string{"body": body}, nil)
+}
+
+func (g *GitHub) close(ctx context.Context, number int) error {
+	return g.request(ctx, "PATCH", fmt.Sprintf("/issues/%d", number), map[string]string{"state": "closed"}, nil)
+}
+
+// GitHubImporter converts issues and their comments, rewriting #123
+// references to the Jira issue when that issue has already been imported
+// and to GitHub otherwise.
+type GitHubImporter struct {
+	github   *GitHub
+	web      string
+	imported map[int]string
+}
+
+func (i *GitHubImporter) reference(match string) string {
+	m := githubReferenceRegexp.FindStringSubmatch(match)
+	number, _ := strconv.Atoi(m[2])
+	if key, ok := i.imported[number]; ok {
+		return m[1] + key
+	}
+	return fmt.Sprintf("%s[#%d](%s/issues/%d)", m[1], number, i.web, number)
+}
+
+func (i *GitHubImporter) convert(body string) string {
+	lines := strings.Split(body, "\n")
+	code := false
+	for n, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			code = !code
+			continue
+		}
+		if !code {
+			lines[n] = githubReferenceRegexp.ReplaceAllStringFunc(line, i.reference)
+		}
+	}
+	return markup.FromMarkdown(strings.Join(lines, "\n"))
+}
+
+func (i *GitHubImporter) description(issue *GitHubIssue) string {
+	header := fmt.Sprintf("Imported from [%s#%d|%s], opened by %s on %s.", i.github.repo, issue.Number, issue.URL, issue.User.Login, issue.Created.Local().Format("2006/01/02"))
+	if strings.TrimSpace(issue.Body) == "" {
+		return header
+	}
+	return header + "\n\n" + i.convert(issue.Body)
+}
+
+func (i *GitHubImporter) commentBody(comment *GitHubComment) string {
+	return fmt.Sprintf("*%s* commented on GitHub on %s:\n\n%s", comment.User.Login, comment.Created.Local().Format("2006/01/02 15:04"), i.convert(comment.Body))
+}
+
+func githubLabels(issue *GitHubIssue, extra []string) []string {
+	labels := append([]string{}, extra...)
+	for _, l := range issue.Labels {
+		labels = append(labels, strings.Join(strings.Fields(l.Name), "-"))
+	}
+	return labels
+}
+
+func githubImportCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	gc := config.GitHub
+	if gc == nil {
+		gc = &GitHubConfig{}
+	}
+
+	flags := flag.NewFlagSet("import github", flag.ExitOnError)
+	repo := flags.String("repo", gc.Repo, "repository to import from, as org/repo")
+	label := flags.String("label", "", "only import issues with these comma separated labels")
+	state := flags.String("state", "open", "issues to import (open, closed, all)")
+	project := flags.String("project", valueOr(gc.Project, options.Project), "project to create issues in")
+	issueType := flags.String("type", valueOr(gc.IssueType, "Bug"), "type of the created issues")
+	comment := flags.Bool("comment", true, "comment on the github issue with a link to its jira issue")
+	closeIssues := flags.Bool("close", false, "close github issues once imported")
+	flags.Parse(args)
+
+	if *repo == "" {
+		return fmt.Errorf("import github requires --repo")
+	}
+
+	gh := &GitHub{
+		url:   strings.TrimSuffix(valueOr(gc.URL, githubAPIURL), "/"),
+		token: valueOr(gc.Token, os.Getenv("GITHUB_TOKEN")),
+		repo:  *repo,
+	}
+
+	issues, err := gh.issues(options.ctx, *state, *label)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("import: %d issues in %s", len(issues), *repo)
+
+	importer := &GitHubImporter{github: gh, web: "https://github.com/" + *repo, imported: make(map[int]string)}
+	if len(issues) > 0 {
+		importer.web = issues[0].URL[:strings.LastIndex(issues[0].URL, "/issues/")]
+	}
+
+	for _, issue := range issues {
+		existing, err := findLinkedIssue(options.ctx, jc, issue.URL)
+		if err != nil {
+			return fmt.Errorf("error getting issues: %w", err)
+		}
+		if existing != nil {
+			log.Printf("[%s] %s#%d already imported", existing.Key, *repo, issue.Number)
+			importer.imported[issue.Number] = existing.Key
+			continue
+		}
+
+		fields := &jira.IssueFields{
+			Project:     jira.Project{Key: *project},
+			Type:        jira.IssueType{Name: *issueType},
+			Summary:     issue.Title,
+			Description: importer.description(issue),
+			Labels:      githubLabels(issue, gc.Labels),
+		}
+
+		log.Printf("importing %s#%d '%s' into %s", *repo, issue.Number, issue.Title, *project)
+
+		if options.DryRun {
+			continue
+		}
+
+		created, _, err := jc.Issue.CreateWithContext(options.ctx, &jira.Issue{Fields: fields})
+		if err != nil {
+			return fmt.Errorf("error creating issue: %w", err)
+		}
+
+		importer.imported[issue.Number] = created.Key
+
+		link := &jira.RemoteLink{
+			GlobalID:     issue.URL,
+			Relationship: "imported from",
+			Object: &jira.RemoteLinkObject{
+				URL:   issue.URL,
+				Title: fmt.Sprintf("%s#%d: %s", *repo, issue.Number, issue.Title),
+				Icon:  &jira.RemoteLinkIcon{Url16x16: githubIcon, Title: "GitHub"},
+			},
+		}
+		if _, _, err := jc.Issue.AddRemoteLinkWithContext(options.ctx, created.Key, link); err != nil {
+			return fmt.Errorf("error adding remote link: %w", err)
+		}
+
+		if issue.Comments > 0 {
+			comments, err := gh.comments(options.ctx, issue.Number)
+			if err != nil {
+				return err
+			}
+			for _, c := range comments {
+				if _, _, err := jc.Issue.AddCommentWithContext(options.ctx, created.Key, &jira.Comment{Body: importer.commentBody(c)}); err != nil {
+					return fmt.Errorf("error adding comment: %w", err)
+				}
+			}
+		}
+
+		log.Printf("[%s] created from %s#%d", created.Key, *repo, issue.Number)
+
+		if *comment {
+			if err := gh.comment(options.ctx, issue.Number, fmt.Sprintf("Moved to Jira as [%s](%s).", created.Key, issueURL(created.Key))); err != nil {
+				return err
+			}
+		}
+
+		if *closeIssues && issue.State == "open" {
+			if err := gh.close(options.ctx, issue.Number); err != nil {
+				return err
+			}
+		}
+
+		fmt.Println(issueURL(created.Key))
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func makeHandoffSummary(config *Config, deployment *Deployment, issues []jira.Issue) (string, string) {
+	title := fmt.Sprintf("%s deployed", deployment.Target)
+	if deployment.Version != "" {
+		title = fmt.Sprintf("%s %s deployed", deployment.Target, deployment.Version)
+	}
+	if deployment.Environment != "" {
+		title += " to " + deployment.Environment
+	}
+	title += fmt.Sprintf(", %d issue(s) ready for QA", len(issues))
+
+	lines := make([]string, 0)
+	for _, i := range issues {
+		lines = append(lines, fmt.Sprintf("%s %s\n  %s", i.Key, i.Fields.Summary, issueURL(i.Key)))
+		if field := config.Handoff.TestNotesField; field != "" {
+			if notes, ok := i.Fields.Unknowns[field].(string); ok && strings.TrimSpace(notes) != "" {
+				lines = append(lines, "  Test notes: "+strings.ReplaceAll(strings.TrimSpace(notes), "\n", "\n    "))
+			}
+		}
+	}
+	if deployment.BuildURL != "" {
+		lines = append(lines, "", "Build: "+deployment.BuildURL)
+	}
+
+	return title, strings.Join(lines, "\n")
+}
+
+func sendHandoff(ctx context.Context, config *Config, deployment *Deployment, issues []jira.Issue) error {
+	if config.Handoff == nil {
+		return fmt.Errorf("handoff is not configured")
+	}
+
+	if len(issues) == 0 {
+		return nil
+	}
+
+	title, body := makeHandoffSummary(config, deployment, issues)
+
+	notifiers := make([]Notifier, 0)
+	if config.Handoff.SlackWebhook != "" {
+		notifiers = append(notifiers, &slackNotifier{webhook: config.Handoff.SlackWebhook})
+	}
+	if len(config.Handoff.Email) > 0 {
+		if config.SMTP == nil {
+			return fmt.Errorf("handoff email requires smtp configuration")
+		}
+		notifiers = append(notifiers, &emailNotifier{smtp: config.SMTP, to: config.Handoff.Email})
+	}
+
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, title, body); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("sent qa handoff for %d issues", len(issues))
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const hookMarker = "# installed by jira-ops"
+
+var hookExemptPrefixes = []string{"Merge ", "Revert ", "fixup! ", "squash! ", "amend! "}
+
+func makeCommitMsgHook(executable, mode string) string {
+	return fmt.Sprintf("#!/bin/sh\n%s\nexec '%s' hooks check --mode %s \"$1\"\n", hookMarker, executable, mode)
+}
+
+func hooksInstallCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("hooks install", flag.ExitOnError)
+	mode := flags.String("mode", "reject", "what to do with commits lacking an issue key (reject, prepend)")
+	force := flags.Bool("force", false, "replace an existing commit-msg hook")
+	flags.Parse(args)
+
+	if *mode != "reject" && *mode != "prepend" {
+		return fmt.Errorf("unknown hook mode: %s", *mode)
+	}
+
+	hooks, err := git("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if executable, err = filepath.Abs(executable); err != nil {
+		return err
+	}
+
+	filename := path.Join(hooks, "commit-msg")
+
+	if existing, err := ioutil.ReadFile(filename); err == nil && !strings.Contains(string(existing), hookMarker) && !*force {
+		return fmt.Errorf("%s already exists, use --force to replace it", filename)
+	}
+
+	if err := os.MkdirAll(hooks, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", hooks, err)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte(makeCommitMsgHook(executable, *mode)), 0755); err != nil {
+		return fmt.Errorf("writing %s: %w", filename, err)
+	}
+
+	log.Printf("installed %s (%s)", filename, *mode)
+
+	return nil
+}
+
+func commitMessageText(message string) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func hooksCheckCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("hooks check", flag.ExitOnError)
+	mode := flags.String("mode", "reject", "what to do with commits lacking an issue key (reject, prepend)")
+	positional := parseArgs(flags, args)
+
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: hooks check [--mode reject|prepend] <commit message file>")
+	}
+
+	data, err := ioutil.ReadFile(positional[0])
+	if err != nil {
+		return err
+	}
+
+	text := commitMessageText(string(data))
+	if text == "" {
+		return nil
+	}
+
+	for _, prefix := range hookExemptPrefixes {
+		if strings.HasPrefix(text, prefix) {
+			return nil
+		}
+	}
+
+	projects := config.projects(options)
+	commit := &Commit{Body: text}
+	if len(commit.issueKeys(projects)) > 0 {
+		return nil
+	}
+
+	if *mode == "prepend" {
+		if key, err := currentBranchIssueKey(); err == nil && len((&Commit{Body: key}).issueKeys(projects)) > 0 {
+			return ioutil.WriteFile(positional[0], append([]byte(key+": "), data...), 0644)
+		}
+	}
+
+	return fmt.Errorf("commit message references no issue in %s", strings.Join(projects, ", "))
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const pagerDutyURL = "https://api.pagerduty.com"
+const opsgenieURL = "https://api.opsgenie.com"
+
+const incidentTimelineLimit = 25
+
+var defaultIncidentPriorities = map[string]string{
+	"P1":   "Highest",
+	"P2":   "High",
+	"P3":   "Medium",
+	"P4":   "Low",
+	"P5":   "Lowest",
+	"high": "High",
+	"low":  "Low",
+}
+
+type IncidentEntry struct {
+	Time    time.Time
+	Summary string
+}
+
+type Incident struct {
+	ID       string
+	Name     string
+	Title    string
+	Severity string
+	Status   string
+	URL      string
+	Created  time.Time
+	Timeline []*IncidentEntry
+}
+
+type IncidentProvider interface {
+	Name() string
+	Incident(ctx context.Context, id string) (*Incident, error)
+	AddNote(ctx context.Context, id, note string) error
+}
+
+func incidentRequest(ctx context.Context, method, url string, headers map[string]string, body interface{}, into interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer r.Body.Close()
+
+	if r.StatusCode < 200 || r.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(r.Body)
+		return fmt.Errorf("%s %s: %s %s", method, req.URL.Path, r.Status, strings.TrimSpace(string(message)))
+	}
+
+	if into == nil {
+		return nil
+	}
+
+	return json.NewDecoder(r.Body).Decode(into)
+}
+
+type pagerDuty struct {
+	url   string
+	token string
+	from  string
+}
+
+func (p *pagerDuty) Name() string {
+	return "PagerDuty"
+}
+
+func (p *pagerDuty) headers() map[string]string {
+	return map[string]string{
+		"Authorization": "Token token=" + p.token,
+		"Accept":        "application/vnd.pagerduty+json;version=2",
+		"From":          p.from,
+	}
+}
+
+func (p *pagerDuty) Incident(ctx context.Context, id string) (*Incident, error) {
+	found := struct {
+		Incident struct {
+			ID       string    `json:"id"`
+			Number   int       `json:"incident_number"`
+			Title    string    `json:"title"`
+			Status   string    `json:"status"`
+			Urgency  string    `json:"urgency"`
+			URL      string    `json:"html_url"`
+			Created  time.Time `json:"created_at"`
+			Priority *struct {
+				Summary string `json:"summary"`
+			} `json:"priority"`
+		} `json:"incident"`
+	}{}
+	if err := incidentRequest(ctx, "GET", p.url+"/incidents/"+url.PathEscape(id), p.headers(), nil, &found); err != nil {
+		return nil, fmt.Errorf("pagerduty: %w", err)
+	}
+
+	i := found.Incident
+	incident := &Incident{
+		ID:       i.ID,
+		Name:     fmt.Sprintf("PagerDuty incident #%d", i.Number),
+		Title:    i.Title,
+		Severity: i.Urgency,
+		Status:   i.Status,
+		URL:      i.URL,
+		Created:  i.Created,
+	}
+	if i.Priority != nil && i.Priority.Summary != "" {
+		incident.Severity = i.Priority.Summary
+	}
+
+	entries := struct {
+		LogEntries []struct {
+			Created time.Time `json:"created_at"`
+			Summary string    `json:"summary"`
+		} `json:"log_entries"`
+	}{}
+	query := fmt.Sprintf("/incidents/%s/log_entries?is_overview=true&limit=%d", url.PathEscape(id), incidentTimelineLimit)
+	if err := incidentRequest(ctx, "GET", p.url+query, p.headers(), nil, &entries); err != nil {
+		return nil, fmt.Errorf("pagerduty: %w", err)
+	}
+	for _, e := range entries.LogEntries {
+		incident.Timeline = append(incident.Timeline, &IncidentEntry{Time: e.Created, Summary: e.Summary})
+	}
+
+	return incident, nil
+}
+
+func (p *pagerDuty) AddNote(ctx context.Context, id, note string) error {
+	if p.from == "" {
+		return fmt.Errorf("pagerduty: adding notes requires incidents.from")
+	}
+	body := map[string]interface{}{"note": map[string]string{"content": note}}
+	if err := incidentRequest(ctx, "POST", p.url+"/incidents/"+url.PathEscape(id)+"/notes", p.headers(), body, nil); err != nil {
+		return fmt.Errorf("pagerduty: %w", err)
+	}
+	return nil
+}
+
+type opsgenie struct {
+	url   string
+	token string
+}
+
+func (o *opsgenie) Name() string {
+	return "Opsgenie"
+}
+
+func (o *opsgenie) headers() map[string]string {
+	return map[string]string{"Authorization": "GenieKey " + o.token}
+}
+
+func (o *opsgenie) Incident(ctx context.Context, id string) (*Incident, error) {
+	found := struct {
+		Data struct {
+			ID       string    `json:"id"`
+			TinyID   string    `json:"tinyId"`
+			Message  string    `json:"message"`
+			Status   string    `json:"status"`
+			Priority string    `json:"priority"`
+			Created  time.Time `json:"createdAt"`
+			Links    struct {
+				Web string `json:"web"`
+			} `json:"links"`
+		} `json:"data"`
+	}{}
+	if err := incidentRequest(ctx, "GET", o.url+"/v1/incidents/"+url.PathEscape(id)+"?identifierType=id", o.headers(), nil, &found); err != nil {
+		return nil, fmt.Errorf("opsgenie: %w", err)
+	}
+
+	d := found.Data
+	incident := &Incident{
+		ID:       d.ID,
+		Name:     fmt.Sprintf("Opsgenie incident #%s", d.TinyID),
+		Title:    d.Message,
+		Severity: d.Priority,
+		Status:   d.Status,
+		URL:      d.Links.Web,
+		Created:  d.Created,
+	}
+
+	entries := struct {
+		Data struct {
+			Entries []struct {
+				Time  time.Time `json:"eventTime"`
+				Title struct {
+					Content string `json:"content"`
+				} `json:"title"`
+			} `json:"entries"`
+		} `json:"data"`
+	}{}
+	query := fmt.Sprintf("/v2/incident-timelines/%s/entries?limit=%d", url.PathEscape(id), incidentTimelineLimit)
+	if err := incidentRequest(ctx, "GET", o.url+query, o.headers(), nil, &entries); err != nil {
+		log.Printf("opsgenie: timeline: %v", err)
+	}
+	for _, e := range entries.Data.Entries {
+		incident.Timeline = append(incident.Timeline, &IncidentEntry{Time: e.Time, Summary: e.Title.Content})
+	}
+
+	return incident, nil
+}
+
+func (o *opsgenie) AddNote(ctx context.Context, id, note string) error {
+	body := map[string]string{"note": note}
+	if err := incidentRequest(ctx, "POST", o.url+"/v1/incidents/"+url.PathEscape(id)+"/notes?identifierType=id", o.headers(), body, nil); err != nil {
+		return fmt.Errorf("opsgenie: %w", err)
+	}
+	return nil
+}
+
+func newIncidentProvider(ic *IncidentsConfig) (IncidentProvider, error) {
+	if ic.Token == "" {
+		return nil, fmt.Errorf("incidents.token is required")
+	}
+	switch ic.Provider {
+	case "pagerduty":
+		return &pagerDuty{url: valueOr(ic.URL, pagerDutyURL), token: ic.Token, from: ic.From}, nil
+	case "opsgenie":
+		return &opsgenie{url: valueOr(ic.URL, opsgenieURL), token: ic.Token}, nil
+	}
+	return nil, fmt.Errorf("unknown incident provider: %s", ic.Provider)
+}
+
+func (ic *IncidentsConfig) priority(severity string) string {
+	if p, ok := ic.Priorities[severity]; ok {
+		return p
+	}
+	return defaultIncidentPriorities[severity]
+}
+
+func makeIncidentDescription(incident *Incident) string {
+	lines := []string{
+		fmt.Sprintf("Imported from [%s|%s].", incident.Name, incident.URL),
+		"",
+		fmt.Sprintf("*Severity:* %s", valueOr(incident.Severity, "-")),
+		fmt.Sprintf("*Status:* %s", valueOr(incident.Status, "-")),
+		fmt.Sprintf("*Opened:* %s", incident.Created.Local().Format("2006/01/02 15:04")),
+	}
+
+	if len(incident.Timeline) > 0 {
+		timeline := append([]*IncidentEntry{}, incident.Timeline...)
+		sort.SliceStable(timeline, func(i, j int) bool {
+			return timeline[i].Time.Before(timeline[j].Time)
+		})
+		lines = append(lines, "", "h3. Timeline")
+		for _, e := range timeline {
+			lines = append(lines, fmt.Sprintf("* %s %s", e.Time.Local().Format("2006/01/02 15:04"), e.Summary))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func incidentImportCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	ic := config.Incidents
+	if ic == nil {
+		return fmt.Errorf("incidents are not configured")
+	}
+
+	flags := flag.NewFlagSet("incident import", flag.ExitOnError)
+	project := flags.String("project", valueOr(ic.Project, options.Project), "project to create the issue in")
+	issueType := flags.String("type", valueOr(ic.IssueType, "Bug"), "type of the created issue")
+	note := flags.Bool("note", true, "post the issue's link back to the incident")
+	positional := parseArgs(flags, args)
+
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: incident import <incident-id>")
+	}
+
+	provider, err := newIncidentProvider(ic)
+	if err != nil {
+		return err
+	}
+
+	incident, err := provider.Incident(options.ctx, positional[0])
+	if err != nil {
+		return err
+	}
+
+	existing, err := findLinkedIssue(options.ctx, jc, incident.URL)
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+	if existing != nil {
+		log.Printf("[%s] %s already imported", existing.Key, incident.Name)
+		fmt.Println(issueURL(existing.Key))
+		return nil
+	}
+
+	fields := &jira.IssueFields{
+		Project:     jira.Project{Key: *project},
+		Type:        jira.IssueType{Name: *issueType},
+		Summary:     incident.Title,
+		Description: makeIncidentDescription(incident),
+		Labels:      append([]string{}, ic.Labels...),
+	}
+	if priority := ic.priority(incident.Severity); priority != "" {
+		fields.Priority = &jira.Priority{Name: priority}
+	}
+
+	log.Printf("importing %s '%s' into %s", incident.Name, incident.Title, *project)
+
+	if options.DryRun {
+		fmt.Println(fields.Description)
+		return nil
+	}
+
+	created, _, err := jc.Issue.CreateWithContext(options.ctx, &jira.Issue{Fields: fields})
+	if err != nil {
+		return fmt.Errorf("error creating issue: %w", err)
+	}
+
+	link := &jira.RemoteLink{
+		GlobalID:     incident.URL,
+		Relationship: "incident",
+		Object: &jira.RemoteLinkObject{
+			URL:   incident.URL,
+			Title: incident.Name,
+		},
+	}
+	if _, _, err := jc.Issue.AddRemoteLinkWithContext(options.ctx, created.Key, link); err != nil {
+		return fmt.Errorf("error adding remote link: %w", err)
+	}
+
+	log.Printf("[%s] created from %s", created.Key, incident.Name)
+
+	if *note {
+		if err := provider.AddNote(options.ctx, incident.ID, fmt.Sprintf("Tracked in Jira as %s: %s", created.Key, issueURL(created.Key))); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println(issueURL(created.Key))
+
+	return nil
+}
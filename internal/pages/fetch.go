@@ -0,0 +1,69 @@
+package pages
+
+import (
+	"context"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// Getter is satisfied by jira.Client.Issue and jiratest.Fake.
+type Getter interface {
+	GetWithContext(ctx context.Context, key string, options *jira.GetQueryOptions) (*jira.Issue, *jira.Response, error)
+}
+
+// Fetched pairs an issue from a search with the full issue.
+type Fetched struct {
+	Search *jira.Issue
+	Issue  *jira.Issue
+	Err    error
+}
+
+// Fetch gets the full issue for each search result kept by keep, with up to
+// fetchers requests in flight, delivering them in search order.
+func Fetch(ctx context.Context, getter Getter, issues <-chan *jira.Issue, fetchers int, keep func(*jira.Issue) bool) <-chan *Fetched {
+	if fetchers < 1 {
+		fetchers = 1
+	}
+
+	fetched := make(chan *Fetched)
+	slots := make(chan chan *Fetched, fetchers)
+
+	go func() {
+		defer close(slots)
+		running := make(chan bool, fetchers)
+		for search := range issues {
+			if keep != nil && !keep(search) {
+				continue
+			}
+			slot := make(chan *Fetched, 1)
+			select {
+			case running <- true:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case slots <- slot:
+			case <-ctx.Done():
+				return
+			}
+			go func(search *jira.Issue) {
+				defer func() { <-running }()
+				issue, _, err := getter.GetWithContext(ctx, search.Key, nil)
+				slot <- &Fetched{Search: search, Issue: issue, Err: err}
+			}(search)
+		}
+	}()
+
+	go func() {
+		defer close(fetched)
+		for slot := range slots {
+			select {
+			case fetched <- <-slot:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return fetched
+}
@@ -0,0 +1,149 @@
+// Package pages iterates over Jira search results, fetching pages as
+// they're needed.
+package pages
+
+import (
+	"context"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const DefaultPageSize = 50
+
+// Searcher is satisfied by jira.Client.Issue and jiratest.Fake.
+type Searcher interface {
+	SearchWithContext(ctx context.Context, jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error)
+}
+
+type Iterator struct {
+	issues  Searcher
+	jql     string
+	options jira.SearchOptions
+	page    []jira.Issue
+	index   int
+	total   int
+	issue   *jira.Issue
+	last    bool
+	err     error
+}
+
+// Search returns an iterator over every issue matching jql. The options
+// are copied, so StartAt and MaxResults may be shared between searches.
+func Search(issues Searcher, jql string, options *jira.SearchOptions) *Iterator {
+	it := &Iterator{issues: issues, jql: jql, total: -1}
+	if options != nil {
+		it.options = *options
+	}
+	if it.options.MaxResults == 0 {
+		it.options.MaxResults = DefaultPageSize
+	}
+	return it
+}
+
+func (it *Iterator) fetch(ctx context.Context) error {
+	issues, res, err := it.issues.SearchWithContext(ctx, it.jql, &it.options)
+	if err != nil {
+		return err
+	}
+
+	it.page = issues
+	it.index = 0
+	it.total = res.Total
+	it.last = len(issues) == 0 || res.StartAt+len(issues) >= res.Total
+	it.options.StartAt = res.StartAt + len(issues)
+
+	return nil
+}
+
+// Next advances to the next issue, fetching another page when the current
+// one is exhausted. It returns false at the end of the results or on error.
+func (it *Iterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.index >= len(it.page) {
+		if it.total >= 0 && it.last {
+			return false
+		}
+		if err := ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		if it.err = it.fetch(ctx); it.err != nil {
+			return false
+		}
+		if len(it.page) == 0 {
+			return false
+		}
+	}
+
+	it.issue = &it.page[it.index]
+	it.index++
+
+	return true
+}
+
+// Issue returns the current issue.
+func (it *Iterator) Issue() *jira.Issue {
+	return it.issue
+}
+
+// Total returns the number of matching issues reported by the server, or
+// -1 before the first page is fetched.
+func (it *Iterator) Total() int {
+	return it.total
+}
+
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Each calls fn with every issue matching jql, stopping at the first error.
+func Each(ctx context.Context, searcher Searcher, jql string, options *jira.SearchOptions, fn func(*jira.Issue) error) error {
+	it := Search(searcher, jql, options)
+	for it.Next(ctx) {
+		if err := fn(it.Issue()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// All returns every issue matching jql.
+func All(ctx context.Context, searcher Searcher, jql string, options *jira.SearchOptions) ([]jira.Issue, error) {
+	issues := make([]jira.Issue, 0)
+	it := Search(searcher, jql, options)
+	for it.Next(ctx) {
+		issues = append(issues, *it.Issue())
+	}
+	return issues, it.Err()
+}
+
+// Stream sends every issue matching jql to the returned channel, fetching
+// pages only as the reader keeps up, so at most buffer issues plus one page
+// are held at a time. The channel is closed at the end of the results, on
+// error, or when ctx is cancelled, after which wait returns the error.
+func Stream(ctx context.Context, searcher Searcher, jql string, options *jira.SearchOptions, buffer int) (issues <-chan *jira.Issue, wait func() error) {
+	stream := make(chan *jira.Issue, buffer)
+	done := make(chan struct{})
+
+	var err error
+	go func() {
+		defer close(done)
+		defer close(stream)
+		err = Each(ctx, searcher, jql, options, func(issue *jira.Issue) error {
+			select {
+			case stream <- issue:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	return stream, func() error {
+		<-done
+		return err
+	}
+}
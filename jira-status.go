@@ -2,19 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"log"
-	"net/http"
-	"net/url"
 	"os"
-	"path"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+	"github.com/jlewallen/jira-ops/jiraops/events"
+	"github.com/jlewallen/jira-ops/jiraops/schema"
 )
 
 type Options struct {
@@ -29,6 +29,41 @@ type Options struct {
 	DeployedApp    bool
 	Help           bool
 	Pull           string
+	Notify         string
+	SlackWebhook   string
+	TeamsWebhook   string
+	Extract        bool
+	ExtractLimit   int64
+	MirrorImages   bool
+	MirrorComment  bool
+	MirrorAll      bool
+	DryRun         bool
+	DiffContext    int
+	Config         string
+	JQL            string
+	UpdatedSince   string
+	CheckLinks     bool
+	Workers        int
+	Fetchers       int
+	Only           string
+	Reprocess      bool
+	Rate           float64
+	Burst          int
+	Retries        int
+	Timing         bool
+	Timeout        time.Duration
+	Output         string
+	Cached         bool
+	Offline        bool
+	HttpCache      bool
+	JSON           bool
+	Record         string
+	Replay         string
+	cache          *Cache
+	ctx            context.Context
+	timing         *client.Timing
+	events         *events.Bus
+	projectSet     bool
 }
 
 func echoIssueActionMessage(action string, issue *jira.Issue) {
@@ -39,8 +74,8 @@ func echoIssueStatusMessage(issue *jira.Issue) {
 	fmt.Printf("%-8s %-18s %s\n", issue.Key, issue.Fields.Status.Name, issue.Fields.Summary)
 }
 
-func deleteLink(jc *jira.Client, linkId string) error {
-	req, _ := jc.NewRequest("DELETE", "/rest/api/2/issueLink/"+linkId, nil)
+func deleteLink(ctx context.Context, jc *jira.Client, linkId string) error {
+	req, _ := jc.NewRequestWithContext(ctx, "DELETE", "/rest/api/2/issueLink/"+linkId, nil)
 	_, err := jc.Do(req, nil)
 	if err != nil {
 		return err
@@ -59,23 +94,38 @@ func shouldShow(i *jira.Issue) bool {
 	return false
 }
 
-func displaySearch(jc *jira.Client, search string) error {
-	issues, _, err := jc.Issue.Search(search, nil)
+func displaySearch(jc *jira.Client, options *Options, search string) error {
+	doc := schema.NewDocument(schema.KindIssues)
+	doc.Issues = make([]*schema.Issue, 0)
+
+	fields := listingFields
+	if options.JSON {
+		fields = withFields(listingFields, "resolution", "components", "created")
+	}
+
+	err := searchIssues(jc, options, search, &jira.SearchOptions{MaxResults: 50, Fields: fields}, func(issue jira.Issue) error {
+		if options.JSON {
+			doc.Issues = append(doc.Issues, schema.NewIssue(&issue, issueURL(issue.Key)))
+		} else {
+			echoIssueStatusMessage(&issue)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("error getting issues: %+v", err)
+		return fmt.Errorf("error getting issues: %w", err)
 	}
 
-	for _, issue := range issues {
-		echoIssueStatusMessage(&issue)
+	if options.JSON {
+		return doc.Write(os.Stdout)
 	}
 
 	return nil
 }
 
 func displayIssues(jc *jira.Client, options *Options) error {
-	epics, _, err := jc.Issue.Search("type = 'Epic' AND resolution IS EMPTY ORDER BY dueDate DESC", nil)
+	epics, _, err := jc.Issue.SearchWithContext(options.ctx, "type = 'Epic' AND resolution IS EMPTY ORDER BY dueDate DESC", nil)
 	if err != nil {
-		return fmt.Errorf("error getting issues: %+v", err)
+		return fmt.Errorf("error getting issues: %w", err)
 	}
 
 	for _, i := range epics {
@@ -114,25 +164,8 @@ func displayIssues(jc *jira.Client, options *Options) error {
 	return nil
 }
 
-var imagesRegexp = regexp.MustCompile("![^!\n]+!")
-
-func makeAllImagesThumbnails(body string) (string, error) {
-	newBody := imagesRegexp.ReplaceAllStringFunc(body, func(match string) string {
-		pipes := strings.Split(match, "|")
-		if len(pipes) == 2 {
-			return match
-		}
-
-		nameOnly := strings.Replace(match, "!", "", -1)
-
-		return fmt.Sprintf("!%s|thumbnail!", nameOnly)
-	})
-
-	return newBody, nil
-}
-
-func findVersion(jc *jira.Client, projectKey, search string) (version *jira.Version, err error) {
-	project, _, err := jc.Project.Get(projectKey)
+func findVersion(ctx context.Context, jc *jira.Client, projectKey, search string) (version *jira.Version, err error) {
+	project, _, err := jc.Project.GetWithContext(ctx, projectKey)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +188,7 @@ func findVersion(jc *jira.Client, projectKey, search string) (version *jira.Vers
 }
 
 func reversion(jc *jira.Client, options *Options) error {
-	version, err := findVersion(jc, options.Project, options.Version)
+	version, err := findVersion(options.ctx, jc, options.Project, options.Version)
 	if err != nil {
 		return err
 	}
@@ -167,9 +200,9 @@ func reversion(jc *jira.Client, options *Options) error {
 	for _, issueNumber := range flag.Args() {
 		issueKey := fmt.Sprintf("%s-%s", options.Project, issueNumber)
 
-		issue, _, err := jc.Issue.Get(issueKey, nil)
+		issue, _, err := jc.Issue.GetWithContext(options.ctx, issueKey, nil)
 		if err != nil {
-			return fmt.Errorf("error getting issue: %+v", err)
+			return fmt.Errorf("error getting issue: %w", err)
 		}
 
 		necessary := true
@@ -195,8 +228,8 @@ func reversion(jc *jira.Client, options *Options) error {
 				},
 			}
 
-			if _, _, err := jc.Issue.Update(update); err != nil {
-				return fmt.Errorf("error updating description: %+v", err)
+			if _, _, err := jc.Issue.UpdateWithContext(options.ctx, update); err != nil {
+				return fmt.Errorf("error updating description: %w", err)
 			}
 		}
 	}
@@ -204,251 +237,33 @@ func reversion(jc *jira.Client, options *Options) error {
 	return nil
 }
 
-var spacesRegexp = regexp.MustCompile("[-_\\\\/]")
-var removeRegexp = regexp.MustCompile("[:\"?'+.`!()]")
-var normalizeRegexp = regexp.MustCompile("\\s+")
-var mirroring = regexp.MustCompile("(\\.txt$|\\.zip$|\\.bin$)")
-var diagnosticsURL = regexp.MustCompile("https://code.conservify.org/diagnostics/?\\?id=([a-zA-Z0-9-]+)")
-
-type DownloadFunc func(ctx context.Context) (io.ReadCloser, error)
-
-type MirroredURL struct {
-	Name     string
-	SaveAs   string
-	Download DownloadFunc
-}
-
-func shouldMirror(name string) bool {
-	return mirroring.MatchString(name)
-}
-
-func makeDirectoryName(issue *jira.Issue) string {
-	value := strings.ToLower(fmt.Sprintf("%s_%s", issue.Key, strings.TrimSpace(issue.Fields.Summary)))
-	value = removeRegexp.ReplaceAllLiteralString(value, "")
-	value = spacesRegexp.ReplaceAllLiteralString(value, " ")
-	return normalizeRegexp.ReplaceAllLiteralString(value, "_")
-}
-
-func findExistingDirectory(issue *jira.Issue, files []os.FileInfo) string {
-	prefix := strings.ToLower(fmt.Sprintf("%s", issue.Key))
-	for _, fi := range files {
-		if strings.HasPrefix(strings.ToLower(fi.Name()), prefix) {
-			return fi.Name()
-		}
-	}
-	return ""
-}
-
-func findInlineURLs(issueKey string, text string) []*MirroredURL {
-	urls := make([]*MirroredURL, 0)
-	matches := diagnosticsURL.FindAllStringSubmatch(text, -1)
-	for _, m := range matches {
-		id := m[1]
-		log.Printf("[%s] found diagnostics link id=%s", issueKey, id)
-		urls = append(urls, &MirroredURL{
-			Name:   fmt.Sprintf("diagnostics-%s", id),
-			SaveAs: id + ".zip",
-			Download: func(ctx context.Context) (io.ReadCloser, error) {
-				url := fmt.Sprintf("https://code.conservify.org/diagnostics/archives/%s.zip?token=%s", id, url.QueryEscape(DiagnosticsToken))
-				r, err := http.Get(url)
-				if err != nil {
-					return nil, err
-				}
-				return r.Body, nil
-			},
-		})
-	}
-	return urls
-}
-
-func makeUniqueName(name string, unique string) string {
-	ext := path.Ext(name)
-	noExt := strings.ReplaceAll(name, ext, "")
-	return fmt.Sprintf("%s_%s%s", noExt, unique, ext)
-}
-
-func findAllURLs(jc *jira.Client, issue *jira.Issue) []*MirroredURL {
-	urls := findInlineURLs(issue.Key, issue.Fields.Description)
-	for _, c := range issue.Fields.Comments.Comments {
-		urls = append(urls, findInlineURLs(issue.Key, c.Body)...)
-	}
-	for _, a := range issue.Fields.Attachments {
-		if shouldMirror(a.Filename) {
-			log.Printf("[%s] attached: %+v (considering)", issue.Key, a.Filename)
-			id := a.ID
-			name := a.Filename
-			urls = append(urls, &MirroredURL{
-				Name:   name,
-				SaveAs: makeUniqueName(a.Filename, a.ID),
-				Download: func(ctx context.Context) (io.ReadCloser, error) {
-					r, err := jc.Issue.DownloadAttachmentWithContext(ctx, id)
-					if err != nil {
-						return nil, fmt.Errorf("downloading: %v", err)
-					}
-					return r.Body, nil
-				},
-			})
-		} else {
-			log.Printf("[%s] attached: %+v (ignoring)", issue.Key, a.Filename)
-		}
-	}
-	return urls
-}
-
-func mirror(jc *jira.Client, options *Options) error {
-	issues, _, err := jc.Issue.Search(`component IN ("Firmware", "Portal", "Backend", "Mobile App") AND resolution IS EMPTY ORDER BY updated DESC`, nil)
-	if err != nil {
-		return fmt.Errorf("error getting issues: %+v", err)
-	}
-
-	base := "/home/jlewallen/downloads/jira"
-
-	if err := os.MkdirAll(base, 0755); err != nil {
-		return fmt.Errorf("creating %s: %v", base, err)
-	}
-
-	files, err := ioutil.ReadDir(base)
+func changeStatus(issues client.Issues, options *Options, search, desired string) ([]jira.Issue, error) {
+	found, err := pages.All(options.ctx, issues, search, nil)
 	if err != nil {
-		return fmt.Errorf("reading %s: %v", base, err)
+		return nil, fmt.Errorf("error getting issues: %w", err)
 	}
 
-	ctx := context.Background()
+	changed := make([]jira.Issue, 0)
 
-	for _, i := range issues {
-		issue, _, err := jc.Issue.Get(i.Key, nil)
-		if err != nil {
-			return fmt.Errorf("error getting issue: %+v", err)
+	for _, i := range found {
+		if options.DryRun {
+			echoIssueActionMessage("would change", &i)
+			changed = append(changed, i)
+			continue
 		}
 
-		directoryName := findExistingDirectory(issue, files)
-		if len(directoryName) == 0 {
-			directoryName = makeDirectoryName(issue)
+		if err := changeIssueStatus(options.ctx, issues, &i, desired); err != nil {
+			return changed, err
 		}
 
-		log.Printf("[%s] dir=%v '%s'", issue.Key, directoryName, issue.Fields.Summary)
-
-		full := path.Join(base, directoryName)
-
-		if err := os.MkdirAll(full, 0755); err != nil {
-			return nil
-		}
-
-		for _, url := range findAllURLs(jc, issue) {
-			saveAsFull := path.Join(full, url.SaveAs)
-			_, err := os.Stat(saveAsFull)
-			if os.IsNotExist(err) {
-				log.Printf("[%s] downloading %s -> %s", issue.Key, url.Name, url.SaveAs)
-				if reader, err := url.Download(ctx); err != nil {
-					return err
-				} else if reader != nil {
-					defer reader.Close()
-
-					file, err := os.Create(saveAsFull)
-					if err != nil {
-						return err
-					}
-
-					defer file.Close()
-
-					if _, err := io.Copy(file, reader); err != nil {
-						return err
-					}
-				}
-			}
-		}
+		changed = append(changed, i)
 	}
 
-	return nil
+	return changed, nil
 }
 
-func upkeep(jc *jira.Client, options *Options) error {
-	issues, _, err := jc.Issue.Search("resolution IS EMPTY ORDER BY updated DESC", nil)
-	if err != nil {
-		return fmt.Errorf("error getting issues: %+v", err)
-	}
-
-	enabled := true
-
-	for _, i := range issues {
-		if false {
-			fmt.Printf("%+v", i.Fields.Description)
-		}
-
-		issue, _, err := jc.Issue.Get(i.Key, nil)
-		if err != nil {
-			return fmt.Errorf("error getting issue: %+v", err)
-		}
-
-		newDescription, err := makeAllImagesThumbnails(i.Fields.Description)
-		if err != nil {
-			return fmt.Errorf("error changing thumbnails: %+v", err)
-		}
-
-		if newDescription != i.Fields.Description {
-			fmt.Printf("%-8s %v (%d linked)\n", i.Key, i.Fields.Summary, len(i.Fields.IssueLinks))
-
-			update := &jira.Issue{
-				Key: i.Key,
-				Fields: &jira.IssueFields{
-					Description: newDescription,
-				},
-			}
-
-			if enabled {
-				if _, _, err := jc.Issue.Update(update); err != nil {
-					return fmt.Errorf("error updating description: %+v", err)
-				}
-			}
-
-			fmt.Printf("OLD: '%v'\n", i.Fields.Description)
-			fmt.Printf("NEW: '%v'\n", newDescription)
-		}
-
-		for _, c := range issue.Fields.Comments.Comments {
-			newBody, err := makeAllImagesThumbnails(c.Body)
-			if err != nil {
-				return fmt.Errorf("error changing thumbnails: %+v", err)
-			}
-
-			if newBody != c.Body {
-				fmt.Printf("%+v %v (%d linked)\n", i.Key, i.Fields.Summary, len(i.Fields.IssueLinks))
-
-				if enabled {
-					c.Body = newBody
-					if _, _, err := jc.Issue.UpdateComment(i.Key, c); err != nil {
-						return fmt.Errorf("error updating: %+v", err)
-					}
-				}
-				fmt.Printf("OLD: '%v'\n", c.Body)
-				fmt.Printf("NEW: '%v'\n", newBody)
-			}
-		}
-	}
-
-	return nil
-}
-
-func changeStatus(jc *jira.Client, options *Options, search, desired string) error {
-	issues, _, err := jc.Issue.Search(search, nil)
-	if err != nil {
-		return fmt.Errorf("error getting issues: %+v", err)
-	}
-
-	for _, i := range issues {
-		if false {
-			fmt.Printf("%-8s %-18s %s\n", i.Key, i.Fields.Status.Name, i.Fields.Summary)
-		}
-
-		if err := changeIssueStatus(jc, &i, desired); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func changeIssueStatus(jc *jira.Client, issue *jira.Issue, desired string) error {
-	transitions, _, err := jc.Issue.GetTransitions(issue.ID)
+func changeIssueStatus(ctx context.Context, issues client.Issues, issue *jira.Issue, desired string) error {
+	transitions, _, err := issues.GetTransitionsWithContext(ctx, issue.Key)
 	if err != nil {
 		return err
 	}
@@ -456,85 +271,191 @@ func changeIssueStatus(jc *jira.Client, issue *jira.Issue, desired string) error
 	for _, transition := range transitions {
 		if transition.To.Name == desired {
 			echoIssueActionMessage("changing", issue)
-			if _, err := jc.Issue.DoTransition(issue.ID, transition.ID); err != nil {
-				return fmt.Errorf("error updating status: %+v", err)
+			if _, err := issues.DoTransitionWithContext(ctx, issue.Key, transition.ID); err != nil {
+				return fmt.Errorf("error updating status: %w", err)
 			}
 			return nil
 		}
 	}
 
-	return fmt.Errorf("missing transition")
+	return fmt.Errorf("no transition from %s to '%s': %w", issue.Key, desired, client.ErrTransitionUnavailable)
 }
 
-func findIssue(jc *jira.Client, search string) (*jira.Issue, error) {
-	issues, _, err := jc.Issue.Search(search, nil)
+func findIssue(ctx context.Context, jc *jira.Client, search string) (*jira.Issue, error) {
+	issues, _, err := jc.Issue.SearchWithContext(ctx, search, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error getting issues: %+v", err)
+		return nil, fmt.Errorf("error getting issues: %w", err)
 	}
 
 	if len(issues) != 1 {
-		return nil, fmt.Errorf("unable to find issue")
+		return nil, fmt.Errorf("unable to find issue: %w", client.ErrNotFound)
 	}
 
 	return &issues[0], nil
 }
 
-func pullIssue(jc *jira.Client, issue *jira.Issue) error {
-	return changeIssueStatus(jc, issue, "In Progress")
+func pullIssue(ctx context.Context, jc *jira.Client, issue *jira.Issue) error {
+	return changeIssueStatus(ctx, jc.Issue, issue, "In Progress")
 }
 
 func main() {
 	options := &Options{}
+	flag.StringVar(&options.Config, "config", defaultConfigPath(), "path to configuration file")
 	flag.StringVar(&options.Project, "project", "FK", "default project prefix, should rarely change")
 	flag.StringVar(&options.Version, "version", "", "version to link issues to")
 	flag.StringVar(&options.Pull, "pull", "", "pull a card to start working")
 	flag.StringVar(&options.Search, "search", "", "search cards")
 	flag.BoolVar(&options.Progress, "progress", false, "display mine in progress")
-	flag.BoolVar(&options.Upkeep, "upkeep", false, "fix thumbnails on recently modified issues")
+	flag.BoolVar(&options.Upkeep, "upkeep", false, "apply text rules to recently modified issues")
+	flag.StringVar(&options.JQL, "jql", "", "restrict upkeep to issues matching this query")
+	flag.StringVar(&options.UpdatedSince, "updated-since", "last", "restrict upkeep to issues updated within a window (7d, 12h, last, all)")
+	flag.BoolVar(&options.CheckLinks, "check-links", false, "report dead links found during upkeep")
+	flag.StringVar(&options.Only, "only", "", "restrict upkeep to these issues (FK-123,FK-124)")
+	flag.BoolVar(&options.Reprocess, "reprocess", false, "process issues again even when their text is unchanged since the last upkeep")
+	flag.IntVar(&options.Workers, "workers", 4, "number of issues to process concurrently")
+	flag.IntVar(&options.Fetchers, "fetchers", 8, "number of issues to fetch concurrently ahead of processing")
+	flag.Float64Var(&options.Rate, "rate", 10, "maximum Jira requests per second (0 for unlimited)")
+	flag.IntVar(&options.Burst, "burst", 5, "requests allowed in a burst before --rate applies")
+	flag.IntVar(&options.Retries, "retries", 4, "attempts for idempotent requests that fail with transient errors")
+	flag.DurationVar(&options.Timeout, "timeout", 0, "give up on the whole run after this long (5m), 0 for no limit")
+	flag.BoolVar(&options.Timing, "timing", false, "print request latencies per endpoint at the end of a run")
+	flag.StringVar(&options.Output, "output", "", "check output format (text, github), github is the default in GitHub Actions")
+	flag.BoolVar(&options.Cached, "cached", false, "read reports and listings from the local cache kept by 'sync'")
+	flag.BoolVar(&options.Offline, "offline", false, "serve listings and reports from the local cache without contacting jira")
+	flag.BoolVar(&options.HttpCache, "http-cache", true, "revalidate repeated jira requests with etags instead of downloading them again")
+	flag.StringVar(&options.Record, "record", "", "save every jira request and response to this file")
+	flag.StringVar(&options.Replay, "replay", "", "answer jira requests from a file saved with --record instead of contacting jira")
+	flag.BoolVar(&options.DryRun, "dry-run", false, "show changes without saving them")
+	flag.IntVar(&options.DiffContext, "diff-context", 3, "lines of context in displayed diffs")
 	flag.BoolVar(&options.Pending, "pending", false, "issues ready for deploy")
-	flag.BoolVar(&options.DeployedPortal, "deployed-portal", false, "deployed portal")
-	flag.BoolVar(&options.DeployedApp, "deployed-app", false, "deployed app")
+	flag.BoolVar(&options.DeployedPortal, "deployed-portal", false, "deployed portal, same as 'deploy portal'")
+	flag.BoolVar(&options.DeployedApp, "deployed-app", false, "deployed app, same as 'deploy app'")
 	flag.BoolVar(&options.Mirror, "mirror", false, "mirror card assets")
+	flag.BoolVar(&options.MirrorImages, "mirror-images", false, "also mirror images referenced in descriptions and comments")
+	flag.BoolVar(&options.MirrorComment, "mirror-comment", false, "post a summary of mirrored files on each issue")
+	flag.BoolVar(&options.MirrorAll, "mirror-all", false, "mirror every open issue, not just those updated since the last mirror")
+	flag.BoolVar(&options.Extract, "extract", false, "unpack mirrored zip archives")
+	flag.Int64Var(&options.ExtractLimit, "extract-limit", 1024, "maximum megabytes to extract from a single archive")
+	flag.StringVar(&options.Notify, "notify", "", "notify on newly mirrored files (desktop, slack or teams)")
+	flag.StringVar(&options.SlackWebhook, "slack-webhook", "", "slack incoming webhook url for notifications")
+	flag.StringVar(&options.TeamsWebhook, "teams-webhook", "", "teams incoming webhook url for notifications")
+	flag.BoolVar(&options.JSON, "json", false, "print issues, reports and mirror results as json, and errors to stderr as json with a kind and exit code")
 	flag.BoolVar(&options.Help, "help", false, "help")
+	flag.Usage = usage
 	flag.Parse()
 
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "project" {
+			options.projectSet = true
+		}
+	})
+
 	if options.Help {
 		flag.Usage()
 		return
 	}
 
-	jc, err := jira.NewClient(nil, JiraUrl)
+	options.ctx = interruptible()
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		options.ctx, cancel = context.WithTimeout(options.ctx, options.Timeout)
+		defer cancel()
+	}
+
+	config, err := loadConfig(options.Config)
 	if err != nil {
-		fmt.Printf("error creating client: %+v\n", err)
+		fail(options, err)
+	}
+
+	command, args := findCommand(commands, flag.Args())
+	if command != nil && command.Run == nil {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Only commands reading the cache open it, a long running serve or
+	// notify would otherwise keep everything else waiting on its lock.
+	if options.Cached || servesOffline(command, options) {
+		options.cache, err = openCache(false, options.Cached || options.Offline)
+		if err != nil {
+			fail(options, err)
+		}
+
+		defer options.cache.close()
+	}
+
+	options.events, err = newEventBus(config)
+	if err != nil {
+		fail(options, err)
+	}
+
+	if options.Timing {
+		options.timing = client.NewTiming()
+		defer options.timing.Report(os.Stderr)
+	}
+
+	if options.Cached && options.cache == nil {
+		fail(options, fmt.Errorf("no cache, run sync first"))
+	}
+
+	if command != nil && command.Offline {
+		if err := command.Run(nil, config, options, args); err != nil {
+			fail(options, err)
+		}
 		return
 	}
 
-	res, err := jc.Authentication.AcquireSessionCookie(JiraUsername, JiraPassword)
-	if err != nil || res == false {
-		log.Fatalf("error authenticating: %+v", err)
+	var jc *jira.Client
+	if !options.Offline {
+		jc, err = newClient(config, options)
+		if err != nil {
+			if options.cache == nil || !servesOffline(command, options) || errors.Is(err, client.ErrAuth) {
+				fail(options, err)
+			}
+			log.Printf("warning: %v, falling back to the local cache", err)
+			options.Offline = true
+		}
+	}
+
+	if options.Offline {
+		if !servesOffline(command, options) {
+			fail(options, fmt.Errorf("not available offline"))
+		}
+		if options.cache == nil {
+			fail(options, fmt.Errorf("no cache, run sync first"))
+		}
+		options.Cached = true
+		log.Printf("offline: data as of %s", options.cache.synced().Local().Format("2006/01/02 15:04"))
+	}
+
+	if command != nil {
+		if err := command.Run(jc, config, options, args); err != nil {
+			fail(options, err)
+		}
+		return
 	}
 
 	if options.Upkeep {
 		log.Printf("querying for issues")
 
-		if err := upkeep(jc, options); err != nil {
-			log.Fatalf("error: %v", err)
+		if err := runUpkeep(jc.Issue, config, options); err != nil {
+			fail(options, err)
 		}
 		return
 	}
 
 	if options.Mirror {
 		log.Printf("mirroring")
-		if err := mirror(jc, options); err != nil {
-			log.Fatalf("error: %v", err)
+		if err := mirrorIssues(jc, config, options); err != nil {
+			fail(options, err)
 		}
 		return
 	}
 
 	if options.Progress {
 		search := fmt.Sprintf(`(project = 'FK') AND (status = 'In Progress') AND (assignee = currentUser())`)
-		if err := displaySearch(jc, search); err != nil {
-			log.Fatalf("error: %v", err)
+		if err := displaySearch(jc, options, search); err != nil {
+			fail(options, err)
 		}
 		return
 	}
@@ -542,8 +463,8 @@ func main() {
 	if options.Search != "" {
 		search := fmt.Sprintf(`(project = 'FK') AND (resolution IS EMPTY) AND (summary ~ '%s*')`, options.Search)
 		// log.Printf("searching: %s", search)
-		if err := displaySearch(jc, search); err != nil {
-			log.Fatalf("error: %v", err)
+		if err := displaySearch(jc, options, search); err != nil {
+			fail(options, err)
 		}
 		return
 	}
@@ -551,43 +472,40 @@ func main() {
 	if options.Pull != "" {
 		issueKey := fmt.Sprintf("%s-%s", options.Project, options.Pull)
 		search := fmt.Sprintf(`(key = '%s')`, issueKey)
-		issue, err := findIssue(jc, search)
+		issue, err := findIssue(options.ctx, jc, search)
 		if err != nil {
-			log.Fatalf("error: %v", err)
+			fail(options, err)
 		}
-		if err := pullIssue(jc, issue); err != nil {
-			log.Fatalf("error: %v", err)
+		if err := pullIssue(options.ctx, jc, issue); err != nil {
+			fail(options, err)
 		}
 		return
 	}
 
 	if options.Pending {
-		search := `status IN ("Ready for Deploy") AND component IN ("Firmware", "Portal", "Backend", "Mobile App")`
-		if err := displaySearch(jc, search); err != nil {
-			log.Fatalf("error: %v", err)
+		if err := displaySearch(jc, options, pendingSearch(config)); err != nil {
+			fail(options, err)
 		}
 		return
 	}
 
 	if options.DeployedPortal {
-		search := `status IN ("Ready for Deploy") AND component IN ("Portal", "Backend")`
-		if err := changeStatus(jc, options, search, "Awaiting QA"); err != nil {
-			log.Fatalf("error: %v", err)
+		if err := deploy(jc.Issue, config, options, &Deployment{Target: "portal"}); err != nil {
+			fail(options, err)
 		}
 		return
 	}
 
 	if options.DeployedApp {
-		search := `status IN ("Ready for Deploy") AND component IN ("Mobile App")`
-		if err := changeStatus(jc, options, search, "Awaiting QA"); err != nil {
-			log.Fatalf("error: %v", err)
+		if err := deploy(jc.Issue, config, options, &Deployment{Target: "app"}); err != nil {
+			fail(options, err)
 		}
 		return
 	}
 
 	if len(options.Version) > 0 {
 		if err := reversion(jc, options); err != nil {
-			log.Fatalf("error: %v", err)
+			fail(options, err)
 		}
 
 		return
@@ -599,7 +517,7 @@ func main() {
 			   (project IN ('FK')) AND
 			   (assignee = currentUser() OR assignee WAS currentUser() OR reporter = currentUser() OR comment ~ currentUser() OR watcher = currentUser())
 		       ORDER BY updated DESC`
-	if err := displaySearch(jc, search); err != nil {
-		log.Fatalf("error: %v", err)
+	if err := displaySearch(jc, options, search); err != nil {
+		fail(options, err)
 	}
 }
@@ -0,0 +1,216 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Interaction is a single recorded request and its response. Requests are
+// matched on method, path and query, and body, never on host, so recordings
+// replay against any base URL.
+type Interaction struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"requestBody,omitempty"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        string      `json:"body"`
+	replayed    bool
+}
+
+// Cassette is a file of interactions, in the order they were made.
+type Cassette struct {
+	Path         string         `json:"-"`
+	Interactions []*Interaction `json:"interactions"`
+	lock         sync.Mutex
+}
+
+var redactedRequests = []string{"/rest/auth/1/session"}
+
+var redactedHeaders = []string{"Set-Cookie", "Authorization", "Cookie"}
+
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := &Cassette{Path: path}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("error reading cassette %s: %w", path, err)
+	}
+
+	return cassette, nil
+}
+
+func NewCassette(path string) *Cassette {
+	return &Cassette{Path: path, Interactions: make([]*Interaction, 0)}
+}
+
+func (c *Cassette) save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.Path, data, 0600)
+}
+
+func requestURL(req *http.Request) string {
+	return req.URL.RequestURI()
+}
+
+func readRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return string(body), nil
+}
+
+// redactSession replaces the session token in a login response, leaving a
+// body go-jira still accepts when the cassette is replayed.
+func redactSession(body string) string {
+	response := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		return ""
+	}
+	if session, ok := response["session"].(map[string]interface{}); ok {
+		session["value"] = "redacted"
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func redacted(url string) bool {
+	for _, prefix := range redactedRequests {
+		if strings.HasPrefix(url, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordingTransport appends every exchange to the cassette, saving after
+// each so an interrupted run still leaves a usable recording. Credentials
+// and cookies are never written.
+type RecordingTransport struct {
+	Base     http.RoundTripper
+	Cassette *Cassette
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	interaction := &Interaction{
+		Method:      req.Method,
+		URL:         requestURL(req),
+		RequestBody: requestBody,
+		Status:      res.StatusCode,
+		Header:      res.Header.Clone(),
+		Body:        string(body),
+	}
+	for _, name := range redactedHeaders {
+		interaction.Header.Del(name)
+	}
+	if redacted(interaction.URL) {
+		interaction.RequestBody = ""
+		interaction.Body = redactSession(interaction.Body)
+	}
+
+	t.Cassette.lock.Lock()
+	defer t.Cassette.lock.Unlock()
+
+	t.Cassette.Interactions = append(t.Cassette.Interactions, interaction)
+	if err := t.Cassette.save(); err != nil {
+		return nil, fmt.Errorf("error saving cassette: %w", err)
+	}
+
+	return res, nil
+}
+
+// ReplayTransport answers requests from a cassette without touching the
+// network. Each interaction is used once, in recorded order, and a request
+// with no match is an error so changes in query construction or payloads
+// show up as failures.
+type ReplayTransport struct {
+	Cassette *Cassette
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	url := requestURL(req)
+
+	t.Cassette.lock.Lock()
+	defer t.Cassette.lock.Unlock()
+
+	for _, i := range t.Cassette.Interactions {
+		if i.replayed || i.Method != req.Method || i.URL != url {
+			continue
+		}
+		if !redacted(url) && i.RequestBody != requestBody {
+			continue
+		}
+
+		i.replayed = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+			StatusCode:    i.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Header.Clone(),
+			Body:          ioutil.NopCloser(strings.NewReader(i.Body)),
+			ContentLength: int64(len(i.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no recorded response for %s %s", req.Method, url)
+}
+
+// Unplayed returns the interactions a replay never reached.
+func (c *Cassette) Unplayed() []*Interaction {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	unplayed := make([]*Interaction, 0)
+	for _, i := range c.Interactions {
+		if !i.replayed {
+			unplayed = append(unplayed, i)
+		}
+	}
+	return unplayed
+}
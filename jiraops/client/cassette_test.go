@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sessionToken = "6E3487971234567896704A9EB4AE501F"
+
+func TestCassetteRedactsSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/rest/auth/1/session" {
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: sessionToken})
+			w.Write([]byte(`{"session":{"name":"JSESSIONID","value":"` + sessionToken + `"},"loginInfo":{"loginCount":1}}`))
+			return
+		}
+		w.Write([]byte(`{"key":"FK-1","fields":{"summary":"One"}}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	jc, err := NewWithContext(context.Background(), &Options{URL: server.URL, Username: "jacob", Password: "hunter2", Record: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := jc.Issue.GetWithContext(context.Background(), "FK-1", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{sessionToken, "hunter2"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %s:\n%s", secret, data)
+		}
+	}
+
+	jc, err = NewWithContext(context.Background(), &Options{URL: "http://jira.invalid", Username: "jacob", Password: "hunter2", Replay: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue, _, err := jc.Issue.GetWithContext(context.Background(), "FK-1", nil)
+	if err != nil || issue.Fields.Summary != "One" {
+		t.Fatal(issue, err)
+	}
+}
@@ -0,0 +1,198 @@
+// Package client builds Jira clients whose transports rate limit, retry,
+// revalidate cached responses and time requests.
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/events"
+)
+
+type HTTPConfig struct {
+	RequestTimeout        string `json:"requestTimeout"`
+	DialTimeout           string `json:"dialTimeout"`
+	KeepAlive             string `json:"keepAlive"`
+	TLSHandshakeTimeout   string `json:"tlsHandshakeTimeout"`
+	ResponseHeaderTimeout string `json:"responseHeaderTimeout"`
+	IdleConnTimeout       string `json:"idleConnTimeout"`
+	MaxIdleConns          int    `json:"maxIdleConns"`
+	MaxIdleConnsPerHost   int    `json:"maxIdleConnsPerHost"`
+	DisableKeepAlives     bool   `json:"disableKeepAlives"`
+}
+
+type Options struct {
+	URL      string
+	Username string
+	Password string
+
+	// Rate is the maximum requests per second, zero for unlimited.
+	Rate  float64
+	Burst int
+
+	// Retries is the number of attempts for idempotent requests that fail
+	// with transient errors.
+	Retries int
+
+	// CacheDirectory enables conditional revalidation of GET responses.
+	CacheDirectory string
+
+	Timing *Timing
+	HTTP   *HTTPConfig
+
+	// Record saves every exchange to a cassette at this path, Replay serves
+	// them back from one instead of contacting Jira.
+	Record string
+	Replay string
+
+	// Events are fired around transitions, updates and comments.
+	Events *events.Bus
+}
+
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelingBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// DeadlineTransport bounds each attempt, including reading the body.
+type DeadlineTransport struct {
+	Base    http.RoundTripper
+	Timeout time.Duration
+}
+
+func (t *DeadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.Timeout)
+	res, err := t.Base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res.Body = &cancelingBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+func NewTransport(c *HTTPConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c == nil {
+		return transport, nil
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+	settings := []struct {
+		name  string
+		value string
+		into  *time.Duration
+	}{
+		{"dialTimeout", c.DialTimeout, &dialer.Timeout},
+		{"keepAlive", c.KeepAlive, &dialer.KeepAlive},
+		{"tlsHandshakeTimeout", c.TLSHandshakeTimeout, &transport.TLSHandshakeTimeout},
+		{"responseHeaderTimeout", c.ResponseHeaderTimeout, &transport.ResponseHeaderTimeout},
+		{"idleConnTimeout", c.IdleConnTimeout, &transport.IdleConnTimeout},
+	}
+	for _, s := range settings {
+		if s.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(s.value)
+		if err != nil {
+			return nil, fmt.Errorf("http.%s: %w", s.name, err)
+		}
+		*s.into = d
+	}
+
+	transport.DialContext = dialer.DialContext
+	transport.DisableKeepAlives = c.DisableKeepAlives
+	if c.MaxIdleConns > 0 {
+		transport.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+
+	return transport, nil
+}
+
+func NewHTTPClient(o *Options) (*http.Client, error) {
+	if o.Replay != "" {
+		cassette, err := LoadCassette(o.Replay)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Client{Transport: &ErrorTransport{Base: &ReplayTransport{Cassette: cassette}}}, nil
+	}
+
+	transport, err := NewTransport(o.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	var base http.RoundTripper = transport
+	if o.HTTP != nil && o.HTTP.RequestTimeout != "" {
+		timeout, err := time.ParseDuration(o.HTTP.RequestTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("http.requestTimeout: %w", err)
+		}
+		base = &DeadlineTransport{Base: base, Timeout: timeout}
+	}
+	if o.Timing != nil {
+		base = &TimingTransport{Base: base, Timing: o.Timing}
+	}
+	limited := &RateLimitedTransport{Base: base}
+	if o.Rate > 0 {
+		limited.Bucket = NewTokenBucket(o.Rate, o.Burst)
+	}
+	var outer http.RoundTripper = limited
+	if o.Retries > 1 {
+		outer = &RetryTransport{Base: outer, Policy: &RetryPolicy{Attempts: o.Retries, Base: DefaultRetryBase, Maximum: DefaultRetryMaximum}}
+	}
+	if o.CacheDirectory != "" {
+		outer = &CachingTransport{Base: outer, Directory: o.CacheDirectory}
+	}
+	if o.Record != "" {
+		outer = &RecordingTransport{Base: outer, Cassette: NewCassette(o.Record)}
+	}
+
+	if o.Events != nil {
+		outer = &EventTransport{Base: outer, Bus: o.Events}
+	}
+
+	return &http.Client{Transport: &ErrorTransport{Base: outer}}, nil
+}
+
+// New returns a client authenticated with a session cookie.
+func New(o *Options) (*jira.Client, error) {
+	return NewWithContext(context.Background(), o)
+}
+
+func NewWithContext(ctx context.Context, o *Options) (*jira.Client, error) {
+	hc, err := NewHTTPClient(o)
+	if err != nil {
+		return nil, err
+	}
+
+	jc, err := jira.NewClient(hc, o.URL)
+	if err != nil {
+		return nil, fmt.Errorf("error creating client: %w", err)
+	}
+
+	res, err := jc.Authentication.AcquireSessionCookieWithContext(ctx, o.Username, o.Password)
+	if err != nil {
+		return nil, fmt.Errorf("error authenticating: %w: %w", ErrAuth, err)
+	}
+	if !res {
+		return nil, fmt.Errorf("error authenticating: %w", ErrAuth)
+	}
+
+	return jc, nil
+}
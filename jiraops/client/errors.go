@@ -0,0 +1,88 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrAuth                  = errors.New("authentication failed")
+	ErrNotFound              = errors.New("not found")
+	ErrRateLimited           = errors.New("rate limited")
+	ErrTransitionUnavailable = errors.New("transition unavailable")
+	ErrPartialFailure        = errors.New("partial failure")
+)
+
+// StatusError is a Jira response that maps to one of the errors above, so
+// callers can use errors.Is no matter how the error was wrapped.
+type StatusError struct {
+	Method   string
+	URL      string
+	Status   int
+	Messages []string
+}
+
+func (e *StatusError) Error() string {
+	message := fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.Status, http.StatusText(e.Status))
+	if len(e.Messages) > 0 {
+		message += ": " + strings.Join(e.Messages, "; ")
+	}
+	return message
+}
+
+func (e *StatusError) Unwrap() error {
+	return statusErrors[e.Status]
+}
+
+var statusErrors = map[int]error{
+	http.StatusUnauthorized:    ErrAuth,
+	http.StatusForbidden:       ErrAuth,
+	http.StatusNotFound:        ErrNotFound,
+	http.StatusTooManyRequests: ErrRateLimited,
+}
+
+func errorMessages(body []byte) []string {
+	parsed := struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil
+	}
+	messages := parsed.ErrorMessages
+	for field, message := range parsed.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", field, message))
+	}
+	return messages
+}
+
+// ErrorTransport turns responses with a status in statusErrors into a
+// StatusError, which go-jira passes through wrapped.
+type ErrorTransport struct {
+	Base http.RoundTripper
+}
+
+func (t *ErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := statusErrors[res.StatusCode]; !ok {
+		return res, nil
+	}
+
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	return nil, &StatusError{
+		Method:   req.Method,
+		URL:      req.URL.Path,
+		Status:   res.StatusCode,
+		Messages: errorMessages(body),
+	}
+}
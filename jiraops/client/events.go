@@ -0,0 +1,79 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/jlewallen/jira-ops/jiraops/events"
+)
+
+var mutations = []struct {
+	method string
+	path   *regexp.Regexp
+	before string
+	after  string
+}{
+	{http.MethodPost, regexp.MustCompile(`/rest/api/2/issue/([^/]+)/transitions$`), events.BeforeTransition, events.AfterTransition},
+	{http.MethodPut, regexp.MustCompile(`/rest/api/2/issue/([^/]+)$`), events.BeforeUpdate, events.AfterUpdate},
+	{http.MethodPost, regexp.MustCompile(`/rest/api/2/issue/([^/]+)/comment$`), events.BeforeComment, events.AfterComment},
+	{http.MethodPut, regexp.MustCompile(`/rest/api/2/issue/([^/]+)/comment/[^/]+$`), events.BeforeComment, events.AfterComment},
+}
+
+// EventTransport fires events around requests that change issues, with the
+// request body as the event's data. A failing before- handler stops the
+// request, failing after- handlers are only logged.
+type EventTransport struct {
+	Base http.RoundTripper
+	Bus  *events.Bus
+}
+
+func (t *EventTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for _, m := range mutations {
+		if req.Method != m.method {
+			continue
+		}
+		match := m.path.FindStringSubmatch(req.URL.Path)
+		if match == nil {
+			continue
+		}
+		return t.fire(req, match[1], m.before, m.after)
+	}
+	return t.Base.RoundTrip(req)
+}
+
+func (t *EventTransport) fire(req *http.Request, key, before, after string) (*http.Response, error) {
+	data := make(map[string]interface{})
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		json.Unmarshal(body, &data)
+	}
+
+	ctx := req.Context()
+
+	if err := t.Bus.Fire(ctx, &events.Event{Name: before, Key: key, Data: data}); err != nil {
+		return nil, err
+	}
+
+	res, err := t.Base.RoundTrip(req)
+
+	e := &events.Event{Name: after, Key: key, Data: data}
+	if err != nil {
+		e.Error = err.Error()
+	} else {
+		e.Status = res.StatusCode
+	}
+	if hookErr := t.Bus.Fire(ctx, e); hookErr != nil {
+		log.Printf("[%s] %v", key, hookErr)
+	}
+
+	return res, err
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path"
+)
+
+const maximumCachedResponse = 4 * 1024 * 1024
+
+type CachedResponse struct {
+	URL          string      `json:"url"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"lastModified,omitempty"`
+	Header       http.Header `json:"header"`
+}
+
+type CachingTransport struct {
+	Base      http.RoundTripper
+	Directory string
+}
+
+func (t *CachingTransport) filename(req *http.Request) string {
+	hash := sha256.Sum256([]byte(req.URL.String()))
+	return path.Join(t.Directory, hex.EncodeToString(hash[:]))
+}
+
+func (t *CachingTransport) load(filename string) (*CachedResponse, []byte) {
+	data, err := ioutil.ReadFile(filename + ".json")
+	if err != nil {
+		return nil, nil
+	}
+
+	cached := &CachedResponse{}
+	if err := json.Unmarshal(data, cached); err != nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadFile(filename + ".body")
+	if err != nil {
+		return nil, nil
+	}
+
+	return cached, body
+}
+
+func (t *CachingTransport) save(filename string, cached *CachedResponse, body []byte) error {
+	if err := os.MkdirAll(t.Directory, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filename+".body", body, 0600); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename+".json", data, 0600)
+}
+
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.Base.RoundTrip(req)
+	}
+
+	filename := t.filename(req)
+	cached, body := t.load(filename)
+
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	res, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotModified && cached != nil {
+		res.Body.Close()
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         res.Proto,
+			ProtoMajor:    res.ProtoMajor,
+			ProtoMinor:    res.ProtoMinor,
+			Header:        cached.Header,
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	etag, modified := res.Header.Get("ETag"), res.Header.Get("Last-Modified")
+	if res.StatusCode != http.StatusOK || (etag == "" && modified == "") || res.ContentLength > maximumCachedResponse {
+		return res, nil
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(res.Body, maximumCachedResponse+1))
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) <= maximumCachedResponse {
+		entry := &CachedResponse{URL: req.URL.String(), ETag: etag, LastModified: modified, Header: res.Header}
+		if err := t.save(filename, entry, data); err != nil {
+			log.Printf("http cache: %v", err)
+		}
+	}
+
+	res.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	return res, nil
+}
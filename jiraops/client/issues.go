@@ -0,0 +1,22 @@
+package client
+
+import (
+	"context"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// Issues is the part of the go-jira issue service the tool relies on. It's
+// satisfied by jira.Client.Issue and by jiratest.Fake.
+type Issues interface {
+	SearchWithContext(ctx context.Context, jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error)
+	GetWithContext(ctx context.Context, key string, options *jira.GetQueryOptions) (*jira.Issue, *jira.Response, error)
+	UpdateIssueWithContext(ctx context.Context, key string, data map[string]interface{}) (*jira.Response, error)
+	AddCommentWithContext(ctx context.Context, key string, comment *jira.Comment) (*jira.Comment, *jira.Response, error)
+	UpdateCommentWithContext(ctx context.Context, key string, comment *jira.Comment) (*jira.Comment, *jira.Response, error)
+	DeleteCommentWithContext(ctx context.Context, key, commentID string) error
+	GetTransitionsWithContext(ctx context.Context, key string) ([]jira.Transition, *jira.Response, error)
+	DoTransitionWithContext(ctx context.Context, key, transitionID string) (*jira.Response, error)
+}
+
+var _ Issues = (*jira.IssueService)(nil)
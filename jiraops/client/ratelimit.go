@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const maximumRateLimitedAttempts = 5
+
+type TokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	paused time.Time
+	lock   sync.Mutex
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *TokenBucket) reserve() time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	if now.Before(b.paused) {
+		return b.paused.Sub(now)
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		delay := b.reserve()
+		if delay == 0 {
+			return nil
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *TokenBucket) Pause(until time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if until.After(b.paused) {
+		b.paused = until
+	}
+	b.tokens = 0
+}
+
+func RetryAfter(res *http.Response, fallback time.Duration) time.Duration {
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return fallback
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return fallback
+}
+
+type RateLimitedTransport struct {
+	Base   http.RoundTripper
+	Bucket *TokenBucket
+}
+
+func (t *RateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fallback := time.Second
+
+	for attempt := 1; ; attempt++ {
+		if t.Bucket != nil {
+			if err := t.Bucket.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := t.Base.RoundTrip(req)
+		if err != nil || res.StatusCode != http.StatusTooManyRequests || attempt == maximumRateLimitedAttempts {
+			return res, err
+		}
+
+		if req.Body != nil && req.GetBody == nil {
+			return res, nil
+		}
+
+		delay := RetryAfter(res, fallback)
+		fallback *= 2
+
+		log.Printf("rate limited by jira, retrying %s %s in %v", req.Method, req.URL.Path, delay)
+
+		res.Body.Close()
+
+		if t.Bucket != nil {
+			t.Bucket.Pause(time.Now().Add(delay))
+		} else {
+			select {
+			case <-time.After(delay):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+const DefaultRetryBase = 500 * time.Millisecond
+const DefaultRetryMaximum = 30 * time.Second
+
+type RetryPolicy struct {
+	Attempts int
+	Base     time.Duration
+	Maximum  time.Duration
+}
+
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Base << uint(attempt-1)
+	if delay > p.Maximum || delay <= 0 {
+		delay = p.Maximum
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func Idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+func transient(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+type RetryTransport struct {
+	Base   http.RoundTripper
+	Policy *RetryPolicy
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !Idempotent(req) || (req.Body != nil && req.GetBody == nil) {
+		return t.Base.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := t.Base.RoundTrip(req)
+		if attempt >= t.Policy.Attempts || !transient(res, err) || req.Context().Err() != nil {
+			return res, err
+		}
+
+		delay := t.Policy.delay(attempt)
+		if err != nil {
+			log.Printf("retrying %s %s in %v: %v", req.Method, req.URL.Path, delay, err)
+		} else {
+			log.Printf("retrying %s %s in %v: %s", req.Method, req.URL.Path, delay, res.Status)
+			res.Body.Close()
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var issueKeySegment = regexp.MustCompile(`^[A-Z][A-Z0-9]+-\d+$`)
+var numericSegment = regexp.MustCompile(`^\d+$`)
+
+func EndpointName(req *http.Request) string {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, s := range segments {
+		if issueKeySegment.MatchString(s) {
+			segments[i] = "{key}"
+		} else if numericSegment.MatchString(s) {
+			segments[i] = "{id}"
+		}
+	}
+	return req.Method + " " + strings.Join(segments, "/")
+}
+
+type EndpointTiming struct {
+	Name      string
+	Latencies []time.Duration
+	Failures  int
+}
+
+func (e *EndpointTiming) total() time.Duration {
+	total := time.Duration(0)
+	for _, l := range e.Latencies {
+		total += l
+	}
+	return total
+}
+
+func (e *EndpointTiming) percentile(p float64) time.Duration {
+	sorted := append([]time.Duration{}, e.Latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(p*float64(len(sorted)-1))]
+}
+
+type Timing struct {
+	started   time.Time
+	lock      sync.Mutex
+	endpoints map[string]*EndpointTiming
+}
+
+func NewTiming() *Timing {
+	return &Timing{
+		started:   time.Now(),
+		endpoints: make(map[string]*EndpointTiming),
+	}
+}
+
+func (t *Timing) record(name string, elapsed time.Duration, failed bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	e := t.endpoints[name]
+	if e == nil {
+		e = &EndpointTiming{Name: name}
+		t.endpoints[name] = e
+	}
+	e.Latencies = append(e.Latencies, elapsed)
+	if failed {
+		e.Failures++
+	}
+}
+
+func (t *Timing) Report(w io.Writer) {
+	if t == nil {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	endpoints := make([]*EndpointTiming, 0)
+	requests := 0
+	total := time.Duration(0)
+	for _, e := range t.endpoints {
+		endpoints = append(endpoints, e)
+		requests += len(e.Latencies)
+		total += e.total()
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].total() > endpoints[j].total() })
+
+	fmt.Fprintf(w, "\ntiming: %d requests, %v api time, %v elapsed\n", requests, total.Round(time.Millisecond), time.Since(t.started).Round(time.Millisecond))
+	if requests == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%6s %6s %10s %10s %10s %10s  %s\n", "COUNT", "FAILED", "TOTAL", "MEAN", "P95", "MAX", "ENDPOINT")
+	for _, e := range endpoints {
+		mean := e.total() / time.Duration(len(e.Latencies))
+		fmt.Fprintf(w, "%6d %6d %10v %10v %10v %10v  %s\n", len(e.Latencies), e.Failures,
+			e.total().Round(time.Millisecond), mean.Round(time.Millisecond),
+			e.percentile(0.95).Round(time.Millisecond), e.percentile(1).Round(time.Millisecond), e.Name)
+	}
+}
+
+type TimingTransport struct {
+	Base   http.RoundTripper
+	Timing *Timing
+}
+
+func (t *TimingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+	res, err := t.Base.RoundTrip(req)
+	t.Timing.record(EndpointName(req), time.Since(started), err != nil || res.StatusCode >= 400)
+	return res, err
+}
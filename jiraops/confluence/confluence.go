@@ -0,0 +1,228 @@
+// Package confluence publishes pages in Confluence's storage format,
+// creating them beneath a parent page or updating them in place.
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type Client struct {
+	URL      string
+	Username string
+	Password string
+	HTTP     *http.Client
+}
+
+type Space struct {
+	Key string `json:"key"`
+}
+
+type Version struct {
+	Number int `json:"number"`
+}
+
+type Storage struct {
+	Value          string `json:"value"`
+	Representation string `json:"representation"`
+}
+
+type Body struct {
+	Storage *Storage `json:"storage"`
+}
+
+type Ancestor struct {
+	ID string `json:"id"`
+}
+
+type Links struct {
+	Base  string `json:"base,omitempty"`
+	WebUI string `json:"webui,omitempty"`
+}
+
+type Page struct {
+	ID        string      `json:"id,omitempty"`
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Space     *Space      `json:"space,omitempty"`
+	Version   *Version    `json:"version,omitempty"`
+	Ancestors []*Ancestor `json:"ancestors,omitempty"`
+	Body      *Body       `json:"body,omitempty"`
+	Links     *Links      `json:"_links,omitempty"`
+}
+
+// WebURL is where the page can be viewed, when Confluence said.
+func (p *Page) WebURL(base string) string {
+	if p.Links == nil || p.Links.WebUI == "" {
+		return ""
+	}
+	if p.Links.Base != "" {
+		base = p.Links.Base
+	}
+	return strings.TrimSuffix(base, "/") + p.Links.WebUI
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, into interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.URL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+
+	req.SetBasicAuth(c.Username, c.Password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	r, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("confluence: %w", err)
+	}
+
+	defer r.Body.Close()
+
+	if r.StatusCode < 200 || r.StatusCode >= 300 {
+		data, _ := ioutil.ReadAll(r.Body)
+		return fmt.Errorf("confluence: %s %s: %s %s", method, path, r.Status, strings.TrimSpace(string(data)))
+	}
+
+	if into == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(into); err != nil {
+		return fmt.Errorf("confluence: %w", err)
+	}
+
+	return nil
+}
+
+// FindPage returns the page with title in space, or nil when there isn't
+// one.
+func (c *Client) FindPage(ctx context.Context, space, title string) (*Page, error) {
+	query := url.Values{}
+	query.Set("spaceKey", space)
+	query.Set("title", title)
+	query.Set("expand", "version,body.storage")
+
+	found := struct {
+		Results []*Page `json:"results"`
+	}{}
+	if err := c.do(ctx, "GET", "/rest/api/content?"+query.Encode(), nil, &found); err != nil {
+		return nil, err
+	}
+
+	if len(found.Results) == 0 {
+		return nil, nil
+	}
+
+	return found.Results[0], nil
+}
+
+func (c *Client) CreatePage(ctx context.Context, space, parentID, title, body string) (*Page, error) {
+	page := &Page{
+		Type:  "page",
+		Title: title,
+		Space: &Space{Key: space},
+		Body:  &Body{Storage: &Storage{Value: body, Representation: "storage"}},
+	}
+	if parentID != "" {
+		page.Ancestors = []*Ancestor{{ID: parentID}}
+	}
+
+	created := &Page{}
+	if err := c.do(ctx, "POST", "/rest/api/content", page, created); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (c *Client) UpdatePage(ctx context.Context, page *Page, body string) (*Page, error) {
+	number := 1
+	if page.Version != nil {
+		number = page.Version.Number + 1
+	}
+
+	update := &Page{
+		ID:      page.ID,
+		Type:    "page",
+		Title:   page.Title,
+		Version: &Version{Number: number},
+		Body:    &Body{Storage: &Storage{Value: body, Representation: "storage"}},
+	}
+
+	updated := &Page{}
+	if err := c.do(ctx, "PUT", "/rest/api/content/"+page.ID, update, updated); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// Publish creates the page titled title beneath parent in space, or updates
+// it when it already exists and its body has changed. The page is returned
+// along with whether anything was saved.
+func (c *Client) Publish(ctx context.Context, space, parent, title, body string) (*Page, bool, error) {
+	existing, err := c.FindPage(ctx, space, title)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if existing != nil {
+		if existing.Body != nil && existing.Body.Storage != nil && existing.Body.Storage.Value == body {
+			return existing, false, nil
+		}
+		page, err := c.UpdatePage(ctx, existing, body)
+		return page, err == nil, err
+	}
+
+	parentID := ""
+	if parent != "" {
+		p, err := c.FindPage(ctx, space, parent)
+		if err != nil {
+			return nil, false, err
+		}
+		if p == nil {
+			return nil, false, fmt.Errorf("confluence: no page '%s' in space %s", parent, space)
+		}
+		parentID = p.ID
+	}
+
+	page, err := c.CreatePage(ctx, space, parentID, title, body)
+	return page, err == nil, err
+}
+
+// ParseLocation splits SPACE/Parent Page into the space key and parent
+// title, the parent being optional.
+func ParseLocation(location string) (string, string, error) {
+	parts := strings.SplitN(location, "/", 2)
+	if parts[0] == "" {
+		return "", "", fmt.Errorf("confluence: expected SPACE/Parent Page, got '%s'", location)
+	}
+	if len(parts) == 1 {
+		return parts[0], "", nil
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,113 @@
+// Package events lets side effects be attached to what the tool changes,
+// either as Go handlers or as shell commands given the event as JSON.
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	BeforeTransition = "before-transition"
+	AfterTransition  = "after-transition"
+	BeforeUpdate     = "before-update"
+	AfterUpdate      = "after-update"
+	BeforeComment    = "before-comment"
+	AfterComment     = "after-comment"
+	AfterDownload    = "after-download"
+
+	// All matches every event.
+	All = "*"
+)
+
+type Event struct {
+	Name   string                 `json:"event"`
+	Key    string                 `json:"key,omitempty"`
+	Time   time.Time              `json:"time"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+	Status int                    `json:"status,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+}
+
+// Before reports whether handlers may veto the change by failing.
+func (e *Event) Before() bool {
+	return strings.HasPrefix(e.Name, "before-")
+}
+
+type Handler func(ctx context.Context, e *Event) error
+
+type Bus struct {
+	handlers map[string][]Handler
+	lock     sync.RWMutex
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+func (b *Bus) On(name string, h Handler) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.handlers[name] = append(b.handlers[name], h)
+}
+
+// Fire calls the handlers for e in the order they were added, stopping at
+// the first error. A nil Bus has no handlers.
+func (b *Bus) Fire(ctx context.Context, e *Event) error {
+	if b == nil {
+		return nil
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.lock.RLock()
+	handlers := append(append([]Handler{}, b.handlers[e.Name]...), b.handlers[All]...)
+	b.lock.RUnlock()
+
+	for _, h := range handlers {
+		if err := h(ctx, e); err != nil {
+			return fmt.Errorf("%s hook: %w", e.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Command runs command with sh, passing the event as JSON on stdin and its
+// name and key in JIRA_OPS_EVENT and JIRA_OPS_KEY. A non-zero exit from a
+// before- hook stops the change.
+func Command(command string, timeout time.Duration) Handler {
+	return func(ctx context.Context, e *Event) error {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(), "JIRA_OPS_EVENT="+e.Name, "JIRA_OPS_KEY="+e.Key)
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", command, err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,417 @@
+// Package jiratest provides an in-memory Jira for exercising upkeep rules,
+// mirroring and transitions without a live instance, either directly through
+// client.Issues or over HTTP with NewServer.
+package jiratest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+var keysQuery = regexp.MustCompile(`(?i)^\s*\(?\s*key\s+(?:IN\s*\(([^)]*)\)|=\s*'?([A-Z][A-Z0-9]+-\d+)'?)`)
+
+type Fake struct {
+	Issues      map[string]*jira.Issue
+	Projects    map[string]*jira.Project
+	Searches    map[string][]string
+	Transitions []jira.Transition
+	Errors      map[string]error
+	User        string
+	Calls       []string
+	Updates     []*Update
+	comments    int
+	lock        sync.Mutex
+}
+
+// Update is an edit made with UpdateIssueWithContext, after a round trip
+// through JSON so it reads the same whether it arrived directly or over HTTP.
+type Update struct {
+	Key  string
+	Data map[string]interface{}
+}
+
+func normalize(data map[string]interface{}) (map[string]interface{}, error) {
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	generic := make(map[string]interface{})
+	if err := json.Unmarshal(bytes, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// NewFake returns a fake holding issues. Searches are answered from
+// Searches by exact JQL, from key queries, or otherwise by filtering issues
+// with the subset of JQL described on filter. User is who currentUser() is.
+func NewFake(issues ...*jira.Issue) *Fake {
+	f := &Fake{
+		Issues:   make(map[string]*jira.Issue),
+		Projects: make(map[string]*jira.Project),
+		Searches: make(map[string][]string),
+		Errors:   make(map[string]error),
+	}
+	for _, issue := range issues {
+		f.Add(issue)
+	}
+	return f
+}
+
+func (f *Fake) Add(issue *jira.Issue) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if issue.ID == "" {
+		issue.ID = strconv.Itoa(10000 + len(f.Issues))
+	}
+	if issue.Fields == nil {
+		issue.Fields = &jira.IssueFields{}
+	}
+	f.Issues[issue.Key] = issue
+}
+
+func (f *Fake) Issue(key string) *jira.Issue {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.Issues[key]
+}
+
+func (f *Fake) project(key string) *jira.Project {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.Projects[key]
+}
+
+func (f *Fake) call(name, key string) error {
+	f.Calls = append(f.Calls, name+" "+key)
+	if err, ok := f.Errors[name+" "+key]; ok {
+		return err
+	}
+	return f.Errors[name]
+}
+
+func (f *Fake) find(key string) (*jira.Issue, error) {
+	for _, issue := range f.Issues {
+		if issue.Key == key || issue.ID == key {
+			return issue, nil
+		}
+	}
+	return nil, fmt.Errorf("issue does not exist: %s", key)
+}
+
+func (f *Fake) matching(jql string) []string {
+	if keys, ok := f.Searches[jql]; ok {
+		return keys
+	}
+
+	keys := make([]string, 0)
+	if m := keysQuery.FindStringSubmatch(jql); m != nil {
+		for _, key := range strings.Split(m[1]+","+m[2], ",") {
+			key = strings.Trim(strings.TrimSpace(key), `'"`)
+			if _, ok := f.Issues[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+
+	filter := parseFilter(jql)
+	for key, issue := range f.Issues {
+		if filter.matches(issue, f.User) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return lessKey(keys[i], keys[j])
+	})
+	return keys
+}
+
+func lessKey(a, b string) bool {
+	ap, an := splitKey(a)
+	bp, bn := splitKey(b)
+	if ap != bp {
+		return ap < bp
+	}
+	return an < bn
+}
+
+func splitKey(key string) (string, int) {
+	parts := strings.SplitN(key, "-", 2)
+	if len(parts) != 2 {
+		return key, 0
+	}
+	n, _ := strconv.Atoi(parts[1])
+	return parts[0], n
+}
+
+// clone round trips issue through JSON, so callers changing the issues
+// they're given don't change what the fake holds.
+func clone(issue *jira.Issue) (*jira.Issue, error) {
+	data, err := json.Marshal(issue)
+	if err != nil {
+		return nil, err
+	}
+	copied := &jira.Issue{}
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+func response(status int) *jira.Response {
+	return &jira.Response{Response: &http.Response{StatusCode: status, Status: http.StatusText(status)}}
+}
+
+func (f *Fake) SearchWithContext(ctx context.Context, jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.call("search", jql); err != nil {
+		return nil, response(http.StatusBadRequest), err
+	}
+
+	keys := f.matching(jql)
+
+	start, max := 0, 50
+	if options != nil {
+		start = options.StartAt
+		if options.MaxResults > 0 {
+			max = options.MaxResults
+		}
+	}
+
+	issues := make([]jira.Issue, 0)
+	for i := start; i < len(keys) && i < start+max; i++ {
+		if issue, ok := f.Issues[keys[i]]; ok {
+			copied, err := clone(issue)
+			if err != nil {
+				return nil, response(http.StatusInternalServerError), err
+			}
+			issues = append(issues, *copied)
+		}
+	}
+
+	res := response(http.StatusOK)
+	res.StartAt, res.MaxResults, res.Total = start, max, len(keys)
+
+	return issues, res, nil
+}
+
+func (f *Fake) GetWithContext(ctx context.Context, key string, options *jira.GetQueryOptions) (*jira.Issue, *jira.Response, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.call("get", key); err != nil {
+		return nil, response(http.StatusInternalServerError), err
+	}
+
+	issue, err := f.find(key)
+	if err != nil {
+		return nil, response(http.StatusNotFound), err
+	}
+
+	copied, err := clone(issue)
+	if err != nil {
+		return nil, response(http.StatusInternalServerError), err
+	}
+
+	return copied, response(http.StatusOK), nil
+}
+
+func (f *Fake) UpdateIssueWithContext(ctx context.Context, key string, data map[string]interface{}) (*jira.Response, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.call("update", key); err != nil {
+		return response(http.StatusInternalServerError), err
+	}
+
+	issue, err := f.find(key)
+	if err != nil {
+		return response(http.StatusNotFound), err
+	}
+
+	generic, err := normalize(data)
+	if err != nil {
+		return response(http.StatusBadRequest), err
+	}
+
+	f.Updates = append(f.Updates, &Update{Key: issue.Key, Data: generic})
+
+	if fields, ok := generic["fields"].(map[string]interface{}); ok {
+		if v, ok := fields["summary"].(string); ok {
+			issue.Fields.Summary = v
+		}
+		if v, ok := fields["description"].(string); ok {
+			issue.Fields.Description = v
+		}
+		if v, ok := fields["labels"].([]interface{}); ok {
+			issue.Fields.Labels = make([]string, 0)
+			for _, label := range v {
+				issue.Fields.Labels = append(issue.Fields.Labels, fmt.Sprintf("%v", label))
+			}
+		}
+	}
+
+	if update, ok := generic["update"].(map[string]interface{}); ok {
+		if ops, ok := update["labels"].([]interface{}); ok {
+			for _, op := range ops {
+				op, _ := op.(map[string]interface{})
+				if label, ok := op["add"].(string); ok {
+					issue.Fields.Labels = append(issue.Fields.Labels, label)
+				}
+				if label, ok := op["remove"].(string); ok {
+					kept := make([]string, 0)
+					for _, l := range issue.Fields.Labels {
+						if l != label {
+							kept = append(kept, l)
+						}
+					}
+					issue.Fields.Labels = kept
+				}
+			}
+		}
+	}
+
+	return response(http.StatusNoContent), nil
+}
+
+func (f *Fake) AddCommentWithContext(ctx context.Context, key string, comment *jira.Comment) (*jira.Comment, *jira.Response, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.call("comment", key); err != nil {
+		return nil, response(http.StatusInternalServerError), err
+	}
+
+	issue, err := f.find(key)
+	if err != nil {
+		return nil, response(http.StatusNotFound), err
+	}
+
+	f.comments++
+	added := *comment
+	added.ID = strconv.Itoa(f.comments)
+
+	if issue.Fields.Comments == nil {
+		issue.Fields.Comments = &jira.Comments{}
+	}
+	issue.Fields.Comments.Comments = append(issue.Fields.Comments.Comments, &added)
+
+	return &added, response(http.StatusCreated), nil
+}
+
+func (f *Fake) UpdateCommentWithContext(ctx context.Context, key string, comment *jira.Comment) (*jira.Comment, *jira.Response, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.call("update-comment", key); err != nil {
+		return nil, response(http.StatusInternalServerError), err
+	}
+
+	issue, err := f.find(key)
+	if err != nil {
+		return nil, response(http.StatusNotFound), err
+	}
+
+	if issue.Fields.Comments != nil {
+		for _, c := range issue.Fields.Comments.Comments {
+			if c.ID == comment.ID {
+				c.Body = comment.Body
+				updated := *c
+				return &updated, response(http.StatusOK), nil
+			}
+		}
+	}
+
+	return nil, response(http.StatusNotFound), fmt.Errorf("comment does not exist: %s", comment.ID)
+}
+
+func (f *Fake) DeleteCommentWithContext(ctx context.Context, key, commentID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.call("delete-comment", key); err != nil {
+		return err
+	}
+
+	issue, err := f.find(key)
+	if err != nil {
+		return err
+	}
+
+	if issue.Fields.Comments != nil {
+		for i, c := range issue.Fields.Comments.Comments {
+			if c.ID == commentID {
+				issue.Fields.Comments.Comments = append(issue.Fields.Comments.Comments[:i], issue.Fields.Comments.Comments[i+1:]...)
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("comment does not exist: %s", commentID)
+}
+
+func (f *Fake) GetTransitionsWithContext(ctx context.Context, key string) ([]jira.Transition, *jira.Response, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.call("transitions", key); err != nil {
+		return nil, response(http.StatusInternalServerError), err
+	}
+
+	if _, err := f.find(key); err != nil {
+		return nil, response(http.StatusNotFound), err
+	}
+
+	return f.Transitions, response(http.StatusOK), nil
+}
+
+func (f *Fake) DoTransitionWithContext(ctx context.Context, key, transitionID string) (*jira.Response, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.call("transition", key); err != nil {
+		return response(http.StatusInternalServerError), err
+	}
+
+	issue, err := f.find(key)
+	if err != nil {
+		return response(http.StatusNotFound), err
+	}
+
+	for _, t := range f.Transitions {
+		if t.ID == transitionID {
+			from := ""
+			if issue.Fields.Status != nil {
+				from = issue.Fields.Status.Name
+			}
+			to := t.To
+			issue.Fields.Status = &to
+			if issue.Changelog == nil {
+				issue.Changelog = &jira.Changelog{}
+			}
+			issue.Changelog.Histories = append(issue.Changelog.Histories, jira.ChangelogHistory{
+				Id:      strconv.Itoa(len(issue.Changelog.Histories) + 1),
+				Author:  jira.User{Name: f.User, AccountID: f.User},
+				Created: time.Now().Format("2006-01-02T15:04:05.000-0700"),
+				Items:   []jira.ChangelogItems{{Field: "status", FieldType: "jira", FromString: from, ToString: to.Name}},
+			})
+			return response(http.StatusNoContent), nil
+		}
+	}
+
+	return response(http.StatusBadRequest), fmt.Errorf("transition does not exist: %s", transitionID)
+}
@@ -0,0 +1,33 @@
+package jiratest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func TestGetReturnsCopy(t *testing.T) {
+	f := NewFake(&jira.Issue{
+		Key: "FK-1",
+		Fields: &jira.IssueFields{
+			Description: "original",
+			Labels:      []string{"one"},
+			Comments:    &jira.Comments{Comments: []*jira.Comment{{ID: "1", Body: "original"}}},
+		},
+	})
+
+	issue, _, err := f.GetWithContext(context.Background(), "FK-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue.Fields.Description = "changed"
+	issue.Fields.Labels[0] = "changed"
+	issue.Fields.Comments.Comments[0].Body = "changed"
+
+	held := f.Issue("FK-1")
+	if held.Fields.Description != "original" || held.Fields.Labels[0] != "one" || held.Fields.Comments.Comments[0].Body != "original" {
+		t.Errorf("fake changed through a returned issue: %+v", held.Fields)
+	}
+}
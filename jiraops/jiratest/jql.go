@@ -0,0 +1,187 @@
+package jiratest
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+var (
+	orderBy = regexp.MustCompile(`(?i)\s+ORDER\s+BY\s+.*$`)
+	and     = regexp.MustCompile(`(?i)\s+AND\s+`)
+	or      = regexp.MustCompile(`(?i)\s+OR\s+`)
+	clause  = regexp.MustCompile(`(?i)^(\w+)\s*(NOT\s+IN|IN|IS\s+NOT|IS|!=|=|~)\s*(.+)$`)
+)
+
+// filter understands enough JQL for the tool's own queries: clauses joined
+// by AND, each of which may be a parenthesized OR, comparing project, key,
+// status, resolution, assignee, issuetype, labels, component, duedate and
+// issueLinkType with =, !=,
+// IN, NOT IN, IS EMPTY and IS NOT EMPTY. Other fields never match within an
+// OR and are otherwise ignored.
+type filter struct {
+	groups [][][]string
+}
+
+func unwrap(part string) string {
+	part = strings.TrimSpace(part)
+	for strings.HasPrefix(part, "(") && strings.HasSuffix(part, ")") && balanced(part[1:len(part)-1]) {
+		part = strings.TrimSpace(part[1 : len(part)-1])
+	}
+	return part
+}
+
+func parseFilter(jql string) *filter {
+	f := &filter{}
+	jql = orderBy.ReplaceAllString(jql, "")
+	for _, part := range and.Split(jql, -1) {
+		group := make([][]string, 0)
+		for _, alternative := range or.Split(unwrap(part), -1) {
+			if m := clause.FindStringSubmatch(unwrap(alternative)); m != nil {
+				group = append(group, []string{strings.ToLower(m[1]), strings.ToUpper(strings.Join(strings.Fields(m[2]), " ")), m[3]})
+			} else {
+				group = append(group, nil)
+			}
+		}
+		f.groups = append(f.groups, group)
+	}
+	return f
+}
+
+func balanced(s string) bool {
+	depth := 0
+	for _, r := range s {
+		if r == '(' {
+			depth++
+		}
+		if r == ')' {
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+func values(s string) []string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "("), ")")
+	parsed := make([]string, 0)
+	for _, v := range strings.Split(s, ",") {
+		parsed = append(parsed, strings.ToLower(strings.Trim(strings.TrimSpace(v), `'"`)))
+	}
+	return parsed
+}
+
+func issueValues(issue *jira.Issue, field, user string) ([]string, bool) {
+	fields := issue.Fields
+	switch field {
+	case "project":
+		project := strings.SplitN(issue.Key, "-", 2)[0]
+		if fields.Project.Key != "" {
+			project = fields.Project.Key
+		}
+		return []string{project}, true
+	case "key", "issue", "issuekey":
+		return []string{issue.Key}, true
+	case "status":
+		if fields.Status == nil {
+			return nil, true
+		}
+		return []string{fields.Status.Name}, true
+	case "resolution":
+		if fields.Resolution == nil {
+			return nil, true
+		}
+		return []string{fields.Resolution.Name}, true
+	case "assignee":
+		if fields.Assignee == nil {
+			return nil, true
+		}
+		a := fields.Assignee
+		if a.Name == user || a.AccountID == user {
+			return []string{"currentuser()", a.Name, a.DisplayName}, true
+		}
+		return []string{a.Name, a.DisplayName}, true
+	case "issuetype", "type":
+		return []string{fields.Type.Name}, true
+	case "labels":
+		return fields.Labels, true
+	case "issuelinktype":
+		names := make([]string, 0)
+		for _, link := range fields.IssueLinks {
+			if link.InwardIssue != nil {
+				names = append(names, link.Type.Inward)
+			}
+			if link.OutwardIssue != nil {
+				names = append(names, link.Type.Outward)
+			}
+		}
+		return names, true
+	case "duedate":
+		if time.Time(fields.Duedate).IsZero() {
+			return nil, true
+		}
+		return []string{time.Time(fields.Duedate).Format("2006-01-02")}, true
+	case "component":
+		names := make([]string, 0)
+		for _, c := range fields.Components {
+			names = append(names, c.Name)
+		}
+		return names, true
+	}
+	return nil, false
+}
+
+// matched is whether issue satisfies c, and known whether c could be
+// evaluated at all.
+func matched(issue *jira.Issue, c []string, user string) (matched bool, known bool) {
+	if c == nil {
+		return false, false
+	}
+
+	have, known := issueValues(issue, c[0], user)
+	if !known {
+		return false, false
+	}
+
+	op, want := c[1], values(c[2])
+
+	if op == "IS" || op == "IS NOT" {
+		return (op == "IS") == (len(have) == 0), true
+	}
+
+	if op == "~" {
+		return true, true
+	}
+
+	found := false
+	for _, h := range have {
+		for _, w := range want {
+			if strings.EqualFold(h, w) {
+				found = true
+			}
+		}
+	}
+
+	negated := op == "!=" || op == "NOT IN"
+	return found != negated, true
+}
+
+func (f *filter) matches(issue *jira.Issue, user string) bool {
+	for _, group := range f.groups {
+		any, anyKnown := false, false
+		for _, c := range group {
+			m, known := matched(issue, c, user)
+			any = any || m
+			anyKnown = anyKnown || known
+		}
+		if anyKnown && !any {
+			return false
+		}
+	}
+	return true
+}
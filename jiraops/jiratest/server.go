@@ -0,0 +1,163 @@
+package jiratest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// NewServer serves the parts of the Jira REST API the tool calls from f, so
+// a real client from jira.NewClient(nil, server.URL) can be used against it.
+func NewServer(f *Fake) *httptest.Server {
+	return httptest.NewServer(&handler{fake: f})
+}
+
+type handler struct {
+	fake *Fake
+}
+
+func write(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if body != nil {
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+func fail(w http.ResponseWriter, res *jira.Response, err error) {
+	status := http.StatusInternalServerError
+	if res != nil && res.Response != nil {
+		status = res.StatusCode
+	}
+	write(w, status, map[string]interface{}{"errorMessages": []string{err.Error()}})
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	path := strings.Trim(r.URL.Path, "/")
+
+	if path == "rest/auth/1/session" {
+		write(w, http.StatusOK, map[string]interface{}{"session": map[string]string{"name": "JSESSIONID", "value": "jiratest"}})
+		return
+	}
+
+	if path == "rest/api/2/search" {
+		q := r.URL.Query()
+		options := &jira.SearchOptions{}
+		options.StartAt, _ = strconv.Atoi(q.Get("startAt"))
+		options.MaxResults, _ = strconv.Atoi(q.Get("maxResults"))
+		issues, res, err := h.fake.SearchWithContext(ctx, q.Get("jql"), options)
+		if err != nil {
+			fail(w, res, err)
+			return
+		}
+		write(w, http.StatusOK, map[string]interface{}{"issues": issues, "startAt": res.StartAt, "maxResults": res.MaxResults, "total": res.Total})
+		return
+	}
+
+	if strings.HasPrefix(path, "rest/api/2/project/") && r.Method == http.MethodGet {
+		if project := h.fake.project(strings.TrimPrefix(path, "rest/api/2/project/")); project != nil {
+			write(w, http.StatusOK, project)
+		} else {
+			write(w, http.StatusNotFound, nil)
+		}
+		return
+	}
+
+	if !strings.HasPrefix(path, "rest/api/2/issue/") {
+		write(w, http.StatusNotFound, nil)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path, "rest/api/2/issue/"), "/")
+	key := parts[0]
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		issue, res, err := h.fake.GetWithContext(ctx, key, nil)
+		if err != nil {
+			fail(w, res, err)
+			return
+		}
+		write(w, http.StatusOK, issue)
+
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		data := make(map[string]interface{})
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			write(w, http.StatusBadRequest, nil)
+			return
+		}
+		res, err := h.fake.UpdateIssueWithContext(ctx, key, data)
+		if err != nil {
+			fail(w, res, err)
+			return
+		}
+		write(w, http.StatusNoContent, nil)
+
+	case len(parts) == 2 && parts[1] == "comment" && r.Method == http.MethodPost:
+		comment := &jira.Comment{}
+		if err := json.NewDecoder(r.Body).Decode(comment); err != nil {
+			write(w, http.StatusBadRequest, nil)
+			return
+		}
+		added, res, err := h.fake.AddCommentWithContext(ctx, key, comment)
+		if err != nil {
+			fail(w, res, err)
+			return
+		}
+		write(w, http.StatusCreated, added)
+
+	case len(parts) == 3 && parts[1] == "comment" && r.Method == http.MethodPut:
+		comment := &jira.Comment{}
+		if err := json.NewDecoder(r.Body).Decode(comment); err != nil {
+			write(w, http.StatusBadRequest, nil)
+			return
+		}
+		comment.ID = parts[2]
+		updated, res, err := h.fake.UpdateCommentWithContext(ctx, key, comment)
+		if err != nil {
+			fail(w, res, err)
+			return
+		}
+		write(w, http.StatusOK, updated)
+
+	case len(parts) == 3 && parts[1] == "comment" && r.Method == http.MethodDelete:
+		if err := h.fake.DeleteCommentWithContext(ctx, key, parts[2]); err != nil {
+			write(w, http.StatusNotFound, map[string]interface{}{"errorMessages": []string{err.Error()}})
+			return
+		}
+		write(w, http.StatusNoContent, nil)
+
+	case len(parts) == 2 && parts[1] == "transitions" && r.Method == http.MethodGet:
+		transitions, res, err := h.fake.GetTransitionsWithContext(ctx, key)
+		if err != nil {
+			fail(w, res, err)
+			return
+		}
+		write(w, http.StatusOK, map[string]interface{}{"transitions": transitions})
+
+	case len(parts) == 2 && parts[1] == "transitions" && r.Method == http.MethodPost:
+		payload := struct {
+			Transition struct {
+				ID string `json:"id"`
+			} `json:"transition"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			write(w, http.StatusBadRequest, nil)
+			return
+		}
+		res, err := h.fake.DoTransitionWithContext(ctx, key, payload.Transition.ID)
+		if err != nil {
+			fail(w, res, err)
+			return
+		}
+		write(w, http.StatusNoContent, nil)
+
+	default:
+		write(w, http.StatusNotFound, nil)
+	}
+}
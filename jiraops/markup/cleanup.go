@@ -0,0 +1,108 @@
+package markup
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var bareURLRegexp = regexp.MustCompile(`(^|[\s(])(https?://[^\s\[\]|!{}<>()]+)`)
+var headingRegexp = regexp.MustCompile(`^h([1-6])\.\s*(.*)$`)
+
+func findCodeLines(lines []string) []bool {
+	code := make([]bool, len(lines))
+	inCode := false
+	for i, line := range lines {
+		toggles := strings.Count(line, "{code") + strings.Count(line, "{noformat")
+		code[i] = inCode || toggles > 0
+		if toggles%2 == 1 {
+			inCode = !inCode
+		}
+	}
+	return code
+}
+
+func mapLinesOutsideCode(body string, fn func(line string) string) string {
+	lines := strings.Split(body, "\n")
+	code := findCodeLines(lines)
+	for i, line := range lines {
+		if !code[i] {
+			lines[i] = fn(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func splitCarriageReturn(line string) (string, string) {
+	if strings.HasSuffix(line, "\r") {
+		return strings.TrimSuffix(line, "\r"), "\r"
+	}
+	return line, ""
+}
+
+func BareURLsLinks(body string) (string, error) {
+	return mapLinesOutsideCode(body, func(line string) string {
+		return bareURLRegexp.ReplaceAllString(line, "$1[$2]")
+	}), nil
+}
+
+func NormalizeHeadings(body string) (string, error) {
+	levels := make(map[int]bool)
+	mapLinesOutsideCode(body, func(line string) string {
+		text, _ := splitCarriageReturn(line)
+		if m := headingRegexp.FindStringSubmatch(text); m != nil {
+			level, _ := strconv.Atoi(m[1])
+			levels[level] = true
+		}
+		return line
+	})
+
+	used := make([]int, 0)
+	for level := range levels {
+		used = append(used, level)
+	}
+	sort.Ints(used)
+
+	normalized := make(map[int]int)
+	for i, level := range used {
+		normalized[level] = i + 1
+	}
+
+	return mapLinesOutsideCode(body, func(line string) string {
+		text, cr := splitCarriageReturn(line)
+		m := headingRegexp.FindStringSubmatch(text)
+		if m == nil {
+			return line
+		}
+		level, _ := strconv.Atoi(m[1])
+		return fmt.Sprintf("h%d. %s%s", normalized[level], m[2], cr)
+	}), nil
+}
+
+func StripTrailingWhitespace(body string) (string, error) {
+	return mapLinesOutsideCode(body, func(line string) string {
+		text, cr := splitCarriageReturn(line)
+		return strings.TrimRight(text, " \t") + cr
+	}), nil
+}
+
+func CollapseBlankLines(body string) (string, error) {
+	lines := strings.Split(body, "\n")
+	code := findCodeLines(lines)
+	collapsed := make([]string, 0, len(lines))
+	blank := false
+	for i, line := range lines {
+		if !code[i] && strings.TrimSpace(line) == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		collapsed = append(collapsed, line)
+	}
+	return strings.Join(collapsed, "\n"), nil
+}
@@ -0,0 +1,241 @@
+// Package markup converts between Markdown and Jira's wiki markup. The
+// conversions cover what issue trackers commonly produce, headings, lists,
+// quotes, code, links and emphasis, and leave anything else as it is. It
+// also holds the cleanups upkeep rules apply to descriptions and comments.
+package markup
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mdFenceRegexp    = regexp.MustCompile("^\\s*(```|~~~)\\s*([\\w+-]*)\\s*$")
+	mdHeadingRegexp  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdBulletRegexp   = regexp.MustCompile(`^(\s*)[-*+]\s+(\[[ xX]\]\s+)?(.*)$`)
+	mdNumberedRegexp = regexp.MustCompile(`^(\s*)\d+[.)]\s+(.*)$`)
+	mdQuoteRegexp    = regexp.MustCompile(`^>\s?(.*)$`)
+	mdRuleRegexp     = regexp.MustCompile(`^\s*([-*_]\s*){3,}$`)
+	mdImageRegexp    = regexp.MustCompile(`!\[[^\]]*\]\(([^)\s]+)[^)]*\)`)
+	mdLinkRegexp     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	mdBoldRegexp     = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	mdItalicRegexp   = regexp.MustCompile(`(^|[^\w*])\*(\S(?:[^*]*?\S)?)\*`)
+	mdStrikeRegexp   = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	mdAutoLinkRegexp = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+)
+
+// FromMarkdown converts a Markdown document to Jira wiki markup.
+func FromMarkdown(md string) string {
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	fence := ""
+	for _, line := range lines {
+		if m := mdFenceRegexp.FindStringSubmatch(line); m != nil && (fence == "" || fence == m[1]) {
+			if fence != "" {
+				fence = ""
+				out = append(out, "{code}")
+			} else if m[2] != "" {
+				fence = m[1]
+				out = append(out, "{code:"+m[2]+"}")
+			} else {
+				fence = m[1]
+				out = append(out, "{code}")
+			}
+			continue
+		}
+		if fence != "" {
+			out = append(out, line)
+			continue
+		}
+		out = append(out, markdownLine(line))
+	}
+	if fence != "" {
+		out = append(out, "{code}")
+	}
+	return strings.Join(out, "\n")
+}
+
+// listDepth treats every two columns of indentation as a level of nesting.
+func listDepth(indent string) int {
+	return len(strings.ReplaceAll(indent, "\t", "  "))/2 + 1
+}
+
+func markdownLine(line string) string {
+	if mdRuleRegexp.MatchString(line) && strings.TrimSpace(line) != "" {
+		return "----"
+	}
+	if m := mdHeadingRegexp.FindStringSubmatch(line); m != nil {
+		return "h" + string(rune('0'+len(m[1]))) + ". " + markdownInline(m[2])
+	}
+	if m := mdBulletRegexp.FindStringSubmatch(line); m != nil {
+		text := markdownInline(m[3])
+		if m[2] != "" {
+			if strings.Contains(m[2], " ]") {
+				text = "(x) " + text
+			} else {
+				text = "(/) " + text
+			}
+		}
+		return strings.Repeat("*", listDepth(m[1])) + " " + text
+	}
+	if m := mdNumberedRegexp.FindStringSubmatch(line); m != nil {
+		return strings.Repeat("#", listDepth(m[1])) + " " + markdownInline(m[2])
+	}
+	if m := mdQuoteRegexp.FindStringSubmatch(line); m != nil {
+		return "bq. " + markdownInline(m[1])
+	}
+	return markdownInline(line)
+}
+
+// markdownInline converts emphasis and links, leaving `code` spans alone
+// other than marking them as monospaced.
+func markdownInline(text string) string {
+	parts := strings.Split(text, "`")
+	for i := range parts {
+		if i%2 == 1 && i < len(parts)-1 {
+			parts[i] = "{{" + parts[i] + "}}"
+			continue
+		}
+		s := parts[i]
+		s = mdImageRegexp.ReplaceAllString(s, "!$1!")
+		s = mdLinkRegexp.ReplaceAllString(s, "[$1|$2]")
+		s = mdAutoLinkRegexp.ReplaceAllString(s, "[$1]")
+		s = mdBoldRegexp.ReplaceAllString(s, "\x00$2\x00")
+		s = mdItalicRegexp.ReplaceAllString(s, "${1}_${2}_")
+		s = strings.ReplaceAll(s, "\x00", "*")
+		s = mdStrikeRegexp.ReplaceAllString(s, "-$1-")
+		if i%2 == 1 {
+			// an unmatched backtick
+			s = "`" + s
+		}
+		parts[i] = s
+	}
+	return strings.Join(parts, "")
+}
+
+var (
+	wikiCodeRegexp     = regexp.MustCompile(`^\s*\{(code|noformat)(?::([^}|]*))?[^}]*\}\s*$`)
+	wikiHeadingRegexp  = regexp.MustCompile(`^h([1-6])\.\s*(.*)$`)
+	wikiListRegexp     = regexp.MustCompile(`^([*#-]+)\s+(.*)$`)
+	wikiQuoteRegexp    = regexp.MustCompile(`^bq\.\s*(.*)$`)
+	wikiRuleRegexp     = regexp.MustCompile(`^-{4,}\s*$`)
+	wikiImageRegexp    = regexp.MustCompile(`!([^!|\s][^!|]*?)(\|[^!]*)?!`)
+	wikiLinkRegexp     = regexp.MustCompile(`\[([^\]|]+)\|([^\]]+)\]`)
+	wikiBareLinkRegexp = regexp.MustCompile(`\[((?:https?|mailto):[^\]]+)\]`)
+	wikiMentionRegexp  = regexp.MustCompile(`\[~(?:accountid:)?([^\]]+)\]`)
+	wikiBoldRegexp     = regexp.MustCompile(`(^|[^\w*])\*(\S(?:[^*]*?\S)?)\*`)
+	wikiStrikeRegexp   = regexp.MustCompile(`(^|\s)-(\S(?:[^-]*?\S)?)-($|[\s.,;:!?])`)
+	wikiColorRegexp    = regexp.MustCompile(`\{color(?::[^}]*)?\}`)
+)
+
+// ToMarkdown converts Jira wiki markup to Markdown.
+func ToMarkdown(wiki string) string {
+	lines := strings.Split(strings.ReplaceAll(wiki, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	code := ""
+	quote := false
+	for _, line := range lines {
+		if m := wikiCodeRegexp.FindStringSubmatch(line); m != nil && (code == "" || code == m[1]) {
+			if code != "" {
+				code = ""
+				out = append(out, "```")
+			} else {
+				code = m[1]
+				out = append(out, "```"+strings.TrimSpace(m[2]))
+			}
+			continue
+		}
+		if code != "" {
+			out = append(out, line)
+			continue
+		}
+		if strings.TrimSpace(line) == "{quote}" {
+			quote = !quote
+			continue
+		}
+
+		converted, header := wikiLine(line)
+		if header {
+			cells := strings.Count(converted, " | ") + 1
+			out = append(out, converted, "|"+strings.Repeat(" --- |", cells))
+			continue
+		}
+		if quote {
+			converted = "> " + converted
+		}
+		out = append(out, converted)
+	}
+	if code != "" {
+		out = append(out, "```")
+	}
+	return strings.Join(out, "\n")
+}
+
+// wikiLine converts a line outside of code, returning whether it was a
+// table's header row.
+func wikiLine(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if wikiRuleRegexp.MatchString(trimmed) {
+		return "---", false
+	}
+	if m := wikiHeadingRegexp.FindStringSubmatch(trimmed); m != nil {
+		return strings.Repeat("#", int(m[1][0]-'0')) + " " + wikiInline(m[2]), false
+	}
+	if m := wikiQuoteRegexp.FindStringSubmatch(trimmed); m != nil {
+		return "> " + wikiInline(m[1]), false
+	}
+	if strings.HasPrefix(trimmed, "||") {
+		cells := strings.Split(strings.Trim(trimmed, "|"), "||")
+		return wikiRow(cells), true
+	}
+	if strings.HasPrefix(trimmed, "|") {
+		cells := strings.Split(strings.Trim(trimmed, "|"), "|")
+		return wikiRow(cells), false
+	}
+	if m := wikiListRegexp.FindStringSubmatch(trimmed); m != nil && !(m[1] == "-" && len(m[2]) > 0 && m[2][0] == '-') {
+		depth := len(m[1]) - 1
+		marker := "-"
+		if strings.HasSuffix(m[1], "#") {
+			marker = "1."
+		}
+		return strings.Repeat("  ", depth) + marker + " " + wikiInline(m[2]), false
+	}
+	return wikiInline(line), false
+}
+
+func wikiRow(cells []string) string {
+	for i, c := range cells {
+		cells[i] = wikiInline(strings.TrimSpace(c))
+	}
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+// wikiInline converts emphasis and links, leaving {{monospaced}} text alone
+// other than marking it as code.
+func wikiInline(text string) string {
+	out := ""
+	for {
+		start := strings.Index(text, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(text[start+2:], "}}")
+		if end < 0 {
+			break
+		}
+		out += wikiEmphasis(text[:start]) + "`" + text[start+2:start+2+end] + "`"
+		text = text[start+2+end+2:]
+	}
+	return out + wikiEmphasis(text)
+}
+
+func wikiEmphasis(s string) string {
+	s = wikiColorRegexp.ReplaceAllString(s, "")
+	s = wikiMentionRegexp.ReplaceAllString(s, "@$1")
+	s = wikiImageRegexp.ReplaceAllString(s, "![]($1)")
+	s = wikiLinkRegexp.ReplaceAllString(s, "[$1]($2)")
+	s = wikiBareLinkRegexp.ReplaceAllString(s, "<$1>")
+	s = wikiBoldRegexp.ReplaceAllString(s, "$1**$2**")
+	s = wikiStrikeRegexp.ReplaceAllString(s, "$1~~$2~~$3")
+	return s
+}
@@ -0,0 +1,44 @@
+package markup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ImagesRegexp matches embedded image markup, capturing the name and any
+// parameters.
+var ImagesRegexp = regexp.MustCompile(`(?i)!([^!\n|]+\.(?:png|jpe?g|gif|bmp|svg|webp))(\|[^!\n]*)?!`)
+
+func makeThumbnail(name, parameters string) string {
+	kept := make([]string, 0)
+	for _, p := range strings.Split(parameters, ",") {
+		p = strings.TrimSpace(p)
+		key := strings.ToLower(strings.SplitN(p, "=", 2)[0])
+		if p == "" || key == "thumbnail" || key == "width" || key == "height" {
+			continue
+		}
+		kept = append(kept, p)
+	}
+
+	kept = append([]string{"thumbnail"}, kept...)
+
+	return fmt.Sprintf("!%s|%s!", name, strings.Join(kept, ","))
+}
+
+func Thumbnails(body string) (string, error) {
+	newBody := ImagesRegexp.ReplaceAllStringFunc(body, func(match string) string {
+		m := ImagesRegexp.FindStringSubmatch(match)
+		name, parameters := m[1], strings.TrimPrefix(m[2], "|")
+
+		for _, p := range strings.Split(parameters, ",") {
+			if strings.TrimSpace(strings.ToLower(p)) == "thumbnail" {
+				return match
+			}
+		}
+
+		return makeThumbnail(name, parameters)
+	})
+
+	return newBody, nil
+}
@@ -0,0 +1,12 @@
+package markup
+
+type TransformFunc func(body string) (string, error)
+
+// Transforms are the structural cleanups available to upkeep rules by type.
+var Transforms = map[string]TransformFunc{
+	"thumbnails":          Thumbnails,
+	"links":               BareURLsLinks,
+	"headings":            NormalizeHeadings,
+	"trailing-whitespace": StripTrailingWhitespace,
+	"blank-lines":         CollapseBlankLines,
+}
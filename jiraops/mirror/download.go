@@ -0,0 +1,48 @@
+package mirror
+
+import (
+	"context"
+	"io"
+)
+
+type DownloadFunc func(ctx context.Context) (io.ReadCloser, error)
+
+// URL is something to mirror, saved as SaveAs. OriginalSaveAs is set when
+// an earlier mirror saved it under a different name.
+type URL struct {
+	Name           string
+	Source         string
+	SaveAs         string
+	OriginalSaveAs string
+	Download       DownloadFunc
+}
+
+// Download saves url to storage, removing the partial file on failure.
+func Download(ctx context.Context, url *URL, storage Storage, directory string) (size int64, err error) {
+	reader, err := url.Download(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if reader == nil {
+		return 0, nil
+	}
+
+	defer reader.Close()
+
+	file, err := storage.Create(directory, url.SaveAs)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			storage.Remove(directory, url.SaveAs)
+		}
+	}()
+
+	return io.Copy(file, reader)
+}
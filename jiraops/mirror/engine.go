@@ -0,0 +1,251 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+)
+
+// Issues is the part of the Jira issue service the engine needs, satisfied
+// by jira.Client.Issue.
+type Issues interface {
+	pages.Searcher
+	pages.Getter
+}
+
+// Hooks customize what the engine mirrors and react as files land. Every
+// hook is optional.
+type Hooks struct {
+	// Filter is called with each search result, holding SearchFields, and
+	// skips the issue when it returns false.
+	Filter func(issue *jira.Issue) bool
+	// URLs returns what to mirror for a fully fetched issue.
+	URLs func(issue *jira.Issue) []*URL
+	// BeforeDownload skips a url when it returns false.
+	BeforeDownload func(issue *jira.Issue, url *URL) bool
+	// AfterDownload is called with each newly saved file.
+	AfterDownload func(ctx context.Context, issue *jira.Issue, directory string, file *ManifestFile) error
+	// AfterIssue is called once an issue's files are saved, before it's
+	// marked mirrored. Changes to the manifest are saved.
+	AfterIssue func(ctx context.Context, issue *jira.Issue, directory string, manifest *Manifest) error
+}
+
+var DefaultSearchFields = []string{"summary", "updated"}
+
+type Engine struct {
+	Issues  Issues
+	Storage Storage
+	Hooks   Hooks
+
+	// SearchFields are requested with the search, for Hooks.Filter.
+	SearchFields []string
+	// Fetchers is the number of issues fetched concurrently.
+	Fetchers int
+	// All mirrors issues even when they haven't been updated since they
+	// were last mirrored.
+	All bool
+}
+
+type Result struct {
+	Mirrored  int
+	Unchanged int
+	Filtered  int
+}
+
+func (e *Engine) mirroredSince(issue *jira.Issue) bool {
+	directory, ok := e.Storage.Find(issue)
+	if !ok {
+		return false
+	}
+	manifest, err := e.Storage.LoadManifest(directory, issue.Key)
+	if err != nil || manifest.Mirrored == nil {
+		return false
+	}
+	return !time.Time(issue.Fields.Updated).After(*manifest.Mirrored)
+}
+
+func (e *Engine) rename(manifest *Manifest, directory string, url *URL) error {
+	if _, err := e.Storage.Stat(directory, url.OriginalSaveAs); err != nil {
+		return nil
+	}
+	if _, err := e.Storage.Stat(directory, url.SaveAs); err == nil {
+		return nil
+	}
+
+	log.Printf("[%s] renaming %s -> %s", manifest.Key, url.OriginalSaveAs, url.SaveAs)
+
+	if err := e.Storage.Rename(directory, url.OriginalSaveAs, url.SaveAs); err != nil {
+		return fmt.Errorf("renaming %s: %w", url.OriginalSaveAs, err)
+	}
+
+	if f := manifest.Find(url.OriginalSaveAs); f != nil {
+		f.SaveAs = url.SaveAs
+	}
+
+	return nil
+}
+
+// Run mirrors every issue matching jql. When ctx is cancelled the issue in
+// progress has its manifest saved, without being marked mirrored, and the
+// context's error is returned.
+func (e *Engine) Run(ctx context.Context, jql string) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// keep runs on the fetching goroutine, so what it skips is counted
+	// atomically and copied into the result as Run returns.
+	var filtered, unchanged int32
+
+	keep := func(issue *jira.Issue) bool {
+		if e.Hooks.Filter != nil && !e.Hooks.Filter(issue) {
+			atomic.AddInt32(&filtered, 1)
+			return false
+		}
+		if !e.All && e.mirroredSince(issue) {
+			atomic.AddInt32(&unchanged, 1)
+			return false
+		}
+		return true
+	}
+
+	result := &Result{}
+	counted := func() *Result {
+		result.Filtered = int(atomic.LoadInt32(&filtered))
+		result.Unchanged = int(atomic.LoadInt32(&unchanged))
+		return result
+	}
+
+	fields := e.SearchFields
+	if len(fields) == 0 {
+		fields = DefaultSearchFields
+	}
+
+	issues, wait := pages.Stream(ctx, e.Issues, jql, &jira.SearchOptions{Fields: fields}, 100)
+
+	for f := range pages.Fetch(ctx, e.Issues, issues, e.Fetchers, keep) {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if f.Err != nil {
+			return counted(), fmt.Errorf("error getting issue: %+v", f.Err)
+		}
+
+		if err := e.mirror(ctx, f.Issue); err != nil {
+			return counted(), err
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		result.Mirrored++
+	}
+
+	if err := ctx.Err(); err != nil {
+		return counted(), err
+	}
+
+	if err := wait(); err != nil {
+		return counted(), fmt.Errorf("error getting issues: %w", err)
+	}
+
+	return counted(), nil
+}
+
+func (e *Engine) mirror(ctx context.Context, issue *jira.Issue) error {
+	directory, err := e.Storage.Directory(issue)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[%s] dir=%v '%s'", issue.Key, directory, issue.Fields.Summary)
+
+	manifest, err := e.Storage.LoadManifest(directory, issue.Key)
+	if err != nil {
+		return fmt.Errorf("loading manifest: %w", err)
+	}
+
+	urls := make([]*URL, 0)
+	if e.Hooks.URLs != nil {
+		urls = e.Hooks.URLs(issue)
+	}
+
+	for _, url := range urls {
+		if url.OriginalSaveAs != "" {
+			if err := e.rename(manifest, directory, url); err != nil {
+				return err
+			}
+		}
+
+		fi, err := e.Storage.Stat(directory, url.SaveAs)
+		if err == nil && manifest.Find(url.SaveAs) == nil {
+			manifest.Add(&ManifestFile{
+				Name:       url.Name,
+				Source:     url.Source,
+				SaveAs:     url.SaveAs,
+				Size:       fi.Size(),
+				Downloaded: fi.ModTime(),
+			})
+		}
+		if !os.IsNotExist(err) {
+			continue
+		}
+
+		if e.Hooks.BeforeDownload != nil && !e.Hooks.BeforeDownload(issue, url) {
+			continue
+		}
+
+		log.Printf("[%s] downloading %s -> %s", issue.Key, url.Name, url.SaveAs)
+		size, err := Download(ctx, url, e.Storage, directory)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return err
+		}
+
+		file := &ManifestFile{
+			Name:       url.Name,
+			Source:     url.Source,
+			SaveAs:     url.SaveAs,
+			Size:       size,
+			Downloaded: time.Now(),
+		}
+		manifest.Add(file)
+
+		if e.Hooks.AfterDownload != nil {
+			if err := e.Hooks.AfterDownload(ctx, issue, directory, file); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := e.Storage.SaveManifest(directory, manifest); err != nil {
+		return fmt.Errorf("saving manifest: %w", err)
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	if e.Hooks.AfterIssue != nil {
+		if err := e.Hooks.AfterIssue(ctx, issue, directory, manifest); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	manifest.Mirrored = &now
+	if err := e.Storage.SaveManifest(directory, manifest); err != nil {
+		return fmt.Errorf("saving manifest: %w", err)
+	}
+
+	return nil
+}
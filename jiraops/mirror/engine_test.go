@@ -0,0 +1,152 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/jiratest"
+)
+
+type memoryFile struct {
+	name string
+	data []byte
+}
+
+func (f *memoryFile) Name() string       { return f.name }
+func (f *memoryFile) Size() int64        { return int64(len(f.data)) }
+func (f *memoryFile) Mode() os.FileMode  { return 0644 }
+func (f *memoryFile) ModTime() time.Time { return time.Time{} }
+func (f *memoryFile) IsDir() bool        { return false }
+func (f *memoryFile) Sys() interface{}   { return nil }
+
+type memoryWriter struct {
+	bytes.Buffer
+	close func([]byte)
+}
+
+func (w *memoryWriter) Close() error {
+	w.close(w.Bytes())
+	return nil
+}
+
+// memoryStorage keeps files and manifests in maps keyed by their path.
+type memoryStorage struct {
+	files     map[string][]byte
+	manifests map[string]*Manifest
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{files: make(map[string][]byte), manifests: make(map[string]*Manifest)}
+}
+
+func (s *memoryStorage) Find(issue *jira.Issue) (string, bool) {
+	_, ok := s.manifests[issue.Key]
+	return issue.Key, ok
+}
+
+func (s *memoryStorage) Directory(issue *jira.Issue) (string, error) {
+	return issue.Key, nil
+}
+
+func (s *memoryStorage) Stat(directory, name string) (os.FileInfo, error) {
+	data, ok := s.files[path.Join(directory, name)]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return &memoryFile{name: name, data: data}, nil
+}
+
+func (s *memoryStorage) Create(directory, name string) (io.WriteCloser, error) {
+	return &memoryWriter{close: func(data []byte) {
+		s.files[path.Join(directory, name)] = data
+	}}, nil
+}
+
+func (s *memoryStorage) Remove(directory, name string) error {
+	delete(s.files, path.Join(directory, name))
+	return nil
+}
+
+func (s *memoryStorage) Rename(directory, from, to string) error {
+	s.files[path.Join(directory, to)] = s.files[path.Join(directory, from)]
+	delete(s.files, path.Join(directory, from))
+	return nil
+}
+
+func (s *memoryStorage) LoadManifest(directory, key string) (*Manifest, error) {
+	if m, ok := s.manifests[directory]; ok {
+		copied := *m
+		copied.Files = append([]*ManifestFile{}, m.Files...)
+		return &copied, nil
+	}
+	return &Manifest{Key: key, Files: make([]*ManifestFile, 0)}, nil
+}
+
+func (s *memoryStorage) SaveManifest(directory string, manifest *Manifest) error {
+	s.manifests[directory] = manifest
+	return nil
+}
+
+func TestEngineRun(t *testing.T) {
+	fake := jiratest.NewFake(
+		&jira.Issue{Key: "FK-1", Fields: &jira.IssueFields{Summary: "One"}},
+		&jira.Issue{Key: "FK-2", Fields: &jira.IssueFields{Summary: "Two"}},
+	)
+	storage := newMemoryStorage()
+
+	downloaded := make([]string, 0)
+	engine := &Engine{
+		Issues:  fake,
+		Storage: storage,
+		Hooks: Hooks{
+			Filter: func(issue *jira.Issue) bool {
+				return issue.Key != "FK-2"
+			},
+			URLs: func(issue *jira.Issue) []*URL {
+				return []*URL{{Name: "logs", SaveAs: "logs.txt", Download: func(ctx context.Context) (io.ReadCloser, error) {
+					return ioutil.NopCloser(strings.NewReader("uploaded")), nil
+				}}}
+			},
+			AfterDownload: func(ctx context.Context, issue *jira.Issue, directory string, file *ManifestFile) error {
+				downloaded = append(downloaded, path.Join(directory, file.SaveAs))
+				return nil
+			},
+		},
+	}
+
+	result, err := engine.Run(context.Background(), "project = FK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Mirrored != 1 || result.Filtered != 1 || result.Unchanged != 0 {
+		t.Errorf("first run: %+v", result)
+	}
+	if len(downloaded) != 1 || downloaded[0] != "FK-1/logs.txt" {
+		t.Errorf("downloaded: %v", downloaded)
+	}
+	if data := string(storage.files["FK-1/logs.txt"]); data != "uploaded" {
+		t.Errorf("saved: %q", data)
+	}
+	if m := storage.manifests["FK-1"]; m == nil || m.Mirrored == nil || len(m.Files) != 1 {
+		t.Errorf("manifest: %+v", m)
+	}
+
+	result, err = engine.Run(context.Background(), "project = FK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Mirrored != 0 || result.Filtered != 1 || result.Unchanged != 1 {
+		t.Errorf("second run: %+v", result)
+	}
+	if len(downloaded) != 1 {
+		t.Errorf("downloaded again: %v", downloaded)
+	}
+}
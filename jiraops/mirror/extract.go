@@ -0,0 +1,100 @@
+package mirror
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const maximumExtractedFiles = 10000
+
+func extractionDirectory(archive string) string {
+	return strings.TrimSuffix(archive, filepath.Ext(archive))
+}
+
+func ExtractZip(archive string, maximumBytes int64) (*Extraction, error) {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", archive, err)
+	}
+
+	defer reader.Close()
+
+	if len(reader.File) > maximumExtractedFiles {
+		return nil, fmt.Errorf("%s has too many files (%d)", archive, len(reader.File))
+	}
+
+	destination, err := filepath.Abs(extractionDirectory(archive))
+	if err != nil {
+		return nil, err
+	}
+
+	extraction := &Extraction{
+		Directory: filepath.Base(destination),
+		Time:      time.Now(),
+	}
+
+	for _, f := range reader.File {
+		target := filepath.Join(destination, f.Name)
+		if !strings.HasPrefix(target, destination+string(os.PathSeparator)) {
+			return nil, fmt.Errorf("%s: illegal path in archive: %s", archive, f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !f.Mode().IsRegular() {
+			return nil, fmt.Errorf("%s: unsupported file in archive: %s", archive, f.Name)
+		}
+
+		remaining := maximumBytes - extraction.Bytes
+		written, err := extractZipFile(f, target, remaining)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", archive, err)
+		}
+
+		extraction.Bytes += written
+		extraction.Files += 1
+	}
+
+	return extraction, nil
+}
+
+func extractZipFile(f *zip.File, target string, remaining int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, err
+	}
+
+	reader, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer reader.Close()
+
+	file, err := os.Create(target)
+	if err != nil {
+		return 0, err
+	}
+
+	defer file.Close()
+
+	written, err := io.Copy(file, io.LimitReader(reader, remaining+1))
+	if err != nil {
+		return written, err
+	}
+
+	if written > remaining {
+		return written, fmt.Errorf("extraction size limit exceeded at %s", f.Name)
+	}
+
+	return written, nil
+}
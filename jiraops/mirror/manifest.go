@@ -0,0 +1,77 @@
+// Package mirror keeps local copies of issue attachments and linked files,
+// recording them in a per-issue manifest.
+package mirror
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+)
+
+const manifestName = "manifest.json"
+
+type Extraction struct {
+	Directory string    `json:"directory"`
+	Files     int       `json:"files"`
+	Bytes     int64     `json:"bytes"`
+	Time      time.Time `json:"time"`
+}
+
+type ManifestFile struct {
+	Name       string      `json:"name"`
+	Source     string      `json:"source,omitempty"`
+	SaveAs     string      `json:"saveAs"`
+	Size       int64       `json:"size"`
+	Downloaded time.Time   `json:"downloaded"`
+	Extraction *Extraction `json:"extraction,omitempty"`
+}
+
+type Manifest struct {
+	Key      string          `json:"key"`
+	Mirrored *time.Time      `json:"mirrored,omitempty"`
+	Files    []*ManifestFile `json:"files"`
+}
+
+func LoadManifest(directory, key string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(path.Join(directory, manifestName))
+	if os.IsNotExist(err) {
+		return &Manifest{Key: key, Files: make([]*ManifestFile, 0)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func (m *Manifest) Save(directory string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(directory, manifestName), data, 0644)
+}
+
+func (m *Manifest) Find(saveAs string) *ManifestFile {
+	for _, f := range m.Files {
+		if f.SaveAs == saveAs {
+			return f
+		}
+	}
+	return nil
+}
+
+func (m *Manifest) Add(file *ManifestFile) {
+	if existing := m.Find(file.SaveAs); existing != nil {
+		*existing = *file
+		return
+	}
+	m.Files = append(m.Files, file)
+}
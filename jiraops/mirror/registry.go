@@ -0,0 +1,185 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// Source is a kind of link found in issue text, mirrored by a provider.
+// URL and SaveAs are templates expanded with Pattern's submatches ($1,
+// ${name}) and default to the matched link and its base name.
+type Source struct {
+	Name     string
+	Provider string
+	Pattern  *regexp.Regexp
+	URL      string
+	SaveAs   string
+	Token    string
+	Headers  map[string]string
+}
+
+// Provider returns the function downloading url for source.
+type Provider func(source *Source, url string) DownloadFunc
+
+type Registry struct {
+	providers map[string]Provider
+	sources   []*Source
+	client    *http.Client
+}
+
+// NewRegistry returns a registry with the http, http-bearer and
+// s3-presigned providers, using client for requests.
+func NewRegistry(client *http.Client) *Registry {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	r := &Registry{providers: make(map[string]Provider), client: client}
+	r.Register("http", r.httpProvider)
+	r.Register("http-bearer", r.bearerProvider)
+	r.Register("s3-presigned", r.presignedProvider)
+	return r
+}
+
+func (r *Registry) Register(name string, p Provider) {
+	r.providers[name] = p
+}
+
+func (r *Registry) Provider(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Add makes links matching source's pattern mirrorable, in the order
+// sources were added.
+func (r *Registry) Add(source *Source) error {
+	if _, ok := r.providers[source.Provider]; !ok {
+		return fmt.Errorf("%s: unknown provider '%s'", source.Name, source.Provider)
+	}
+	if source.Pattern == nil {
+		return fmt.Errorf("%s: pattern required", source.Name)
+	}
+	r.sources = append(r.sources, source)
+	return nil
+}
+
+// Find returns what to mirror for every link in text matched by a source.
+// A link is only matched by the first source it matches.
+func (r *Registry) Find(text string) []*URL {
+	urls := make([]*URL, 0)
+	seen := make(map[string]bool)
+	for _, source := range r.sources {
+		for _, m := range source.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			link := text[m[0]:m[1]]
+			if seen[link] {
+				continue
+			}
+			seen[link] = true
+			urls = append(urls, r.URL(source, text, m))
+		}
+	}
+	return urls
+}
+
+// URL expands source's templates for the match at m in text.
+func (r *Registry) URL(source *Source, text string, m []int) *URL {
+	link := text[m[0]:m[1]]
+
+	target := link
+	if source.URL != "" {
+		target = string(source.Pattern.ExpandString(nil, source.URL, text, m))
+	}
+
+	saveAs := path.Base(strings.Split(link, "?")[0])
+	if source.SaveAs != "" {
+		saveAs = string(source.Pattern.ExpandString(nil, source.SaveAs, text, m))
+	}
+
+	return &URL{
+		Name:     link,
+		Source:   source.Name,
+		SaveAs:   saveAs,
+		Download: r.providers[source.Provider](source, target),
+	}
+}
+
+func (r *Registry) get(ctx context.Context, url string, headers map[string]string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("downloading %s: %s", redactQuery(url), res.Status)
+	}
+	return res.Body, nil
+}
+
+func redactQuery(url string) string {
+	return strings.Split(url, "?")[0]
+}
+
+func (r *Registry) httpProvider(source *Source, url string) DownloadFunc {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		return r.get(ctx, url, source.Headers)
+	}
+}
+
+func (r *Registry) bearerProvider(source *Source, url string) DownloadFunc {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		if source.Token == "" {
+			return nil, fmt.Errorf("%s: token required", source.Name)
+		}
+		headers := map[string]string{"Authorization": "Bearer " + source.Token}
+		for name, value := range source.Headers {
+			headers[name] = value
+		}
+		return r.get(ctx, url, headers)
+	}
+}
+
+// presignedProvider sends nothing but the url, extra headers would change
+// the request the signature covers.
+func (r *Registry) presignedProvider(source *Source, url string) DownloadFunc {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		return r.get(ctx, url, nil)
+	}
+}
+
+// AttachmentDownloader is satisfied by jira.Client.Issue.
+type AttachmentDownloader interface {
+	DownloadAttachmentWithContext(ctx context.Context, attachmentID string) (*jira.Response, error)
+}
+
+var attachmentID = regexp.MustCompile(`/attachment/(?:content/)?(\d+)`)
+
+// JiraAttachment is a provider downloading attachments through the Jira
+// API, given their content url or id.
+func JiraAttachment(issues AttachmentDownloader) Provider {
+	return func(source *Source, url string) DownloadFunc {
+		id := url
+		if m := attachmentID.FindStringSubmatch(url); m != nil {
+			id = m[1]
+		}
+		return func(ctx context.Context) (io.ReadCloser, error) {
+			res, err := issues.DownloadAttachmentWithContext(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("downloading: %w", err)
+			}
+			return res.Body, nil
+		}
+	}
+}
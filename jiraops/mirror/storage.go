@@ -0,0 +1,108 @@
+package mirror
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// Storage is where mirrored files and manifests are kept. Directories are
+// opaque to the engine and only passed back to the same Storage.
+type Storage interface {
+	// Find returns the directory already holding issue's files, if any.
+	Find(issue *jira.Issue) (string, bool)
+	// Directory returns the directory for issue's files, creating it.
+	Directory(issue *jira.Issue) (string, error)
+	Stat(directory, name string) (os.FileInfo, error)
+	Create(directory, name string) (io.WriteCloser, error)
+	Remove(directory, name string) error
+	Rename(directory, from, to string) error
+	LoadManifest(directory, key string) (*Manifest, error)
+	SaveManifest(directory string, manifest *Manifest) error
+}
+
+var spacesRegexp = regexp.MustCompile("[-_\\\\/]")
+var removeRegexp = regexp.MustCompile("[:\"?'+.`!()]")
+var normalizeRegexp = regexp.MustCompile("\\s+")
+
+// DirectoryName is the directory for a newly mirrored issue, its key and
+// summary.
+func DirectoryName(issue *jira.Issue) string {
+	value := strings.ToLower(fmt.Sprintf("%s_%s", issue.Key, strings.TrimSpace(issue.Fields.Summary)))
+	value = removeRegexp.ReplaceAllLiteralString(value, "")
+	value = spacesRegexp.ReplaceAllLiteralString(value, " ")
+	return normalizeRegexp.ReplaceAllLiteralString(value, "_")
+}
+
+// LocalStorage keeps each issue in a directory under Base, found by key so
+// renamed issues keep their original directory. Directories are full paths.
+type LocalStorage struct {
+	Base  string
+	files []os.FileInfo
+}
+
+func NewLocalStorage(base string) (*LocalStorage, error) {
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", base, err)
+	}
+
+	files, err := ioutil.ReadDir(base)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", base, err)
+	}
+
+	return &LocalStorage{Base: base, files: files}, nil
+}
+
+func (s *LocalStorage) Find(issue *jira.Issue) (string, bool) {
+	prefix := strings.ToLower(issue.Key)
+	named := spacesRegexp.ReplaceAllLiteralString(prefix, "_") + "_"
+	for _, fi := range s.files {
+		name := strings.ToLower(fi.Name())
+		if strings.HasPrefix(name, prefix) || strings.HasPrefix(name, named) {
+			return path.Join(s.Base, fi.Name()), true
+		}
+	}
+	return "", false
+}
+
+func (s *LocalStorage) Directory(issue *jira.Issue) (string, error) {
+	directory, ok := s.Find(issue)
+	if !ok {
+		directory = path.Join(s.Base, DirectoryName(issue))
+	}
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return "", err
+	}
+	return directory, nil
+}
+
+func (s *LocalStorage) Stat(directory, name string) (os.FileInfo, error) {
+	return os.Stat(path.Join(directory, name))
+}
+
+func (s *LocalStorage) Create(directory, name string) (io.WriteCloser, error) {
+	return os.Create(path.Join(directory, name))
+}
+
+func (s *LocalStorage) Remove(directory, name string) error {
+	return os.Remove(path.Join(directory, name))
+}
+
+func (s *LocalStorage) Rename(directory, from, to string) error {
+	return os.Rename(path.Join(directory, from), path.Join(directory, to))
+}
+
+func (s *LocalStorage) LoadManifest(directory, key string) (*Manifest, error) {
+	return LoadManifest(directory, key)
+}
+
+func (s *LocalStorage) SaveManifest(directory string, manifest *Manifest) error {
+	return manifest.Save(directory)
+}
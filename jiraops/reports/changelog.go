@@ -0,0 +1,139 @@
+// Package reports derives status history and durations from issue changelogs.
+package reports
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type StatusChange struct {
+	Time   time.Time
+	From   string
+	To     string
+	Author string
+}
+
+func StatusChanges(issue *jira.Issue) []*StatusChange {
+	changes := make([]*StatusChange, 0)
+	if issue.Changelog == nil {
+		return changes
+	}
+
+	for _, h := range issue.Changelog.Histories {
+		created, err := h.CreatedTime()
+		if err != nil {
+			continue
+		}
+		for _, item := range h.Items {
+			if item.Field != "status" {
+				continue
+			}
+			changes = append(changes, &StatusChange{
+				Time:   created,
+				From:   item.FromString,
+				To:     item.ToString,
+				Author: h.Author.AccountID,
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Time.Before(changes[j].Time)
+	})
+
+	return changes
+}
+
+func FirstEntered(issue *jira.Issue, status string) (time.Time, bool) {
+	for _, c := range StatusChanges(issue) {
+		if c.To == status {
+			return c.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func EnteredCurrentStatus(issue *jira.Issue) time.Time {
+	changes := StatusChanges(issue)
+	if len(changes) == 0 {
+		return time.Time(issue.Fields.Created)
+	}
+	return changes[len(changes)-1].Time
+}
+
+type StatusDuration struct {
+	Status   string
+	Duration time.Duration
+	Visits   int
+}
+
+func TimeInStatuses(issue *jira.Issue, now time.Time) []*StatusDuration {
+	durations := make([]*StatusDuration, 0)
+	byStatus := make(map[string]*StatusDuration)
+
+	add := func(status string, d time.Duration) {
+		sd, ok := byStatus[status]
+		if !ok {
+			sd = &StatusDuration{Status: status}
+			byStatus[status] = sd
+			durations = append(durations, sd)
+		}
+		sd.Duration += d
+		sd.Visits++
+	}
+
+	since := time.Time(issue.Fields.Created)
+	for _, c := range StatusChanges(issue) {
+		add(c.From, c.Time.Sub(since))
+		since = c.Time
+	}
+
+	if issue.Fields.Status != nil {
+		end := now
+		if resolved := time.Time(issue.Fields.Resolutiondate); issue.Fields.Resolution != nil && resolved.After(since) {
+			end = resolved
+		}
+		add(issue.Fields.Status.Name, end.Sub(since))
+	}
+
+	return durations
+}
+
+func Percentile(values []time.Duration, p float64) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	index := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
+
+func Average(values []time.Duration) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, v := range values {
+		total += v
+	}
+	return total / time.Duration(len(values))
+}
+
+func FormatDays(d time.Duration) string {
+	days := d.Hours() / 24
+	if days < 1 {
+		return fmt.Sprintf("%.0fh", d.Hours())
+	}
+	return fmt.Sprintf("%.1fd", days)
+}
@@ -0,0 +1,147 @@
+// Package schema defines the JSON written with --json, kept apart from the
+// structs used internally so those can change without breaking scripts.
+//
+// Every document carries schemaVersion and kind. Within a version fields and
+// kinds may be added, so readers should ignore what they don't recognize,
+// but nothing is removed, renamed or changes type and fields are present even
+// when empty. Anything else bumps Version, and readers should refuse a
+// version they weren't written against.
+package schema
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const Version = 1
+
+const (
+	KindIssues = "issues"
+	KindReport = "report"
+	KindMirror = "mirror"
+)
+
+// Document is the top level of everything written to stdout, only the field
+// named by Kind is set.
+type Document struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	Kind          string    `json:"kind"`
+	Generated     time.Time `json:"generated"`
+	Issues        []*Issue  `json:"issues,omitempty"`
+	Report        *Report   `json:"report,omitempty"`
+	Mirror        *Mirror   `json:"mirror,omitempty"`
+}
+
+// Error is written to stderr when a command fails, Kind is one of the
+// failure kinds and Code the process exit code.
+type Error struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Error         string `json:"error"`
+	Kind          string `json:"kind"`
+	Code          int    `json:"code"`
+}
+
+type Issue struct {
+	Key        string     `json:"key"`
+	URL        string     `json:"url"`
+	Type       string     `json:"type"`
+	Status     string     `json:"status"`
+	Priority   string     `json:"priority"`
+	Summary    string     `json:"summary"`
+	Assignee   string     `json:"assignee"`
+	Resolution string     `json:"resolution"`
+	Labels     []string   `json:"labels"`
+	Components []string   `json:"components"`
+	Created    *time.Time `json:"created"`
+	Updated    *time.Time `json:"updated"`
+}
+
+// Report rows hold one value per aggregation, in the order of Aggregations,
+// for each combination of the GroupBy fields.
+type Report struct {
+	Name         string       `json:"name"`
+	GroupBy      []string     `json:"groupBy"`
+	Aggregations []string     `json:"aggregations"`
+	Rows         []*ReportRow `json:"rows"`
+}
+
+type ReportRow struct {
+	Group  []string  `json:"group"`
+	Values []float64 `json:"values"`
+}
+
+type Mirror struct {
+	Mirrored  int              `json:"mirrored"`
+	Unchanged int              `json:"unchanged"`
+	Filtered  int              `json:"filtered"`
+	Issues    []*MirroredIssue `json:"issues"`
+}
+
+type MirroredIssue struct {
+	Key       string          `json:"key"`
+	Directory string          `json:"directory"`
+	Files     []*MirroredFile `json:"files"`
+}
+
+type MirroredFile struct {
+	Name       string    `json:"name"`
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	Downloaded time.Time `json:"downloaded"`
+	Extracted  string    `json:"extracted"`
+}
+
+func NewDocument(kind string) *Document {
+	return &Document{SchemaVersion: Version, Kind: kind, Generated: time.Now().UTC()}
+}
+
+func (d *Document) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// NewIssue converts whichever fields were fetched, the rest are left empty.
+func NewIssue(issue *jira.Issue, url string) *Issue {
+	i := &Issue{
+		Key:        issue.Key,
+		URL:        url,
+		Labels:     make([]string, 0),
+		Components: make([]string, 0),
+	}
+	f := issue.Fields
+	if f == nil {
+		return i
+	}
+	i.Summary = f.Summary
+	i.Type = f.Type.Name
+	if f.Status != nil {
+		i.Status = f.Status.Name
+	}
+	if f.Priority != nil {
+		i.Priority = f.Priority.Name
+	}
+	if f.Assignee != nil {
+		i.Assignee = f.Assignee.DisplayName
+	}
+	if f.Resolution != nil {
+		i.Resolution = f.Resolution.Name
+	}
+	i.Labels = append(i.Labels, f.Labels...)
+	for _, c := range f.Components {
+		i.Components = append(i.Components, c.Name)
+	}
+	i.Created = optionalTime(time.Time(f.Created))
+	i.Updated = optionalTime(time.Time(f.Updated))
+	return i
+}
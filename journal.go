@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+)
+
+type DeployRecord struct {
+	Run         string    `json:"run"`
+	Time        time.Time `json:"time"`
+	Target      string    `json:"target"`
+	Version     string    `json:"version,omitempty"`
+	Commit      string    `json:"commit,omitempty"`
+	Environment string    `json:"environment,omitempty"`
+	BuildURL    string    `json:"buildUrl,omitempty"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Issues      []string  `json:"issues"`
+	Rollback    bool      `json:"rollback,omitempty"`
+}
+
+func deployJournalPath() string {
+	return path.Join(stateDirectory(), "deploys.jsonl")
+}
+
+func appendDeployRecord(record *DeployRecord) error {
+	if err := os.MkdirAll(stateDirectory(), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(deployJournalPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening deploy journal: %w", err)
+	}
+
+	defer file.Close()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing deploy journal: %w", err)
+	}
+
+	return nil
+}
+
+func readDeployJournal() ([]*DeployRecord, error) {
+	file, err := os.Open(deployJournalPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	records := make([]*DeployRecord, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		record := &DeployRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, fmt.Errorf("parsing deploy journal: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
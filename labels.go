@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+)
+
+type Labeler struct {
+	Label    string
+	Patterns []*regexp.Regexp
+}
+
+func loadLabelers(config *Config) ([]*Labeler, error) {
+	labelers := make([]*Labeler, 0)
+	for _, lc := range config.Labels {
+		labeler := &Labeler{Label: lc.Label}
+		for _, p := range lc.Patterns {
+			re, err := regexp.Compile("(?i)" + p)
+			if err != nil {
+				return nil, fmt.Errorf("label %s: %w", lc.Label, err)
+			}
+			labeler.Patterns = append(labeler.Patterns, re)
+		}
+		labelers = append(labelers, labeler)
+	}
+	return labelers, nil
+}
+
+func (l *Labeler) matches(issue *jira.Issue) bool {
+	for _, re := range l.Patterns {
+		if re.MatchString(issue.Fields.Summary) || re.MatchString(issue.Fields.Description) {
+			return true
+		}
+	}
+	return false
+}
+
+func findMissingLabels(labelers []*Labeler, issue *jira.Issue) []string {
+	missing := make([]string, 0)
+	for _, l := range labelers {
+		if !hasLabel(issue, l.Label) && l.matches(issue) {
+			missing = append(missing, l.Label)
+		}
+	}
+	return missing
+}
+
+func autoLabel(issues client.Issues, labelers []*Labeler, issue *jira.Issue, options *Options, audit *AuditLog, out io.Writer) error {
+	missing := findMissingLabels(labelers, issue)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(out, "%-8s %v (labels)\n", issue.Key, issue.Fields.Summary)
+	fmt.Fprintf(out, "  labels: %s +%s\n", strings.Join(issue.Fields.Labels, " "), strings.Join(missing, " +"))
+
+	if options.DryRun {
+		return nil
+	}
+
+	for _, label := range missing {
+		if err := addLabel(options.ctx, issues, issue, label); err != nil {
+			return err
+		}
+	}
+
+	before := strings.Join(issue.Fields.Labels, " ")
+	after := strings.TrimSpace(before + " " + strings.Join(missing, " "))
+	if err := audit.record(issue.Key, "labels", []string{"labels"}, before, after); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const linkCacheLifetime = 24 * time.Hour
+const linkCheckInterval = 200 * time.Millisecond
+
+var urlRegexp = regexp.MustCompile(`https?://[^\s\[\]|!{}<>()"']+`)
+
+type LinkStatus struct {
+	Status  int       `json:"status"`
+	Error   string    `json:"error,omitempty"`
+	Checked time.Time `json:"checked"`
+}
+
+func (s *LinkStatus) dead() bool {
+	return s.Error != "" || s.Status >= 400
+}
+
+type LinkChecker struct {
+	client   *http.Client
+	cache    map[string]*LinkStatus
+	throttle *time.Ticker
+	dead     map[string][]string
+	lock     sync.Mutex
+}
+
+func linkCachePath() string {
+	return path.Join(stateDirectory(), "links.json")
+}
+
+func newLinkChecker() (*LinkChecker, error) {
+	cache := make(map[string]*LinkStatus)
+
+	data, err := ioutil.ReadFile(linkCachePath())
+	if err == nil {
+		if err := json.Unmarshal(data, &cache); err != nil {
+			return nil, fmt.Errorf("parsing %s: %v", linkCachePath(), err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return &LinkChecker{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		cache:    cache,
+		throttle: time.NewTicker(linkCheckInterval),
+		dead:     make(map[string][]string),
+	}, nil
+}
+
+func checkableURL(link string) string {
+	if m := diagnosticsURL.FindStringSubmatch(link); m != nil {
+		return fmt.Sprintf("https://code.conservify.org/diagnostics/archives/%s.zip?token=%s", m[1], url.QueryEscape(DiagnosticsToken))
+	}
+	return link
+}
+
+func (lc *LinkChecker) check(link string) *LinkStatus {
+	lc.lock.Lock()
+	cached, ok := lc.cache[link]
+	lc.lock.Unlock()
+
+	if ok && time.Since(cached.Checked) < linkCacheLifetime {
+		return cached
+	}
+
+	<-lc.throttle.C
+
+	status := &LinkStatus{Checked: time.Now()}
+
+	target := checkableURL(link)
+	r, err := lc.client.Head(target)
+	if err == nil && r.StatusCode == http.StatusMethodNotAllowed {
+		r.Body.Close()
+		r, err = lc.client.Get(target)
+	}
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		r.Body.Close()
+		status.Status = r.StatusCode
+	}
+
+	lc.lock.Lock()
+	lc.cache[link] = status
+	lc.lock.Unlock()
+
+	return status
+}
+
+func (lc *LinkChecker) checkIssue(issue *jira.Issue) {
+	bodies := []string{issue.Fields.Description}
+	if issue.Fields.Comments != nil {
+		for _, c := range issue.Fields.Comments.Comments {
+			bodies = append(bodies, c.Body)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, body := range bodies {
+		for _, link := range urlRegexp.FindAllString(body, -1) {
+			if seen[link] {
+				continue
+			}
+			seen[link] = true
+
+			if status := lc.check(link); status.dead() {
+				lc.lock.Lock()
+				lc.dead[issue.Key] = append(lc.dead[issue.Key], link)
+				lc.lock.Unlock()
+			}
+		}
+	}
+}
+
+func (lc *LinkChecker) report() {
+	keys := make([]string, 0)
+	for key := range lc.dead {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Printf("%-8s %d dead link(s)\n", key, len(lc.dead[key]))
+		for _, link := range lc.dead[key] {
+			status := lc.cache[link]
+			if status.Error != "" {
+				fmt.Printf("  %s (%s)\n", link, status.Error)
+			} else {
+				fmt.Printf("  %s (%d)\n", link, status.Status)
+			}
+		}
+	}
+}
+
+func (lc *LinkChecker) close() error {
+	lc.throttle.Stop()
+
+	if err := os.MkdirAll(stateDirectory(), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(lc.cache, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(linkCachePath(), data, 0644)
+}
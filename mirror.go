@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/events"
+	"github.com/jlewallen/jira-ops/jiraops/markup"
+	"github.com/jlewallen/jira-ops/jiraops/mirror"
+	"github.com/jlewallen/jira-ops/jiraops/schema"
+)
+
+var mirroring = regexp.MustCompile("(\\.txt$|\\.zip$|\\.bin$)")
+
+func shouldMirror(name string) bool {
+	return mirroring.MatchString(name)
+}
+
+var crashLog = regexp.MustCompile("crash.*\\.(txt|log)$")
+
+// diagnosticKind names the kind of diagnostics file, or is empty for files
+// that aren't worth a notification, like images and other attachments.
+func diagnosticKind(file *mirror.ManifestFile) string {
+	name := strings.ToLower(file.SaveAs)
+	switch {
+	case file.Source == "diagnostics":
+		return "diagnostics"
+	case crashLog.MatchString(name):
+		return "crash log"
+	case strings.HasSuffix(name, ".zip"):
+		return "archive"
+	}
+	return ""
+}
+
+func findInlineURLs(downloaders *mirror.Registry, issueKey string, text string) []*mirror.URL {
+	urls := downloaders.Find(text)
+	for _, u := range urls {
+		log.Printf("[%s] found link %s", issueKey, u.Name)
+	}
+	return urls
+}
+
+func makeUniqueName(name string, unique string) string {
+	ext := path.Ext(name)
+	noExt := strings.ReplaceAll(name, ext, "")
+	return fmt.Sprintf("%s_%s%s", noExt, unique, ext)
+}
+
+func findImageReferences(text string) []string {
+	names := make([]string, 0)
+	for _, m := range markup.ImagesRegexp.FindAllStringSubmatch(text, -1) {
+		names = append(names, strings.TrimSpace(m[1]))
+	}
+	return names
+}
+
+func findReferencedImages(issue *jira.Issue) map[string]bool {
+	referenced := make(map[string]bool)
+	for _, name := range findImageReferences(issue.Fields.Description) {
+		referenced[name] = true
+	}
+	if issue.Fields.Comments != nil {
+		for _, c := range issue.Fields.Comments.Comments {
+			for _, name := range findImageReferences(c.Body) {
+				referenced[name] = true
+			}
+		}
+	}
+	return referenced
+}
+
+func findExternalImageURLs(downloaders *mirror.Registry, issueKey string, referenced map[string]bool) []*mirror.URL {
+	urls := make([]*mirror.URL, 0)
+	http, _ := downloaders.Provider("http")
+	for name := range referenced {
+		if !strings.HasPrefix(name, "http://") && !strings.HasPrefix(name, "https://") {
+			continue
+		}
+		log.Printf("[%s] found external image %s", issueKey, name)
+		urls = append(urls, &mirror.URL{
+			Name:     name,
+			Source:   "image",
+			SaveAs:   makeUniqueName(path.Base(strings.Split(name, "?")[0]), hashText(name)),
+			Download: http(&mirror.Source{Name: "image", Provider: "http"}, name),
+		})
+	}
+	return urls
+}
+
+func findAllURLs(downloaders *mirror.Registry, names *AttachmentNames, issue *jira.Issue, options *Options) []*mirror.URL {
+	urls := findInlineURLs(downloaders, issue.Key, issue.Fields.Description)
+	if issue.Fields.Comments != nil {
+		for _, c := range issue.Fields.Comments.Comments {
+			urls = append(urls, findInlineURLs(downloaders, issue.Key, c.Body)...)
+		}
+	}
+	referenced := make(map[string]bool)
+	if options.MirrorImages {
+		referenced = findReferencedImages(issue)
+		urls = append(urls, findExternalImageURLs(downloaders, issue.Key, referenced)...)
+	}
+	attachment, _ := downloaders.Provider("jira-attachment")
+	for _, a := range issue.Fields.Attachments {
+		if shouldMirror(a.Filename) || referenced[a.Filename] {
+			log.Printf("[%s] attached: %+v (considering)", issue.Key, a.Filename)
+			name := a.Filename
+			saveAs := makeUniqueName(a.Filename, a.ID)
+			renamed := names.mirroredName(issue, a, saveAs)
+			if renamed == saveAs {
+				saveAs = ""
+			}
+			urls = append(urls, &mirror.URL{
+				Name:           name,
+				Source:         "attachment",
+				SaveAs:         renamed,
+				OriginalSaveAs: saveAs,
+				Download:       attachment(&mirror.Source{Name: "attachment", Provider: "jira-attachment"}, a.ID),
+			})
+		} else {
+			log.Printf("[%s] attached: %+v (ignoring)", issue.Key, a.Filename)
+		}
+	}
+	return urls
+}
+
+func makeMirrorSearch(state *State, options *Options) string {
+	search := `component IN ("Firmware", "Portal", "Backend", "Mobile App") AND resolution IS EMPTY`
+	if state.MirrorLastRun != nil && !options.MirrorAll {
+		search += fmt.Sprintf(" AND updated >= '%s'", state.MirrorLastRun.Add(-time.Minute).Format(jqlTimeLayout))
+	}
+	return search + " ORDER BY updated DESC"
+}
+
+// newMirrorEngine returns an engine with the configured downloaders and
+// notifications, recording what it mirrors in the returned summary.
+func newMirrorEngine(jc *jira.Client, config *Config, options *Options) (*mirror.Engine, *schema.Mirror, error) {
+	storage, err := mirror.NewLocalStorage("/home/jlewallen/downloads/jira")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notifier, err := newNotifier(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names, err := loadAttachmentNames(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	downloaders, err := newDownloaders(jc, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summary := &schema.Mirror{Issues: make([]*schema.MirroredIssue, 0)}
+
+	engine := &mirror.Engine{
+		Issues:   jc.Issue,
+		Storage:  storage,
+		Fetchers: options.Fetchers,
+		All:      options.MirrorAll,
+		Hooks: mirror.Hooks{
+			URLs: func(issue *jira.Issue) []*mirror.URL {
+				return findAllURLs(downloaders, names, issue, options)
+			},
+			AfterDownload: func(ctx context.Context, issue *jira.Issue, directory string, file *mirror.ManifestFile) error {
+				e := &events.Event{Name: events.AfterDownload, Key: issue.Key, Data: map[string]interface{}{
+					"name":      file.Name,
+					"path":      path.Join(directory, file.SaveAs),
+					"size":      file.Size,
+					"directory": directory,
+				}}
+				if err := options.events.Fire(ctx, e); err != nil {
+					log.Printf("[%s] %v", issue.Key, err)
+				}
+				if kind := diagnosticKind(file); kind != "" && notifier != nil {
+					message := fmt.Sprintf("%s '%s'\n%s", issue.Key, issue.Fields.Summary, file.SaveAs)
+					if err := notifier.Notify(ctx, "new "+kind, message); err != nil {
+						log.Printf("[%s] notify: %v", issue.Key, err)
+					}
+				}
+				return nil
+			},
+			AfterIssue: func(ctx context.Context, issue *jira.Issue, directory string, manifest *mirror.Manifest) error {
+				if options.Extract {
+					extractMirrored(issue, directory, manifest, options.ExtractLimit*1024*1024)
+				}
+				summary.Issues = append(summary.Issues, mirroredIssue(issue, directory, manifest))
+				if options.MirrorComment && len(manifest.Files) > 0 {
+					return postMirrorSummary(ctx, jc, issue, manifest, directory)
+				}
+				return nil
+			},
+		},
+	}
+
+	return engine, summary, nil
+}
+
+func mirrorIssues(jc *jira.Client, config *Config, options *Options) error {
+	state, err := loadState()
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+
+	search := makeMirrorSearch(state, options)
+
+	log.Printf("mirror: %s", search)
+
+	engine, summary, err := newMirrorEngine(jc, config, options)
+	if err != nil {
+		return err
+	}
+
+	result, err := engine.Run(options.ctx, search)
+	if err != nil {
+		if interrupted(err) {
+			log.Printf("mirror: interrupted after %d issues", result.Mirrored)
+		}
+		return err
+	}
+
+	log.Printf("mirror: %d issues with new activity, %d unchanged", result.Mirrored, result.Unchanged)
+
+	if options.JSON {
+		summary.Mirrored, summary.Unchanged, summary.Filtered = result.Mirrored, result.Unchanged, result.Filtered
+		doc := schema.NewDocument(schema.KindMirror)
+		doc.Mirror = summary
+		if err := doc.Write(os.Stdout); err != nil {
+			return err
+		}
+	}
+
+	state.MirrorLastRun = &started
+	if err := state.save(); err != nil {
+		return fmt.Errorf("saving state: %w", err)
+	}
+
+	return nil
+}
+
+func mirroredIssue(issue *jira.Issue, directory string, manifest *mirror.Manifest) *schema.MirroredIssue {
+	mi := &schema.MirroredIssue{Key: issue.Key, Directory: directory, Files: make([]*schema.MirroredFile, 0)}
+	for _, f := range manifest.Files {
+		file := &schema.MirroredFile{Name: f.Name, Path: path.Join(directory, f.SaveAs), Size: f.Size, Downloaded: f.Downloaded}
+		if f.Extraction != nil {
+			file.Extracted = path.Join(directory, f.Extraction.Directory)
+		}
+		mi.Files = append(mi.Files, file)
+	}
+	return mi
+}
+
+func extractMirrored(issue *jira.Issue, directory string, manifest *mirror.Manifest, limit int64) {
+	for _, f := range manifest.Files {
+		if f.Extraction != nil || !strings.HasSuffix(strings.ToLower(f.SaveAs), ".zip") {
+			continue
+		}
+
+		log.Printf("[%s] extracting %s", issue.Key, f.SaveAs)
+		extraction, err := mirror.ExtractZip(path.Join(directory, f.SaveAs), limit)
+		if err != nil {
+			log.Printf("[%s] extract: %v", issue.Key, err)
+			continue
+		}
+
+		f.Extraction = extraction
+	}
+}
+
+const mirrorSummaryMarker = "_jira-ops mirror summary_"
+
+func makeMirrorSummary(manifest *mirror.Manifest, directory string) string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	lines := []string{
+		mirrorSummaryMarker,
+		fmt.Sprintf("Archived to {{%s:%s}}:", hostname, directory),
+	}
+	for _, f := range manifest.Files {
+		line := fmt.Sprintf("* {{%s}} (%d bytes)", f.SaveAs, f.Size)
+		if f.Extraction != nil {
+			line += fmt.Sprintf(", extracted to {{%s/}}", f.Extraction.Directory)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func postMirrorSummary(ctx context.Context, jc *jira.Client, issue *jira.Issue, manifest *mirror.Manifest, directory string) error {
+	body := makeMirrorSummary(manifest, directory)
+
+	if issue.Fields.Comments != nil {
+		for _, c := range issue.Fields.Comments.Comments {
+			if strings.HasPrefix(c.Body, mirrorSummaryMarker) {
+				if c.Body == body {
+					return nil
+				}
+				log.Printf("[%s] updating mirror summary", issue.Key)
+				c.Body = body
+				if _, _, err := jc.Issue.UpdateCommentWithContext(ctx, issue.Key, c); err != nil {
+					return fmt.Errorf("error updating comment: %w", err)
+				}
+				return nil
+			}
+		}
+	}
+
+	log.Printf("[%s] adding mirror summary", issue.Key)
+	if _, _, err := jc.Issue.AddCommentWithContext(ctx, issue.Key, &jira.Comment{Body: body}); err != nil {
+		return fmt.Errorf("error adding comment: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+)
+
+const collapsedComment = "_(superseded by a newer automated comment)_"
+
+type NoiseFilter struct {
+	Name     string
+	Authors  []string
+	Patterns []*regexp.Regexp
+	Action   string
+}
+
+func loadNoiseFilters(config *Config) ([]*NoiseFilter, error) {
+	filters := make([]*NoiseFilter, 0)
+	for _, nc := range config.Noise {
+		// A filter without either would match every comment.
+		if len(nc.Authors) == 0 && len(nc.Patterns) == 0 {
+			return nil, fmt.Errorf("noise %s: needs authors or patterns", nc.Name)
+		}
+		filter := &NoiseFilter{Name: nc.Name, Authors: nc.Authors, Action: nc.Action}
+		if filter.Action == "" {
+			filter.Action = "collapse"
+		}
+		if filter.Action != "collapse" && filter.Action != "delete" {
+			return nil, fmt.Errorf("noise %s: unknown action '%s'", nc.Name, nc.Action)
+		}
+		for _, p := range nc.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("noise %s: %w", nc.Name, err)
+			}
+			filter.Patterns = append(filter.Patterns, re)
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+func (f *NoiseFilter) matches(c *jira.Comment) bool {
+	if c.Body == collapsedComment {
+		return false
+	}
+
+	if len(f.Authors) > 0 {
+		found := false
+		for _, a := range f.Authors {
+			if strings.EqualFold(a, c.Author.Name) || strings.EqualFold(a, c.Author.DisplayName) || strings.EqualFold(a, c.Author.EmailAddress) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Patterns) > 0 {
+		for _, re := range f.Patterns {
+			if re.MatchString(c.Body) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+func cleanupNoise(issues client.Issues, filters []*NoiseFilter, issue *jira.Issue, options *Options, audit *AuditLog, out io.Writer) error {
+	if issue.Fields.Comments == nil {
+		return nil
+	}
+
+	for _, f := range filters {
+		matching := make([]*jira.Comment, 0)
+		for _, c := range issue.Fields.Comments.Comments {
+			if f.matches(c) {
+				matching = append(matching, c)
+			}
+		}
+
+		if len(matching) < 2 {
+			continue
+		}
+
+		for _, c := range matching[:len(matching)-1] {
+			fmt.Fprintf(out, "%-8s %s comment-%s by %s (%s)\n", issue.Key, f.Action, c.ID, c.Author.DisplayName, f.Name)
+
+			if options.DryRun {
+				continue
+			}
+
+			before := c.Body
+			if f.Action == "delete" {
+				if err := issues.DeleteCommentWithContext(options.ctx, issue.Key, c.ID); err != nil {
+					return fmt.Errorf("error deleting comment: %w", err)
+				}
+				c.Body = ""
+			} else {
+				c.Body = collapsedComment
+				if _, _, err := issues.UpdateCommentWithContext(options.ctx, issue.Key, c); err != nil {
+					return fmt.Errorf("error updating: %w", err)
+				}
+			}
+
+			if err := audit.record(issue.Key, "comment-"+c.ID, []string{f.Name}, before, c.Body); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+type Notifier interface {
+	Notify(ctx context.Context, title, message string) error
+}
+
+// notifyClient keeps a slow webhook from holding up whatever is notifying.
+var notifyClient = &http.Client{Timeout: 30 * time.Second}
+
+type desktopNotifier struct {
+}
+
+func (n *desktopNotifier) Notify(ctx context.Context, title, message string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	} else {
+		cmd = exec.CommandContext(ctx, "notify-send", title, message)
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("desktop notification: %w", err)
+	}
+	return nil
+}
+
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// Posts with a bot token when one is given, otherwise to the webhook. The
+// channel is optional for webhooks, which default to their own channel.
+type slackNotifier struct {
+	webhook string
+	token   string
+	channel string
+}
+
+func (n *slackNotifier) Notify(ctx context.Context, title, message string) error {
+	payload := map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", title, message),
+	}
+	if n.channel != "" {
+		payload["channel"] = n.channel
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	url := n.webhook
+	if n.token != "" {
+		url = slackPostMessageURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	r, err := notifyClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack notification: %w", err)
+	}
+
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack notification: %s", r.Status)
+	}
+
+	if n.token != "" {
+		reply := struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&reply); err != nil {
+			return fmt.Errorf("slack notification: %w", err)
+		}
+		if !reply.OK {
+			return fmt.Errorf("slack notification: %s", reply.Error)
+		}
+	}
+
+	return nil
+}
+
+type emailNotifier struct {
+	smtp *SMTPConfig
+	to   []string
+	html bool
+}
+
+func (n *emailNotifier) Notify(ctx context.Context, title, message string) error {
+	contentType := "text/plain"
+	if n.html {
+		contentType = "text/html"
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", n.smtp.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", title))
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&body, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&body, "Content-Type: %s; charset=utf-8\r\n\r\n", contentType)
+	body.WriteString(strings.ReplaceAll(message, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if n.smtp.Username != "" {
+		auth = smtp.PlainAuth("", n.smtp.Username, n.smtp.Password, n.smtp.Host)
+	}
+
+	port := n.smtp.Port
+	if port == 0 {
+		port = 587
+	}
+
+	address := fmt.Sprintf("%s:%d", n.smtp.Host, port)
+	if err := smtp.SendMail(address, auth, n.smtp.From, n.to, body.Bytes()); err != nil {
+		return fmt.Errorf("email notification: %w", err)
+	}
+
+	return nil
+}
+
+// mailer sends to a list named in the mail configuration or to comma
+// separated addresses.
+func (c *Config) mailer(to string, html bool) (Notifier, error) {
+	if c.SMTP == nil {
+		return nil, fmt.Errorf("mail requires smtp configuration")
+	}
+
+	recipients, ok := c.Mail[to]
+	if !ok {
+		recipients = make([]string, 0)
+		for _, address := range strings.Split(to, ",") {
+			address = strings.TrimSpace(address)
+			if !strings.Contains(address, "@") {
+				return nil, fmt.Errorf("unknown mailing list: %s", address)
+			}
+			recipients = append(recipients, address)
+		}
+	}
+
+	return &emailNotifier{smtp: c.SMTP, to: recipients, html: html}, nil
+}
+
+func newNotifier(options *Options) (Notifier, error) {
+	switch options.Notify {
+	case "":
+		return nil, nil
+	case "desktop":
+		return &desktopNotifier{}, nil
+	case "slack":
+		if options.SlackWebhook == "" {
+			return nil, fmt.Errorf("slack notifications require --slack-webhook")
+		}
+		return &slackNotifier{webhook: options.SlackWebhook}, nil
+	case "teams":
+		if options.TeamsWebhook == "" {
+			return nil, fmt.Errorf("teams notifications require --teams-webhook")
+		}
+		return &teamsNotifier{webhook: options.TeamsWebhook}, nil
+	}
+	return nil, fmt.Errorf("unknown notifier: %s", options.Notify)
+}
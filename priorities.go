@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/reports"
+)
+
+type PriorityBucket struct {
+	Name   string
+	Order  int
+	Ages   []time.Duration
+	Issues []*jira.Issue
+}
+
+func reportPrioritiesCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report priorities", flag.ExitOnError)
+	jql := flags.String("jql", "", "restrict to issues matching this query")
+	urgent := flags.String("urgent", "Highest,High", "priorities whose old issues are highlighted")
+	days := flags.Int("days", 14, "highlight urgent issues older than this many days")
+	flags.Parse(args)
+
+	buckets := make(map[string]*PriorityBucket)
+	now := time.Now()
+
+	searchOptions := &jira.SearchOptions{MaxResults: 100, Fields: []string{"priority", "created", "summary", "assignee", "status"}}
+	err := searchIssues(jc, options, reportSearch(options, "(resolution IS EMPTY)", *jql), searchOptions, func(issue jira.Issue) error {
+		name, order := "(none)", 1<<30
+		if issue.Fields.Priority != nil {
+			name = issue.Fields.Priority.Name
+			if id, err := strconv.Atoi(issue.Fields.Priority.ID); err == nil {
+				order = id
+			}
+		}
+		if buckets[name] == nil {
+			buckets[name] = &PriorityBucket{Name: name, Order: order}
+		}
+		b := buckets[name]
+		b.Ages = append(b.Ages, now.Sub(time.Time(issue.Fields.Created)))
+		i := issue
+		b.Issues = append(b.Issues, &i)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	sorted := make([]*PriorityBucket, 0, len(buckets))
+	total := 0
+	for _, b := range buckets {
+		sorted = append(sorted, b)
+		total += len(b.Issues)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+
+	fmt.Printf("%-12s %6s %6s %8s %8s %8s\n", "PRIORITY", "N", "%", "p50", "p85", "MAX")
+	for _, b := range sorted {
+		fmt.Printf("%-12s %6d %5.0f%% %8s %8s %8s\n", b.Name, len(b.Issues), 100*float64(len(b.Issues))/float64(total),
+			reports.FormatDays(reports.Percentile(b.Ages, 50)), reports.FormatDays(reports.Percentile(b.Ages, 85)), reports.FormatDays(reports.Percentile(b.Ages, 100)))
+	}
+
+	threshold := time.Duration(*days) * 24 * time.Hour
+	highlighted := false
+	for _, name := range strings.Split(*urgent, ",") {
+		b, ok := buckets[strings.TrimSpace(name)]
+		if !ok {
+			continue
+		}
+		for _, i := range b.Issues {
+			age := now.Sub(time.Time(i.Fields.Created))
+			if age < threshold {
+				continue
+			}
+			if !highlighted {
+				fmt.Printf("\n%s issues older than %d days:\n", *urgent, *days)
+				highlighted = true
+			}
+			fmt.Printf("%-8s %-8s %7s %-20s %s\n", i.Key, b.Name, reports.FormatDays(age), assigneeName(i), i.Fields.Summary)
+		}
+	}
+
+	return nil
+}
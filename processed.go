@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+var processedFields = []string{"summary", "description", "comment", "labels", "attachment"}
+
+type ProcessedIssue struct {
+	Hashes    map[string]string `json:"hashes"`
+	Processed time.Time         `json:"processed"`
+}
+
+type ProcessedState struct {
+	Configuration string                     `json:"configuration"`
+	Issues        map[string]*ProcessedIssue `json:"issues"`
+	lock          sync.Mutex
+}
+
+func processedStatePath() string {
+	return path.Join(stateDirectory(), "upkeep-processed.json")
+}
+
+func hashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+func configurationHash(config *Config) (string, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return hashText(string(data)), nil
+}
+
+func loadProcessedState(config *Config) (*ProcessedState, error) {
+	configuration, err := configurationHash(config)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &ProcessedState{}
+
+	data, err := ioutil.ReadFile(processedStatePath())
+	if err == nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("parsing %s: %v", processedStatePath(), err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if state.Configuration != configuration || state.Issues == nil {
+		state.Configuration = configuration
+		state.Issues = make(map[string]*ProcessedIssue)
+	}
+
+	return state, nil
+}
+
+func issueHashes(issue *jira.Issue) map[string]string {
+	attachments := make([]string, 0)
+	for _, a := range issue.Fields.Attachments {
+		attachments = append(attachments, a.ID+"/"+a.Filename)
+	}
+
+	hashes := map[string]string{
+		"fields":      hashText(issue.Fields.Summary + "\x00" + strings.Join(issue.Fields.Labels, ",") + "\x00" + strings.Join(attachments, ",")),
+		"description": hashText(issue.Fields.Description),
+	}
+	if issue.Fields.Comments != nil {
+		for _, c := range issue.Fields.Comments.Comments {
+			hashes["comment-"+c.ID] = hashText(c.Body)
+		}
+	}
+	return hashes
+}
+
+func (s *ProcessedState) unchanged(issue *jira.Issue) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	processed, ok := s.Issues[issue.Key]
+	if !ok {
+		return false
+	}
+
+	hashes := issueHashes(issue)
+	if len(hashes) != len(processed.Hashes) {
+		return false
+	}
+	for key, hash := range hashes {
+		if processed.Hashes[key] != hash {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *ProcessedState) record(issue *jira.Issue) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.Issues[issue.Key] = &ProcessedIssue{Hashes: issueHashes(issue), Processed: time.Now()}
+}
+
+func (s *ProcessedState) save() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := os.MkdirAll(stateDirectory(), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(processedStatePath(), data, 0644)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const githubIcon = "https://github.com/favicon.ico"
+const gitlabIcon = "https://gitlab.com/favicon.ico"
+
+type PullRequest struct {
+	URL    string
+	Title  string
+	State  string
+	Number int
+}
+
+func (pr *PullRequest) resolved() bool {
+	state := strings.ToLower(pr.State)
+	return state == "merged" || state == "closed"
+}
+
+func (pr *PullRequest) icon() string {
+	if strings.Contains(pr.URL, "gitlab") {
+		return gitlabIcon
+	}
+	return githubIcon
+}
+
+func detectPullRequest() (*PullRequest, error) {
+	if data, err := exec.Command("gh", "pr", "view", "--json", "url,title,state,number").Output(); err == nil {
+		pr := &PullRequest{}
+		if err := json.Unmarshal(data, pr); err != nil {
+			return nil, fmt.Errorf("parsing gh output: %w", err)
+		}
+		return pr, nil
+	}
+
+	if data, err := exec.Command("glab", "mr", "view", "--output", "json").Output(); err == nil {
+		mr := struct {
+			WebURL string `json:"web_url"`
+			Title  string `json:"title"`
+			State  string `json:"state"`
+			IID    int    `json:"iid"`
+		}{}
+		if err := json.Unmarshal(data, &mr); err != nil {
+			return nil, fmt.Errorf("parsing glab output: %w", err)
+		}
+		return &PullRequest{URL: mr.WebURL, Title: mr.Title, State: mr.State, Number: mr.IID}, nil
+	}
+
+	return nil, fmt.Errorf("no pull request url given and none found with gh or glab")
+}
+
+func linkPullRequest(ctx context.Context, jc *jira.Client, key string, pr *PullRequest) error {
+	title := pr.Title
+	if title == "" {
+		title = pr.URL
+	}
+
+	link := &jira.RemoteLink{
+		GlobalID: pr.URL,
+		Application: &jira.RemoteLinkApplication{
+			Type: "jira-ops.pull-request",
+			Name: "Pull Request",
+		},
+		Relationship: "pull request",
+		Object: &jira.RemoteLinkObject{
+			URL:   pr.URL,
+			Title: title,
+			Icon: &jira.RemoteLinkIcon{
+				Url16x16: pr.icon(),
+				Title:    "Pull Request",
+			},
+			Status: &jira.RemoteLinkStatus{
+				Resolved: pr.resolved(),
+			},
+		},
+	}
+
+	if pr.State != "" {
+		link.Object.Summary = strings.ToLower(pr.State)
+	}
+
+	log.Printf("[%s] linking %s", key, pr.URL)
+
+	if _, _, err := jc.Issue.AddRemoteLinkWithContext(ctx, key, link); err != nil {
+		return fmt.Errorf("error adding remote link: %w", err)
+	}
+
+	return nil
+}
+
+func prLinkCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("pr link", flag.ExitOnError)
+	title := flags.String("title", "", "title of the link")
+	positional := parseArgs(flags, args)
+
+	key, rest, err := resolveIssueKey(options, positional)
+	if err != nil {
+		return err
+	}
+
+	var pr *PullRequest
+	if len(rest) > 0 {
+		pr = &PullRequest{URL: rest[0]}
+	} else {
+		pr, err = detectPullRequest()
+		if err != nil {
+			return err
+		}
+	}
+
+	if *title != "" {
+		pr.Title = *title
+	}
+
+	return linkPullRequest(options.ctx, jc, key, pr)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/reports"
+)
+
+var qaBuckets = []time.Duration{24 * time.Hour, 3 * 24 * time.Hour, 7 * 24 * time.Hour, 14 * 24 * time.Hour}
+
+func lastDeployRuns(records []*DeployRecord) map[string]*DeployRecord {
+	runs := make(map[string]*DeployRecord)
+	for _, r := range records {
+		if r.Rollback {
+			continue
+		}
+		for _, key := range r.Issues {
+			if existing, ok := runs[key]; !ok || r.Time.After(existing.Time) {
+				runs[key] = r
+			}
+		}
+	}
+	return runs
+}
+
+func bucketName(i int) string {
+	if i == 0 {
+		return fmt.Sprintf("< %s", reports.FormatDays(qaBuckets[0]))
+	}
+	if i == len(qaBuckets) {
+		return fmt.Sprintf(">= %s", reports.FormatDays(qaBuckets[i-1]))
+	}
+	return fmt.Sprintf("%s - %s", reports.FormatDays(qaBuckets[i-1]), reports.FormatDays(qaBuckets[i]))
+}
+
+func reportQACommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report qa", flag.ExitOnError)
+	status := flags.String("status", "Awaiting QA", "status of the qa queue")
+	post := flags.Bool("post", false, "post issues new to the queue since the last post to the configured slack qa channel")
+	flags.Parse(args)
+
+	records, err := readDeployJournal()
+	if err != nil {
+		return err
+	}
+
+	runs := lastDeployRuns(records)
+
+	queue := make([]*AgingIssue, 0)
+	search := reportSearch(options, fmt.Sprintf("(status = '%s')", *status), "")
+	err = searchIssues(jc, options, search, &jira.SearchOptions{Expand: "changelog", MaxResults: 100, Fields: withFields(listingFields, "created")}, func(issue jira.Issue) error {
+		queue = append(queue, &AgingIssue{Issue: listed(&issue), Entered: reports.EnteredCurrentStatus(&issue)})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	sort.Slice(queue, func(i, j int) bool {
+		return queue[i].Entered.Before(queue[j].Entered)
+	})
+
+	now := time.Now()
+	ages := make([]time.Duration, 0, len(queue))
+	buckets := make([]int, len(qaBuckets)+1)
+
+	for _, q := range queue {
+		age := now.Sub(q.Entered)
+		ages = append(ages, age)
+
+		bucket := sort.Search(len(qaBuckets), func(i int) bool {
+			return age < qaBuckets[i]
+		})
+		buckets[bucket]++
+
+		from := "-"
+		if r, ok := runs[q.Issue.Key]; ok {
+			from = fmt.Sprintf("%s %s %s", r.Target, valueOr(r.Version, "-"), r.Time.Local().Format("2006/01/02"))
+		}
+
+		fmt.Printf("%-8s %7s %-28s %s\n", q.Issue.Key, reports.FormatDays(age), from, q.Issue.Fields.Summary)
+	}
+
+	fmt.Printf("\n%d issues in %s, p50 %s, p85 %s\n\n", len(queue), *status, reports.FormatDays(reports.Percentile(ages, 50)), reports.FormatDays(reports.Percentile(ages, 85)))
+
+	for i, n := range buckets {
+		fmt.Printf("%-12s %4d\n", bucketName(i), n)
+	}
+
+	if *post {
+		return postNewQA(options.ctx, config, *status, queue, now)
+	}
+
+	return nil
+}
+
+func postNewQA(ctx context.Context, config *Config, status string, queue []*AgingIssue, now time.Time) error {
+	notifier, err := config.slack(slackQA)
+	if err != nil {
+		return err
+	}
+	if notifier == nil {
+		return fmt.Errorf("--post requires a slack qa channel")
+	}
+
+	state, err := loadState()
+	if err != nil {
+		return err
+	}
+
+	since := now.Add(-24 * time.Hour)
+	if state.QANotified != nil {
+		since = *state.QANotified
+	}
+
+	lines := make([]string, 0)
+	for _, q := range queue {
+		if q.Entered.After(since) {
+			lines = append(lines, slackIssueLine(q.Issue))
+		}
+	}
+
+	if len(lines) > 0 {
+		title := fmt.Sprintf("%d new issue(s) in %s", len(lines), status)
+		if err := notifier.Notify(ctx, title, strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+	}
+
+	state.QANotified = &now
+	return state.save()
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type ReleaseNote struct {
+	Key     string
+	Type    string
+	Summary string
+	Commits []*Commit
+}
+
+func releaseNotesByType(notes []*ReleaseNote) ([]string, map[string][]*ReleaseNote) {
+	byType := make(map[string][]*ReleaseNote)
+	for _, n := range notes {
+		byType[n.Type] = append(byType[n.Type], n)
+	}
+
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return types, byType
+}
+
+func makeReleaseNotes(notes []*ReleaseNote, unreferenced []*Commit) string {
+	types, byType := releaseNotesByType(notes)
+
+	lines := make([]string, 0)
+	for _, t := range types {
+		lines = append(lines, fmt.Sprintf("## %s", t), "")
+		for _, n := range byType[t] {
+			lines = append(lines, fmt.Sprintf("* [%s](%s) %s", n.Key, issueURL(n.Key), n.Summary))
+			for _, c := range n.Commits {
+				lines = append(lines, fmt.Sprintf("  * %s %s", c.Hash[:8], c.Subject))
+			}
+		}
+		lines = append(lines, "")
+	}
+
+	if len(unreferenced) > 0 {
+		lines = append(lines, "## Commits without an issue", "")
+		for _, c := range unreferenced {
+			lines = append(lines, fmt.Sprintf("* %s %s (%s)", c.Hash[:8], c.Subject, c.Author))
+		}
+		lines = append(lines, "")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// makeReleaseNotesStorage renders the notes in Confluence's storage format.
+func makeReleaseNotesStorage(notes []*ReleaseNote, unreferenced []*Commit) string {
+	types, byType := releaseNotesByType(notes)
+
+	var b strings.Builder
+	for _, t := range types {
+		fmt.Fprintf(&b, "<h2>%s</h2>\n<ul>\n", html.EscapeString(t))
+		for _, n := range byType[t] {
+			fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a> %s", html.EscapeString(issueURL(n.Key)), n.Key, html.EscapeString(n.Summary))
+			if len(n.Commits) > 0 {
+				b.WriteString("<ul>")
+				for _, c := range n.Commits {
+					fmt.Fprintf(&b, "<li><code>%s</code> %s</li>", c.Hash[:8], html.EscapeString(c.Subject))
+				}
+				b.WriteString("</ul>")
+			}
+			b.WriteString("</li>\n")
+		}
+		b.WriteString("</ul>\n")
+	}
+
+	if len(unreferenced) > 0 {
+		b.WriteString("<h2>Commits without an issue</h2>\n<ul>\n")
+		for _, c := range unreferenced {
+			fmt.Fprintf(&b, "<li><code>%s</code> %s (%s)</li>\n", c.Hash[:8], html.EscapeString(c.Subject), html.EscapeString(c.Author))
+		}
+		b.WriteString("</ul>\n")
+	}
+
+	return b.String()
+}
+
+func releaseNotesCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("release-notes", flag.ExitOnError)
+	rng := flags.String("range", "", "git revision range of the release (v1.2.0..v1.3.0)")
+	commits := flags.Bool("commits", true, "include commit subjects under each issue and list commits referencing no issue")
+	publish := flags.String("publish-confluence", "", "also publish the notes as a confluence page beneath SPACE/Parent Page")
+	title := flags.String("title", "", "title of the published page, defaults to the range")
+	flags.Parse(args)
+
+	if *rng == "" {
+		return fmt.Errorf("usage: release-notes --range <revisions>")
+	}
+
+	history, err := readCommits(*rng, false)
+	if err != nil {
+		return err
+	}
+
+	projects := config.projects(options)
+	byIssue := commitsByIssue(history, projects)
+
+	notes := make([]*ReleaseNote, 0)
+	for _, key := range issueKeysInCommits(history, projects) {
+		note := &ReleaseNote{Key: key, Type: "Other"}
+		if *commits {
+			note.Commits = byIssue[key]
+		}
+
+		issue, _, err := jc.Issue.GetWithContext(options.ctx, key, nil)
+		if err != nil {
+			log.Printf("[%s] error getting issue: %v", key, err)
+			note.Summary = byIssue[key][0].Subject
+		} else {
+			note.Type = issue.Fields.Type.Name
+			note.Summary = issue.Fields.Summary
+		}
+
+		notes = append(notes, note)
+	}
+
+	unreferenced := make([]*Commit, 0)
+	if *commits {
+		for _, c := range history {
+			if len(c.issueKeys(projects)) == 0 && !strings.HasPrefix(c.Subject, "Merge ") {
+				unreferenced = append(unreferenced, c)
+			}
+		}
+	}
+
+	fmt.Print(makeReleaseNotes(notes, unreferenced))
+
+	if *publish != "" {
+		return publishConfluence(config, options, *publish, valueOr(*title, "Release notes "+*rng), makeReleaseNotesStorage(notes, unreferenced))
+	}
+
+	return nil
+}
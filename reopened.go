@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type ReopenRate struct {
+	Issues   int
+	Reopened int
+	Keys     []string
+}
+
+func reopenCount(issue *jira.Issue, statuses map[string]bool) int {
+	if issue.Changelog == nil {
+		return 0
+	}
+
+	histories := make([]jira.ChangelogHistory, len(issue.Changelog.Histories))
+	copy(histories, issue.Changelog.Histories)
+	sort.SliceStable(histories, func(i, j int) bool {
+		a, _ := histories[i].CreatedTime()
+		b, _ := histories[j].CreatedTime()
+		return a.Before(b)
+	})
+
+	resolved := false
+	count := 0
+	for _, h := range histories {
+		for _, item := range h.Items {
+			switch {
+			case item.Field == "resolution" && item.ToString != "":
+				resolved = true
+			case item.Field == "resolution" && item.ToString == "" && resolved:
+				resolved = false
+				count++
+			case item.Field == "status" && statuses[item.ToString] && resolved:
+				resolved = false
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+func reportReopenedCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report reopened", flag.ExitOnError)
+	jql := flags.String("jql", "", "restrict to issues matching this query")
+	since := flags.String("since", "90d", "only include issues resolved within this window")
+	statuses := flags.String("status", "Reopened,In Progress", "statuses that count as reopening a resolved issue")
+	flags.Parse(args)
+
+	_, err := parseSince(*since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	reopening := make(map[string]bool)
+	for _, s := range strings.Split(*statuses, ",") {
+		reopening[strings.TrimSpace(s)] = true
+	}
+
+	search := reportSearch(options, fmt.Sprintf("(resolved >= %s OR status CHANGED AFTER %s)", jqlSince(*since), jqlSince(*since)), *jql)
+
+	groups := map[string]map[string]*ReopenRate{
+		"component":  make(map[string]*ReopenRate),
+		"fixVersion": make(map[string]*ReopenRate),
+	}
+
+	err = searchIssues(jc, options, search, &jira.SearchOptions{Expand: "changelog", MaxResults: 100, Fields: withFields([]string{"summary", "resolution"}, "component", "fixVersion")}, func(issue jira.Issue) error {
+		count := reopenCount(&issue, reopening)
+		if count == 0 && issue.Fields.Resolution == nil {
+			return nil
+		}
+
+		for by, rates := range groups {
+			for _, g := range issueGroups(&issue, by) {
+				if rates[g] == nil {
+					rates[g] = &ReopenRate{}
+				}
+				rates[g].Issues++
+				if count > 0 {
+					rates[g].Reopened++
+					rates[g].Keys = append(rates[g].Keys, issue.Key)
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	for _, by := range []string{"component", "fixVersion"} {
+		names := make([]string, 0)
+		for name := range groups[by] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Printf("%-24s %8s %8s %6s  %s\n", by, "RESOLVED", "REOPENED", "RATE", "ISSUES")
+		for _, name := range names {
+			r := groups[by][name]
+			fmt.Printf("%-24s %8d %8d %5.0f%%  %s\n", name, r.Issues, r.Reopened, 100*float64(r.Reopened)/float64(r.Issues), strings.Join(r.Keys, " "))
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+	"github.com/jlewallen/jira-ops/jiraops/jiratest"
+)
+
+func upkeepThrough(t *testing.T, transport http.RoundTripper, url string) {
+	jc, err := jira.NewClient(&http.Client{Transport: transport}, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := &Options{ctx: context.Background(), Only: "FK-1", Workers: 1, Fetchers: 1}
+	if err := runUpkeep(jc.Issue, newUpkeepConfig(), options); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestUpkeepReplay records upkeep against the fake and runs it again from
+// the recording alone, which has to make exactly the same requests.
+func TestUpkeepReplay(t *testing.T) {
+	t.Setenv("JIRA_OPS_STATE", t.TempDir())
+	path := filepath.Join(t.TempDir(), "upkeep.json")
+
+	server := jiratest.NewServer(newUpkeepFake())
+	upkeepThrough(t, &client.RecordingTransport{Base: http.DefaultTransport, Cassette: client.NewCassette(path)}, server.URL)
+	server.Close()
+
+	cassette, err := client.LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	methods := make(map[string]int)
+	for _, i := range cassette.Interactions {
+		methods[i.Method]++
+	}
+	if methods["PUT"] == 0 || methods["DELETE"] == 0 {
+		t.Fatalf("recorded %v", methods)
+	}
+
+	upkeepThrough(t, &client.ReplayTransport{Cassette: cassette}, server.URL)
+
+	for _, i := range cassette.Unplayed() {
+		t.Errorf("not replayed: %s %s", i.Method, i.URL)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func reportDeploysCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report deploys", flag.ExitOnError)
+	since := flags.String("since", "30d", "only show deploy runs newer than this")
+	target := flags.String("target", "", "only show deploy runs for this target")
+	flags.Parse(args)
+
+	after, err := parseTimeOrSince(*since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	records, err := readDeployJournal()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-16s %-10s %-12s %-12s %6s %s\n", "TIME", "TARGET", "VERSION", "ENV", "ISSUES", "RUN")
+
+	runs, issues := 0, 0
+	for _, r := range records {
+		if r.Time.Before(after) || (*target != "" && r.Target != *target) {
+			continue
+		}
+
+		kind := r.Target
+		if r.Rollback {
+			kind += "*"
+		}
+
+		fmt.Printf("%-16s %-10s %-12s %-12s %6d %s\n", r.Time.Local().Format("2006/01/02 15:04"), kind, valueOr(r.Version, "-"), valueOr(r.Environment, "-"), len(r.Issues), r.Run)
+
+		runs++
+		issues += len(r.Issues)
+	}
+
+	fmt.Printf("\n%d deploy runs, %d issue transitions (* rollback)\n", runs, issues)
+
+	return nil
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
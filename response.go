@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/reports"
+)
+
+const jiraTimeLayout = "2006-01-02T15:04:05.999-0700"
+
+func sameUser(a *jira.User, b *jira.User) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a.AccountID != "" || b.AccountID != "" {
+		return a.AccountID == b.AccountID
+	}
+	return a.Name == b.Name
+}
+
+func firstResponse(issue *jira.Issue) (time.Time, bool) {
+	var first time.Time
+
+	consider := func(t time.Time) {
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+	}
+
+	if issue.Fields.Comments != nil {
+		for _, c := range issue.Fields.Comments.Comments {
+			if sameUser(&c.Author, issue.Fields.Reporter) {
+				continue
+			}
+			if created, err := time.Parse(jiraTimeLayout, c.Created); err == nil {
+				consider(created)
+			}
+		}
+	}
+
+	if issue.Changelog != nil {
+		for _, h := range issue.Changelog.Histories {
+			if sameUser(&h.Author, issue.Fields.Reporter) {
+				continue
+			}
+			for _, item := range h.Items {
+				if item.Field != "status" {
+					continue
+				}
+				if created, err := h.CreatedTime(); err == nil {
+					consider(created)
+				}
+			}
+		}
+	}
+
+	return first, !first.IsZero()
+}
+
+type ResponseTimes struct {
+	Issues     int
+	Unanswered int
+	Times      []time.Duration
+}
+
+func reportFirstResponseCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report first-response", flag.ExitOnError)
+	jql := flags.String("jql", "(type = Bug)", "restrict to issues matching this query")
+	since := flags.String("since", "90d", "only include issues created within this window")
+	by := flags.String("by", "component", "group by (component, type, assignee)")
+	flags.Parse(args)
+
+	_, err := parseSince(*since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	search := reportSearch(options, fmt.Sprintf("(created >= %s)", jqlSince(*since)), *jql)
+
+	groups := make(map[string]*ResponseTimes)
+	searchOptions := &jira.SearchOptions{Expand: "changelog", MaxResults: 100, Fields: []string{"*navigable", "comment"}}
+	err = searchIssues(jc, options, search, searchOptions, func(issue jira.Issue) error {
+		responded, ok := firstResponse(&issue)
+		for _, g := range issueGroups(&issue, *by) {
+			if groups[g] == nil {
+				groups[g] = &ResponseTimes{}
+			}
+			groups[g].Issues++
+			if ok {
+				groups[g].Times = append(groups[g].Times, responded.Sub(time.Time(issue.Fields.Created)))
+			} else {
+				groups[g].Unanswered++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	names := make([]string, 0)
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%-24s %6s %10s %8s %8s %8s\n", *by, "N", "UNANSWERED", "p50", "p85", "MAX")
+	for _, name := range names {
+		g := groups[name]
+		fmt.Printf("%-24s %6d %10d %8s %8s %8s\n", name, g.Issues, g.Unanswered,
+			reports.FormatDays(reports.Percentile(g.Times, 50)), reports.FormatDays(reports.Percentile(g.Times, 85)), reports.FormatDays(reports.Percentile(g.Times, 100)))
+	}
+
+	return nil
+}
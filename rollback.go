@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func parseTimeOrSince(value string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return parseSince(value, now)
+}
+
+func deployRollbackCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("deploy rollback", flag.ExitOnError)
+	since := flags.String("since", "", "roll back deploy runs at or after this time (2024-05-01T10:00 or 2h)")
+	run := flags.String("run", "", "roll back a single deploy run")
+	positional := parseArgs(flags, args)
+	if len(positional) != 1 || (*since == "" && *run == "") {
+		return fmt.Errorf("usage: deploy rollback <%s> --since <time> | --run <id>", strings.Join(deployTargetNames(config), "|"))
+	}
+
+	name := positional[0]
+
+	var after time.Time
+	if *since != "" {
+		var err error
+		after, err = parseTimeOrSince(*since, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	records, err := readDeployJournal()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	rollback := &DeployRecord{
+		Run:      now.UTC().Format("20060102T150405Z"),
+		Time:     now,
+		Target:   name,
+		Rollback: true,
+		Issues:   make([]string, 0),
+	}
+
+	seen := make(map[string]bool)
+
+	for _, r := range records {
+		if r.Target != name || r.Rollback || r.Time.Before(after) || (*run != "" && r.Run != *run) {
+			continue
+		}
+
+		log.Printf("rolling back %s (%s, %d issues)", r.Run, r.Time.Local().Format(jqlTimeLayout), len(r.Issues))
+
+		rollback.From, rollback.To = r.To, r.From
+
+		for _, key := range r.Issues {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			issue, _, err := jc.Issue.GetWithContext(options.ctx, key, nil)
+			if err != nil {
+				return fmt.Errorf("error getting issue: %w", err)
+			}
+
+			if issue.Fields.Status.Name != r.To {
+				log.Printf("[%s] skipping, now in '%s'", key, issue.Fields.Status.Name)
+				continue
+			}
+
+			if options.DryRun {
+				echoIssueActionMessage("would roll back", issue)
+				continue
+			}
+
+			if err := changeIssueStatus(options.ctx, jc.Issue, issue, r.From); err != nil {
+				return err
+			}
+
+			rollback.Issues = append(rollback.Issues, key)
+		}
+	}
+
+	if len(rollback.Issues) > 0 {
+		return appendDeployRecord(rollback)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+	"github.com/jlewallen/jira-ops/jiraops/markup"
+)
+
+type Rule struct {
+	Name      string
+	Transform markup.TransformFunc
+	Projects  []string
+	JQL       string
+	keys      map[string]bool
+}
+
+type Rules []*Rule
+
+func defaultRuleConfigs() []*RuleConfig {
+	disabled := false
+	return []*RuleConfig{
+		&RuleConfig{Name: "thumbnails", Type: "thumbnails"},
+		&RuleConfig{Name: "links", Type: "links", Enabled: &disabled},
+		&RuleConfig{Name: "headings", Type: "headings", Enabled: &disabled},
+		&RuleConfig{Name: "trailing-whitespace", Type: "trailing-whitespace", Enabled: &disabled},
+		&RuleConfig{Name: "blank-lines", Type: "blank-lines", Enabled: &disabled},
+	}
+}
+
+func mergeRuleConfigs(defaults, configured []*RuleConfig) []*RuleConfig {
+	merged := make([]*RuleConfig, 0)
+	byName := make(map[string]*RuleConfig)
+	for _, rc := range defaults {
+		copied := *rc
+		byName[rc.Name] = &copied
+		merged = append(merged, &copied)
+	}
+
+	for _, rc := range configured {
+		if existing, ok := byName[rc.Name]; ok && (rc.Type == "" || rc.Type == existing.Type) {
+			existing.Enabled = rc.Enabled
+			existing.Projects = rc.Projects
+			existing.JQL = rc.JQL
+			if rc.Enabled == nil {
+				enabled := true
+				existing.Enabled = &enabled
+			}
+			continue
+		}
+		merged = append(merged, rc)
+	}
+
+	return merged
+}
+
+func newRule(rc *RuleConfig) (*Rule, error) {
+	rule := &Rule{
+		Name:     rc.Name,
+		Projects: rc.Projects,
+		JQL:      rc.JQL,
+	}
+
+	if rc.Type == "regex" {
+		re, err := regexp.Compile(rc.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rc.Name, err)
+		}
+		replace := rc.Replace
+		rule.Transform = func(body string) (string, error) {
+			return re.ReplaceAllString(body, replace), nil
+		}
+		return rule, nil
+	}
+
+	transform, ok := markup.Transforms[rc.Type]
+	if !ok {
+		return nil, fmt.Errorf("rule %s: unknown type '%s'", rc.Name, rc.Type)
+	}
+
+	rule.Transform = transform
+
+	return rule, nil
+}
+
+func loadRules(ctx context.Context, issues client.Issues, config *Config) (Rules, error) {
+	configs := mergeRuleConfigs(defaultRuleConfigs(), config.Rules)
+
+	rules := make(Rules, 0)
+	for _, rc := range configs {
+		if rc.Enabled != nil && !*rc.Enabled {
+			continue
+		}
+
+		rule, err := newRule(rc)
+		if err != nil {
+			return nil, err
+		}
+
+		if rule.JQL != "" {
+			rule.keys = make(map[string]bool)
+			err := pages.Each(ctx, issues, rule.JQL, &jira.SearchOptions{Fields: []string{"key"}}, func(i *jira.Issue) error {
+				rule.keys[i.Key] = true
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: error getting issues: %w", rule.Name, err)
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (r *Rule) appliesTo(issue *jira.Issue) bool {
+	if len(r.Projects) > 0 {
+		project := strings.Split(issue.Key, "-")[0]
+		found := false
+		for _, p := range r.Projects {
+			if strings.EqualFold(p, project) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if r.keys != nil && !r.keys[issue.Key] {
+		return false
+	}
+
+	return true
+}
+
+func (rules Rules) apply(issue *jira.Issue, body string) (string, []string, error) {
+	applied := make([]string, 0)
+	for _, rule := range rules {
+		if !rule.appliesTo(issue) {
+			continue
+		}
+
+		changed, err := rule.Transform(body)
+		if err != nil {
+			return body, applied, fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+
+		if changed != body {
+			applied = append(applied, rule.Name)
+			body = changed
+		}
+	}
+	return body, applied, nil
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const sentryFrameLimit = 15
+
+var sentryIssuePattern = regexp.MustCompile(`/issues/(\d+)`)
+
+var sentryTags = []string{"release", "environment", "device", "os", "browser", "runtime"}
+
+var defaultSentryPriorities = map[string]string{
+	"fatal":   "Highest",
+	"error":   "High",
+	"warning": "Medium",
+	"info":    "Low",
+	"debug":   "Lowest",
+}
+
+type SentryFrame struct {
+	Filename string `json:"filename"`
+	Function string `json:"function"`
+	Line     int    `json:"lineNo"`
+	InApp    bool   `json:"inApp"`
+}
+
+type SentryException struct {
+	Type       string `json:"type"`
+	Value      string `json:"value"`
+	Stacktrace *struct {
+		Frames []*SentryFrame `json:"frames"`
+	} `json:"stacktrace"`
+}
+
+type SentryEvent struct {
+	ID   string `json:"eventID"`
+	Tags []*struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"tags"`
+	Entries []*struct {
+		Type string `json:"type"`
+		Data struct {
+			Values []*SentryException `json:"values"`
+		} `json:"data"`
+	} `json:"entries"`
+}
+
+type SentryIssue struct {
+	ID        string    `json:"id"`
+	ShortID   string    `json:"shortId"`
+	Title     string    `json:"title"`
+	Culprit   string    `json:"culprit"`
+	Permalink string    `json:"permalink"`
+	Level     string    `json:"level"`
+	Count     string    `json:"count"`
+	UserCount int       `json:"userCount"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Latest    *SentryEvent
+}
+
+func (e *SentryEvent) tag(key string) string {
+	for _, t := range e.Tags {
+		if t.Key == key {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+func (e *SentryEvent) exceptions() []*SentryException {
+	for _, entry := range e.Entries {
+		if entry.Type == "exception" {
+			return entry.Data.Values
+		}
+	}
+	return nil
+}
+
+// parseSentryURL splits a link to an issue into the api's base url and the
+// issue's id, sentry.io and self hosted installs serve the api on the same
+// host as the ui.
+func parseSentryURL(link string) (string, string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", "", err
+	}
+	m := sentryIssuePattern.FindStringSubmatch(u.Path)
+	if m == nil || u.Host == "" {
+		return "", "", fmt.Errorf("not a sentry issue url: %s", link)
+	}
+	return u.Scheme + "://" + u.Host, m[1], nil
+}
+
+func getSentryIssue(ctx context.Context, base, token, id string) (*SentryIssue, error) {
+	headers := map[string]string{"Authorization": "Bearer " + token}
+	api := strings.TrimSuffix(base, "/") + "/api/0/issues/" + url.PathEscape(id)
+
+	issue := &SentryIssue{}
+	if err := incidentRequest(ctx, "GET", api+"/", headers, nil, issue); err != nil {
+		return nil, fmt.Errorf("sentry: %w", err)
+	}
+
+	issue.Latest = &SentryEvent{}
+	if err := incidentRequest(ctx, "GET", api+"/events/latest/", headers, nil, issue.Latest); err != nil {
+		return nil, fmt.Errorf("sentry: %w", err)
+	}
+
+	return issue, nil
+}
+
+// stackTraceExcerpt keeps the innermost frames of each exception, most
+// recent call first, preferring the application's own frames when the
+// event says which those are.
+func stackTraceExcerpt(exceptions []*SentryException) string {
+	lines := make([]string, 0)
+	for i := len(exceptions) - 1; i >= 0; i-- {
+		e := exceptions[i]
+		lines = append(lines, fmt.Sprintf("%s: %s", e.Type, e.Value))
+		if e.Stacktrace == nil {
+			continue
+		}
+		frames := make([]*SentryFrame, 0)
+		for _, f := range e.Stacktrace.Frames {
+			if f.InApp {
+				frames = append(frames, f)
+			}
+		}
+		if len(frames) == 0 {
+			frames = e.Stacktrace.Frames
+		}
+		for j, shown := len(frames)-1, 0; j >= 0 && shown < sentryFrameLimit; j, shown = j-1, shown+1 {
+			f := frames[j]
+			lines = append(lines, fmt.Sprintf("  at %s (%s:%d)", valueOr(f.Function, "?"), f.Filename, f.Line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func makeSentryDescription(issue *SentryIssue) string {
+	lines := []string{
+		fmt.Sprintf("Created from [Sentry %s|%s].", issue.ShortID, issue.Permalink),
+		"",
+		fmt.Sprintf("*Culprit:* %s", valueOr(issue.Culprit, "-")),
+		fmt.Sprintf("*Level:* %s", valueOr(issue.Level, "-")),
+		fmt.Sprintf("*Events:* %s, *Users:* %d", valueOr(issue.Count, "0"), issue.UserCount),
+		fmt.Sprintf("*Seen:* %s to %s", issue.FirstSeen.Local().Format("2006/01/02 15:04"), issue.LastSeen.Local().Format("2006/01/02 15:04")),
+	}
+
+	for _, key := range sentryTags {
+		if value := issue.Latest.tag(key); value != "" {
+			lines = append(lines, fmt.Sprintf("*%s:* %s", strings.Title(key), value))
+		}
+	}
+
+	if trace := stackTraceExcerpt(issue.Latest.exceptions()); trace != "" {
+		lines = append(lines, "", "h3. Stack trace", "{noformat}", trace, "{noformat}")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func sentryRemoteLink(issue *SentryIssue) *jira.RemoteLink {
+	return &jira.RemoteLink{
+		GlobalID:     issue.Permalink,
+		Relationship: "sentry",
+		Object: &jira.RemoteLinkObject{
+			URL:   issue.Permalink,
+			Title: fmt.Sprintf("Sentry %s: %s", issue.ShortID, issue.Title),
+		},
+	}
+}
+
+func sentryLinkCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	sc := config.Sentry
+	if sc == nil || sc.Token == "" {
+		return fmt.Errorf("sentry.token is required")
+	}
+
+	flags := flag.NewFlagSet("sentry link", flag.ExitOnError)
+	project := flags.String("project", valueOr(sc.Project, options.Project), "project to create the bug in")
+	issueType := flags.String("type", valueOr(sc.IssueType, "Bug"), "type of the created issue")
+	positional := parseArgs(flags, args)
+
+	if len(positional) < 1 || len(positional) > 2 {
+		return fmt.Errorf("usage: sentry link <sentry-issue-url> [issue]")
+	}
+
+	base, id, err := parseSentryURL(positional[0])
+	if err != nil {
+		return err
+	}
+
+	issue, err := getSentryIssue(options.ctx, valueOr(sc.URL, base), sc.Token, id)
+	if err != nil {
+		return err
+	}
+	if issue.Permalink == "" {
+		issue.Permalink = positional[0]
+	}
+
+	link := sentryRemoteLink(issue)
+
+	if len(positional) == 2 {
+		key := strings.ToUpper(positional[1])
+
+		log.Printf("[%s] linking sentry %s", key, issue.ShortID)
+
+		if options.DryRun {
+			return nil
+		}
+
+		if _, _, err := jc.Issue.AddRemoteLinkWithContext(options.ctx, key, link); err != nil {
+			return fmt.Errorf("error adding remote link: %w", err)
+		}
+
+		fmt.Println(issueURL(key))
+
+		return nil
+	}
+
+	existing, err := findLinkedIssue(options.ctx, jc, issue.Permalink)
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+	if existing != nil {
+		log.Printf("[%s] sentry %s already linked", existing.Key, issue.ShortID)
+		fmt.Println(issueURL(existing.Key))
+		return nil
+	}
+
+	fields := &jira.IssueFields{
+		Project:     jira.Project{Key: *project},
+		Type:        jira.IssueType{Name: *issueType},
+		Summary:     issue.Title,
+		Description: makeSentryDescription(issue),
+		Labels:      append([]string{}, sc.Labels...),
+	}
+	if priority := defaultSentryPriorities[issue.Level]; priority != "" {
+		fields.Priority = &jira.Priority{Name: priority}
+	}
+
+	log.Printf("creating %s from sentry %s '%s'", *project, issue.ShortID, issue.Title)
+
+	if options.DryRun {
+		fmt.Println(fields.Description)
+		return nil
+	}
+
+	created, _, err := jc.Issue.CreateWithContext(options.ctx, &jira.Issue{Fields: fields})
+	if err != nil {
+		return fmt.Errorf("error creating issue: %w", err)
+	}
+
+	if _, _, err := jc.Issue.AddRemoteLinkWithContext(options.ctx, created.Key, link); err != nil {
+		return fmt.Errorf("error adding remote link: %w", err)
+	}
+
+	log.Printf("[%s] created from sentry %s", created.Key, issue.ShortID)
+
+	fmt.Println(issueURL(created.Key))
+
+	return nil
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const (
+	webhookIssueCreated    = "issue_created"
+	webhookIssueUpdated    = "issue_updated"
+	webhookAttachmentAdded = "attachment_added"
+)
+
+const maxWebhookBody = 4 * 1024 * 1024
+
+var webhookActions = []string{"mirror", "upkeep", "slack"}
+
+func defaultServeActions() []*ServeActionConfig {
+	return []*ServeActionConfig{
+		&ServeActionConfig{Events: []string{webhookIssueCreated, webhookIssueUpdated}, Action: "upkeep"},
+		&ServeActionConfig{Events: []string{webhookAttachmentAdded}, Action: "mirror"},
+	}
+}
+
+func (a *ServeActionConfig) matches(event string) bool {
+	if len(a.Events) == 0 {
+		return true
+	}
+	for _, e := range a.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookPayload struct {
+	WebhookEvent string      `json:"webhookEvent"`
+	User         *jira.User  `json:"user"`
+	Issue        *jira.Issue `json:"issue"`
+	Changelog    *struct {
+		Items []jira.ChangelogItems `json:"items"`
+	} `json:"changelog"`
+}
+
+// events maps a Jira webhook to the events actions are configured with, an
+// update adding an attachment is both issue_updated and attachment_added.
+func (p *WebhookPayload) events() []string {
+	names := make([]string, 0)
+	switch p.WebhookEvent {
+	case "jira:issue_created":
+		names = append(names, webhookIssueCreated)
+	case "jira:issue_updated":
+		names = append(names, webhookIssueUpdated)
+	}
+	if p.Changelog != nil {
+		for _, item := range p.Changelog.Items {
+			if strings.EqualFold(item.Field, "Attachment") && item.ToString != "" {
+				names = append(names, webhookAttachmentAdded)
+				break
+			}
+		}
+	}
+	return names
+}
+
+type WebhookJob struct {
+	Event string
+	Issue *jira.Issue
+	User  string
+}
+
+func (j *WebhookJob) id() string {
+	return j.Event + " " + j.Issue.Key
+}
+
+// Webhooks are signed with the secret in X-Hub-Signature where Jira
+// supports it, otherwise the secret is expected in the url's query.
+func validWebhook(r *http.Request, body []byte, secret string) bool {
+	if signature := r.Header.Get("X-Hub-Signature"); signature != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(signature), []byte(expected))
+	}
+	return validSecret(r, secret)
+}
+
+// validSecret checks the secret passed in the url's query.
+func validSecret(r *http.Request, secret string) bool {
+	token := r.URL.Query().Get("secret")
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+// requireSecret only lets requests passing ?secret= through to handler.
+func requireSecret(secret string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validSecret(r, secret) {
+			http.Error(w, "invalid secret", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+type WebhookServer struct {
+	jc      *jira.Client
+	config  *Config
+	options *Options
+	secret  string
+	actions []*ServeActionConfig
+	upkeep  *Upkeep
+	queue   chan *WebhookJob
+	pending map[string]bool
+	lock    sync.Mutex
+}
+
+func (s *WebhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !validWebhook(r, body, s.secret) {
+		log.Printf("serve: rejected webhook from %s", r.RemoteAddr)
+		http.Error(w, "invalid secret", http.StatusUnauthorized)
+		return
+	}
+
+	payload := &WebhookPayload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if payload.Issue == nil || payload.Issue.Key == "" {
+		log.Printf("serve: ignoring %s without an issue", payload.WebhookEvent)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if payload.Issue.Fields == nil {
+		payload.Issue.Fields = &jira.IssueFields{}
+	}
+
+	user := ""
+	if payload.User != nil {
+		user = payload.User.DisplayName
+	}
+
+	for _, event := range payload.events() {
+		if !s.enqueue(&WebhookJob{Event: event, Issue: payload.Issue, User: user}) {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// enqueue drops jobs already waiting for the same event and issue, Jira
+// tends to send several updates in quick succession.
+func (s *WebhookServer) enqueue(job *WebhookJob) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.pending[job.id()] {
+		return true
+	}
+
+	select {
+	case s.queue <- job:
+		s.pending[job.id()] = true
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *WebhookServer) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.queue:
+			s.lock.Lock()
+			delete(s.pending, job.id())
+			s.lock.Unlock()
+
+			s.handle(ctx, job)
+		}
+	}
+}
+
+func (s *WebhookServer) handle(ctx context.Context, job *WebhookJob) {
+	for _, a := range s.actions {
+		if !a.matches(job.Event) {
+			continue
+		}
+		log.Printf("[%s] %s: %s", job.Issue.Key, job.Event, a.Action)
+		if err := s.act(ctx, a, job); err != nil {
+			log.Printf("[%s] %s: %v", job.Issue.Key, a.Action, err)
+		}
+	}
+}
+
+func (s *WebhookServer) act(ctx context.Context, a *ServeActionConfig, job *WebhookJob) error {
+	switch a.Action {
+	case "mirror":
+		engine, _, err := newMirrorEngine(s.jc, s.config, s.options)
+		if err != nil {
+			return err
+		}
+		_, err = engine.Run(ctx, fmt.Sprintf("key = '%s'", job.Issue.Key))
+		return err
+	case "upkeep":
+		return s.upkeep.issue(ctx, job.Issue.Key)
+	case "slack":
+		notifier, err := s.config.slack(a.Channel)
+		if err != nil {
+			return err
+		}
+		if notifier == nil {
+			return fmt.Errorf("no slack channel for '%s'", a.Channel)
+		}
+		title := fmt.Sprintf("%s %s", job.Issue.Key, strings.ReplaceAll(job.Event, "_", " "))
+		body := slackIssueLine(job.Issue)
+		if job.User != "" {
+			body += " _by " + job.User + "_"
+		}
+		return notifier.Notify(ctx, title, body)
+	}
+	return fmt.Errorf("unknown action: %s", a.Action)
+}
+
+func validateServeActions(actions []*ServeActionConfig) error {
+	for _, a := range actions {
+		known := false
+		for _, name := range webhookActions {
+			if a.Action == name {
+				known = true
+			}
+		}
+		if !known {
+			return fmt.Errorf("serve: unknown action '%s'", a.Action)
+		}
+		if a.Action == "slack" && a.Channel == "" {
+			return fmt.Errorf("serve: slack action requires a channel")
+		}
+	}
+	return nil
+}
+
+func serveCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	sc := config.Serve
+	if sc == nil {
+		sc = &ServeConfig{}
+	}
+
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := flags.String("listen", valueOr(sc.Listen, ":8080"), "address to receive webhooks on")
+	secret := flags.String("secret", sc.Secret, "secret webhooks are signed with or pass as ?secret=")
+	flags.Parse(args)
+
+	if *secret == "" {
+		return fmt.Errorf("serve requires a webhook secret")
+	}
+
+	actions := sc.Actions
+	if len(actions) == 0 {
+		actions = defaultServeActions()
+	}
+	if err := validateServeActions(actions); err != nil {
+		return err
+	}
+
+	u, err := newUpkeep(jc.Issue, config, options)
+	if err != nil {
+		return err
+	}
+
+	if !options.DryRun {
+		u.audit, err = openAuditLog()
+		if err != nil {
+			return err
+		}
+
+		defer u.audit.close()
+	}
+
+	server := &WebhookServer{
+		jc:      jc,
+		config:  config,
+		options: options,
+		secret:  *secret,
+		actions: actions,
+		upkeep:  u,
+		queue:   make(chan *WebhookJob, 100),
+		pending: make(map[string]bool),
+	}
+
+	go server.run(options.ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle("/webhook", server)
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	if config.Feed != nil {
+		mux.HandleFunc("/feed.xml", requireSecret(*secret, cachedHandler("feed", "application/atom+xml; charset=utf-8", feedRefresh, func() ([]byte, error) {
+			return config.Feed.build(jc, options)
+		})))
+	}
+
+	hs := &http.Server{Addr: *listen, Handler: mux}
+
+	go func() {
+		<-options.ctx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hs.Shutdown(ctx)
+	}()
+
+	log.Printf("serve: listening on %s", *listen)
+
+	if err := hs.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func interruptible() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		s := <-signals
+		log.Printf("%v, stopping (repeat to quit immediately)", s)
+		signal.Stop(signals)
+		cancel()
+	}()
+
+	return ctx
+}
+
+func interrupted(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
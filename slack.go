@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const (
+	slackDeploy = "deploy"
+	slackQA     = "qa"
+	slackDigest = "digest"
+)
+
+func (c *Config) slack(name string) (Notifier, error) {
+	if c.Slack == nil {
+		return nil, nil
+	}
+	channel, ok := c.Slack.Channels[name]
+	if !ok {
+		return nil, nil
+	}
+	if c.Slack.Token == "" && c.Slack.Webhook == "" {
+		return nil, fmt.Errorf("slack requires a webhook or token")
+	}
+	if c.Slack.Token != "" && channel == "" {
+		return nil, fmt.Errorf("slack channel for %s is required with a token", name)
+	}
+	return &slackNotifier{webhook: c.Slack.Webhook, token: c.Slack.Token, channel: channel}, nil
+}
+
+func slackIssueLine(issue *jira.Issue) string {
+	return fmt.Sprintf("• <%s|%s> %s", issueURL(issue.Key), issue.Key, issue.Fields.Summary)
+}
+
+func makeDeployAnnouncement(deployment *Deployment, target *DeployTargetConfig, issues []jira.Issue) (string, string) {
+	title := fmt.Sprintf("%s deployed", deployment.Target)
+	if deployment.Version != "" {
+		title = fmt.Sprintf("%s %s deployed", deployment.Target, deployment.Version)
+	}
+	if deployment.Environment != "" {
+		title += " to " + deployment.Environment
+	}
+
+	lines := []string{fmt.Sprintf("%d issue(s) moved to _%s_", len(issues), target.Destination)}
+	for _, i := range issues {
+		lines = append(lines, slackIssueLine(&i))
+	}
+	if deployment.BuildURL != "" {
+		lines = append(lines, fmt.Sprintf("<%s|Build>", deployment.BuildURL))
+	}
+
+	return title, strings.Join(lines, "\n")
+}
+
+func announceDeploy(ctx context.Context, config *Config, deployment *Deployment, target *DeployTargetConfig, issues []jira.Issue) {
+	if len(issues) == 0 {
+		return
+	}
+
+	if teams := config.teams(slackDeploy); teams != nil {
+		if err := teams.post(ctx, makeDeployCard(deployment, target, issues)); err != nil {
+			log.Printf("teams: %v", err)
+		}
+	}
+
+	notifier, err := config.slack(slackDeploy)
+	if err != nil {
+		log.Printf("slack: %v", err)
+		return
+	}
+	if notifier == nil {
+		return
+	}
+
+	title, body := makeDeployAnnouncement(deployment, target, issues)
+	if err := notifier.Notify(ctx, title, body); err != nil {
+		log.Printf("slack: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type SprintIssue struct {
+	Issue     *jira.Issue
+	Points    float64
+	Added     bool
+	Completed bool
+}
+
+func findSprint(ctx context.Context, jc *jira.Client, board int, query string) (*jira.Sprint, error) {
+	if board == 0 {
+		return nil, fmt.Errorf("no board configured, use --board or set board in the configuration")
+	}
+
+	options := &jira.GetAllSprintsOptions{State: "active,closed"}
+	var found *jira.Sprint
+	for {
+		sprints, _, err := jc.Board.GetAllSprintsWithOptionsWithContext(ctx, board, options)
+		if err != nil {
+			return nil, fmt.Errorf("error getting sprints: %w", err)
+		}
+		for i := range sprints.Values {
+			s := &sprints.Values[i]
+			if strconv.Itoa(s.ID) == query || strings.EqualFold(s.Name, query) || (query == "active" && s.State == "active") {
+				return s, nil
+			}
+			if strings.Contains(strings.ToLower(s.Name), strings.ToLower(query)) {
+				found = s
+			}
+		}
+		if sprints.IsLast || len(sprints.Values) == 0 {
+			break
+		}
+		options.StartAt += len(sprints.Values)
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no sprint matching '%s' on board %d", query, board)
+	}
+
+	return found, nil
+}
+
+func addedToSprint(issue *jira.Issue, sprint *jira.Sprint) time.Time {
+	if issue.Changelog == nil {
+		return time.Time{}
+	}
+
+	id := strconv.Itoa(sprint.ID)
+
+	var added time.Time
+	for _, h := range issue.Changelog.Histories {
+		for _, item := range h.Items {
+			if item.Field != "Sprint" {
+				continue
+			}
+			from := fmt.Sprintf(",%s,", strings.ReplaceAll(fmt.Sprintf("%v", item.From), " ", ""))
+			to := fmt.Sprintf(",%s,", strings.ReplaceAll(fmt.Sprintf("%v", item.To), " ", ""))
+			if strings.Contains(to, ","+id+",") && !strings.Contains(from, ","+id+",") {
+				if created, err := h.CreatedTime(); err == nil && created.After(added) {
+					added = created
+				}
+			}
+		}
+	}
+
+	return added
+}
+
+func reportSprintCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report sprint", flag.ExitOnError)
+	board := flags.Int("board", config.Board, "agile board the sprint belongs to")
+	positional := parseArgs(flags, args)
+
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: report sprint <id|name|active> [--board <id>]")
+	}
+
+	sprint, err := findSprint(options.ctx, jc, *board, positional[0])
+	if err != nil {
+		return err
+	}
+
+	if sprint.StartDate == nil {
+		return fmt.Errorf("sprint %s has not started", sprint.Name)
+	}
+
+	end := time.Now()
+	if sprint.CompleteDate != nil {
+		end = *sprint.CompleteDate
+	}
+
+	issues := make([]*SprintIssue, 0)
+	search := fmt.Sprintf("sprint = %d", sprint.ID)
+	err = searchIssues(jc, options, search, &jira.SearchOptions{Expand: "changelog", MaxResults: 100, Fields: withFields(listingFields, "created", "resolution", "resolutiondate", config.PointsField)}, func(issue jira.Issue) error {
+		started := sprint.StartDate.Add(time.Minute)
+		si := &SprintIssue{
+			Issue: listed(&issue),
+			Added: addedToSprint(&issue, sprint).After(started) || time.Time(issue.Fields.Created).After(started),
+		}
+		if config.PointsField != "" {
+			si.Points, _ = numericField(&issue, config.PointsField)
+		}
+		if resolved := time.Time(issue.Fields.Resolutiondate); issue.Fields.Resolution != nil && !resolved.After(end) {
+			si.Completed = true
+		}
+		issues = append(issues, si)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	var committed, committedDone, added, addedDone int
+	var committedPoints, committedDonePoints, addedPoints, addedDonePoints float64
+
+	fmt.Printf("%s (%s - %s, %s)\n\n", sprint.Name, sprint.StartDate.Local().Format("2006/01/02"), end.Local().Format("2006/01/02"), sprint.State)
+
+	for _, si := range issues {
+		state := " "
+		if si.Completed {
+			state = "✓"
+		}
+		scope := ""
+		if si.Added {
+			scope = "added"
+			added++
+			addedPoints += si.Points
+			if si.Completed {
+				addedDone++
+				addedDonePoints += si.Points
+			}
+		} else {
+			committed++
+			committedPoints += si.Points
+			if si.Completed {
+				committedDone++
+				committedDonePoints += si.Points
+			}
+		}
+		fmt.Printf("%s %-8s %5.1f %-6s %-16s %s\n", state, si.Issue.Key, si.Points, scope, si.Issue.Fields.Status.Name, si.Issue.Fields.Summary)
+	}
+
+	fmt.Println()
+	fmt.Printf("committed %3d issues %6.1f points, completed %3d issues %6.1f points\n", committed, committedPoints, committedDone, committedDonePoints)
+	fmt.Printf("added     %3d issues %6.1f points, completed %3d issues %6.1f points\n", added, addedPoints, addedDone, addedDonePoints)
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"text/template"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+)
+
+const defaultStaleComment = "This issue has been {{ .Status }} without updates for {{ .Days }} days. Is it still being worked on?"
+
+type StaleIssue struct {
+	Key      string
+	Summary  string
+	Status   string
+	Assignee string
+	Days     int
+}
+
+func addLabel(ctx context.Context, issues client.Issues, issue *jira.Issue, label string) error {
+	update := map[string]interface{}{
+		"update": map[string]interface{}{
+			"labels": []map[string]string{{"add": label}},
+		},
+	}
+	if _, err := issues.UpdateIssueWithContext(ctx, issue.Key, update); err != nil {
+		return fmt.Errorf("error adding label: %w", err)
+	}
+	return nil
+}
+
+func hasLabel(issue *jira.Issue, label string) bool {
+	for _, l := range issue.Fields.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+func nagStaleIssue(issues client.Issues, sc *StaleConfig, issue *jira.Issue, options *Options, audit *AuditLog) error {
+	stale := &StaleIssue{
+		Key:     issue.Key,
+		Summary: issue.Fields.Summary,
+		Status:  issue.Fields.Status.Name,
+		Days:    sc.Days,
+	}
+	if issue.Fields.Assignee != nil {
+		stale.Assignee = issue.Fields.Assignee.Name
+	}
+
+	echoIssueActionMessage(fmt.Sprintf("stale (%s)", sc.Action), issue)
+
+	if options.DryRun {
+		return nil
+	}
+
+	switch sc.Action {
+	case "comment", "":
+		text := sc.Comment
+		if text == "" {
+			text = defaultStaleComment
+		}
+		t, err := template.New("stale").Parse(text)
+		if err != nil {
+			return fmt.Errorf("stale comment template: %w", err)
+		}
+		var body bytes.Buffer
+		if err := t.Execute(&body, stale); err != nil {
+			return fmt.Errorf("stale comment template: %w", err)
+		}
+		if _, _, err := issues.AddCommentWithContext(options.ctx, issue.Key, &jira.Comment{Body: body.String()}); err != nil {
+			return fmt.Errorf("error adding comment: %w", err)
+		}
+		return audit.record(issue.Key, "comment", []string{"stale"}, "", body.String())
+	case "label":
+		label := sc.Label
+		if label == "" {
+			label = "stale"
+		}
+		if hasLabel(issue, label) {
+			return nil
+		}
+		if err := addLabel(options.ctx, issues, issue, label); err != nil {
+			return err
+		}
+		return audit.record(issue.Key, "labels", []string{"stale"}, "", label)
+	case "flag":
+		if sc.FlagField == "" {
+			return fmt.Errorf("stale rule for '%s' is missing flagField", sc.Status)
+		}
+		update := map[string]interface{}{
+			"fields": map[string]interface{}{
+				sc.FlagField: []map[string]string{{"value": "Impediment"}},
+			},
+		}
+		if _, err := issues.UpdateIssueWithContext(options.ctx, issue.Key, update); err != nil {
+			return fmt.Errorf("error flagging: %w", err)
+		}
+		return audit.record(issue.Key, sc.FlagField, []string{"stale"}, "", "Impediment")
+	}
+
+	return fmt.Errorf("unknown stale action: %s", sc.Action)
+}
+
+func nagStaleIssues(issues client.Issues, config *Config, options *Options, audit *AuditLog) error {
+	for _, sc := range config.Stale {
+		search := fmt.Sprintf("(status = '%s') AND (resolution IS EMPTY) AND (updated <= -%dd)", sc.Status, sc.Days)
+		if options.JQL != "" {
+			search += fmt.Sprintf(" AND (%s)", options.JQL)
+		}
+		if options.projectSet {
+			search += fmt.Sprintf(" AND (project = '%s')", options.Project)
+		}
+
+		log.Printf("stale: %s", search)
+
+		found, err := pages.All(options.ctx, issues, search+" ORDER BY updated ASC", &jira.SearchOptions{Fields: listingFields})
+		if err != nil {
+			return fmt.Errorf("error getting issues: %w", err)
+		}
+
+		for _, i := range found {
+			if config.skipped(&i) {
+				continue
+			}
+			if err := nagStaleIssue(issues, sc, &i, options, audit); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/reports"
+)
+
+type EpicProgress struct {
+	Epic         *jira.Issue
+	Children     int
+	Done         int
+	LastActivity time.Time
+}
+
+func (p *EpicProgress) completion() float64 {
+	if p.Children == 0 {
+		return 0
+	}
+	return 100 * float64(p.Done) / float64(p.Children)
+}
+
+func epicProgress(jc *jira.Client, options *Options, epic *jira.Issue) (*EpicProgress, error) {
+	progress := &EpicProgress{
+		Epic:         epic,
+		LastActivity: time.Time(epic.Fields.Updated),
+	}
+
+	search := fmt.Sprintf("issue IN linkedIssues(%s) AND type != Epic", epic.Key)
+	err := searchIssues(jc, options, search, &jira.SearchOptions{MaxResults: 100, Fields: []string{"status", "updated"}}, func(child jira.Issue) error {
+		progress.Children++
+		if child.Fields.Status != nil && child.Fields.Status.StatusCategory.Key == "done" {
+			progress.Done++
+		}
+		if updated := time.Time(child.Fields.Updated); updated.After(progress.LastActivity) {
+			progress.LastActivity = updated
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting children of %s: %w", epic.Key, err)
+	}
+
+	return progress, nil
+}
+
+func reportEpicsCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report epics", flag.ExitOnError)
+	days := flags.Int("days", 30, "epics without child activity for this many days are stale")
+	all := flags.Bool("all", false, "list every open epic, not just stale and overdue ones")
+	flags.Parse(args)
+
+	epics := make([]jira.Issue, 0)
+	err := searchIssues(jc, options, reportSearch(options, "(type = 'Epic') AND (resolution IS EMPTY)", "")+" ORDER BY dueDate ASC", &jira.SearchOptions{MaxResults: 100, Fields: []string{"summary", "status", "updated", "duedate"}}, func(epic jira.Issue) error {
+		epics = append(epics, epic)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	threshold := now.AddDate(0, 0, -*days)
+
+	fmt.Printf("%-8s %6s %9s %-10s %-14s %s\n", "EPIC", "DONE", "CHILDREN", "DUE", "FLAGS", "SUMMARY")
+
+	for i := range epics {
+		epic := &epics[i]
+
+		progress, err := epicProgress(jc, options, epic)
+		if err != nil {
+			return err
+		}
+
+		notes := ""
+		due := time.Time(epic.Fields.Duedate)
+		if !due.IsZero() && due.Before(today) {
+			notes += "overdue "
+		}
+		if progress.LastActivity.Before(threshold) {
+			notes += fmt.Sprintf("idle %s", reports.FormatDays(now.Sub(progress.LastActivity)))
+		}
+
+		if notes == "" && !*all {
+			continue
+		}
+
+		dueText := "-"
+		if !due.IsZero() {
+			dueText = due.Format("2006/01/02")
+		}
+
+		fmt.Printf("%-8s %5.0f%% %9d %-10s %-14s %s\n", epic.Key, progress.completion(), progress.Children, dueText, notes, epic.Fields.Summary)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const jqlTimeLayout = "2006/01/02 15:04"
+const jqlDateLayout = "2006/01/02"
+
+type State struct {
+	UpkeepLastRun *time.Time `json:"upkeepLastRun,omitempty"`
+	MirrorLastRun *time.Time `json:"mirrorLastRun,omitempty"`
+	QANotified    *time.Time `json:"qaNotified,omitempty"`
+}
+
+func stateDirectory() string {
+	if dir := os.Getenv("JIRA_OPS_STATE"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".jira-ops"
+	}
+	return path.Join(home, ".jira-ops")
+}
+
+func statePath() string {
+	return path.Join(stateDirectory(), "state.json")
+}
+
+func loadState() (*State, error) {
+	state := &State{}
+
+	data, err := ioutil.ReadFile(statePath())
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", statePath(), err)
+	}
+
+	return state, nil
+}
+
+func (s *State) save() error {
+	if err := os.MkdirAll(stateDirectory(), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(statePath(), data, 0644)
+}
+
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if len(value) > 1 {
+		unit := value[len(value)-1:]
+		if unit == "d" || unit == "w" {
+			n, err := strconv.Atoi(strings.TrimSuffix(value, unit))
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid duration: %s", value)
+			}
+			days := n
+			if unit == "w" {
+				days = n * 7
+			}
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid duration: %s", value)
+	}
+
+	return now.Add(-d), nil
+}
+
+// jqlSince is a --since window as a date relative to now. Absolute dates
+// would make the same report a different search every day, which the cache
+// can't answer.
+func jqlSince(since string) string {
+	if unit := since[len(since)-1:]; unit == "d" || unit == "w" {
+		return "-" + since
+	}
+	d, _ := time.ParseDuration(since)
+	return fmt.Sprintf("-%dm", int(d.Minutes()))
+}
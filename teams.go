@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const adaptiveCardSchema = "http://adaptivecards.io/schemas/adaptive-card.json"
+
+var slackLinkRegexp = regexp.MustCompile(`<([^|>]+)\|([^>]+)>`)
+var slackBoldRegexp = regexp.MustCompile(`(^|\s)\*([^*\n]+)\*`)
+
+type AdaptiveCard struct {
+	Type    string        `json:"type"`
+	Schema  string        `json:"$schema"`
+	Version string        `json:"version"`
+	Body    []interface{} `json:"body"`
+	Actions []interface{} `json:"actions,omitempty"`
+}
+
+type CardText struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Size     string `json:"size,omitempty"`
+	Weight   string `json:"weight,omitempty"`
+	IsSubtle bool   `json:"isSubtle,omitempty"`
+	Wrap     bool   `json:"wrap"`
+	Spacing  string `json:"spacing,omitempty"`
+}
+
+type CardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type CardFactSet struct {
+	Type  string      `json:"type"`
+	Facts []*CardFact `json:"facts"`
+}
+
+type CardOpenURL struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+func newAdaptiveCard(title string) *AdaptiveCard {
+	return &AdaptiveCard{
+		Type:    "AdaptiveCard",
+		Schema:  adaptiveCardSchema,
+		Version: "1.4",
+		Body:    []interface{}{&CardText{Type: "TextBlock", Text: title, Size: "Medium", Weight: "Bolder", Wrap: true}},
+	}
+}
+
+func (c *AdaptiveCard) text(text string) *CardText {
+	block := &CardText{Type: "TextBlock", Text: text, Wrap: true}
+	c.Body = append(c.Body, block)
+	return block
+}
+
+func (c *AdaptiveCard) heading(text string) {
+	c.Body = append(c.Body, &CardText{Type: "TextBlock", Text: text, Weight: "Bolder", Wrap: true, Spacing: "Medium"})
+}
+
+func (c *AdaptiveCard) facts(facts ...*CardFact) {
+	set := &CardFactSet{Type: "FactSet"}
+	for _, f := range facts {
+		if f.Value != "" {
+			set.Facts = append(set.Facts, f)
+		}
+	}
+	c.Body = append(c.Body, set)
+}
+
+func (c *AdaptiveCard) link(title, url string) {
+	c.Actions = append(c.Actions, &CardOpenURL{Type: "Action.OpenUrl", Title: title, URL: url})
+}
+
+func cardIssueLine(key, summary, note string) string {
+	line := fmt.Sprintf("[%s](%s) %s", key, issueURL(key), summary)
+	if note != "" {
+		line += fmt.Sprintf(" _%s_", note)
+	}
+	return line
+}
+
+// teamsText converts the Slack formatting other notifications are written
+// in to the markdown Teams understands.
+func teamsText(message string) string {
+	message = slackLinkRegexp.ReplaceAllString(message, "[$2]($1)")
+	message = slackBoldRegexp.ReplaceAllString(message, "$1**$2**")
+	return strings.ReplaceAll(message, "• ", "- ")
+}
+
+type teamsNotifier struct {
+	webhook string
+}
+
+func (n *teamsNotifier) Notify(ctx context.Context, title, message string) error {
+	card := newAdaptiveCard(title)
+	card.text(teamsText(message))
+	return n.post(ctx, card)
+}
+
+func (n *teamsNotifier) post(ctx context.Context, card *AdaptiveCard) error {
+	payload := map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{"contentType": "application/vnd.microsoft.card.adaptive", "content": card},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := notifyClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("teams notification: %w", err)
+	}
+
+	defer r.Body.Close()
+
+	if r.StatusCode < 200 || r.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(r.Body)
+		return fmt.Errorf("teams notification: %s %s", r.Status, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// teams returns the notifier for a notification's webhook, nil when the
+// notification isn't configured to go to Teams.
+func (c *Config) teams(name string) *teamsNotifier {
+	if c.Teams == nil || c.Teams.Webhooks[name] == "" {
+		return nil
+	}
+	return &teamsNotifier{webhook: c.Teams.Webhooks[name]}
+}
+
+func makeDeployCard(deployment *Deployment, target *DeployTargetConfig, issues []jira.Issue) *AdaptiveCard {
+	title, _ := makeDeployAnnouncement(deployment, target, issues)
+	card := newAdaptiveCard(title)
+	card.facts(
+		&CardFact{Title: "Target", Value: deployment.Target},
+		&CardFact{Title: "Version", Value: deployment.Version},
+		&CardFact{Title: "Environment", Value: deployment.Environment},
+		&CardFact{Title: "Moved to", Value: target.Destination},
+		&CardFact{Title: "Issues", Value: fmt.Sprintf("%d", len(issues))},
+	)
+	for _, i := range issues {
+		card.text(cardIssueLine(i.Key, i.Fields.Summary, "")).Spacing = "None"
+	}
+	if deployment.BuildURL != "" {
+		card.link("Build", deployment.BuildURL)
+	}
+	return card
+}
+
+func (d *Digest) teams() *AdaptiveCard {
+	card := newAdaptiveCard(d.Title)
+	for _, s := range d.Sections {
+		card.heading(fmt.Sprintf("%s (%d)", s.Title, len(s.Items)))
+		for _, i := range s.Items {
+			card.text(cardIssueLine(i.Key, i.Summary, i.Note)).Spacing = "None"
+		}
+	}
+	return card
+}
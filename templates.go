@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+var defaultTemplateSections = []string{"Steps to Reproduce", "Expected", "Actual"}
+
+type DescriptionTemplate struct {
+	Type     string
+	Sections []string
+	Insert   bool
+	headings []*regexp.Regexp
+}
+
+func loadDescriptionTemplates(config *Config) ([]*DescriptionTemplate, error) {
+	templates := make([]*DescriptionTemplate, 0)
+	for _, tc := range config.Templates {
+		template := &DescriptionTemplate{
+			Type:     tc.Type,
+			Sections: tc.Sections,
+			Insert:   tc.Action == "insert",
+		}
+		if tc.Action != "" && tc.Action != "insert" && tc.Action != "report" {
+			return nil, fmt.Errorf("template %s: unknown action '%s'", tc.Type, tc.Action)
+		}
+		if len(template.Sections) == 0 {
+			template.Sections = defaultTemplateSections
+		}
+		for _, section := range template.Sections {
+			pattern := fmt.Sprintf(`(?im)^\s*(h[1-6]\.\s*|#+\s*)?[*_]*%s`, regexp.QuoteMeta(section))
+			template.headings = append(template.headings, regexp.MustCompile(pattern))
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+func (t *DescriptionTemplate) missing(description string) []string {
+	missing := make([]string, 0)
+	for i, heading := range t.headings {
+		if !heading.MatchString(description) {
+			missing = append(missing, t.Sections[i])
+		}
+	}
+	return missing
+}
+
+func applyDescriptionTemplates(templates []*DescriptionTemplate, issue *jira.Issue, description string, out io.Writer) (string, []string) {
+	applied := make([]string, 0)
+	for _, t := range templates {
+		if !strings.EqualFold(t.Type, issue.Fields.Type.Name) {
+			continue
+		}
+
+		missing := t.missing(description)
+		if len(missing) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(out, "%-8s %v (missing %s)\n", issue.Key, issue.Fields.Summary, strings.Join(missing, ", "))
+
+		if t.Insert {
+			skeleton := make([]string, 0)
+			for _, section := range missing {
+				skeleton = append(skeleton, fmt.Sprintf("h3. %s\n", section))
+			}
+			description = strings.TrimRight(description, "\n") + "\n\n" + strings.Join(skeleton, "\n")
+			applied = append(applied, "template")
+		}
+	}
+	return description, applied
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const tempoURL = "https://api.tempo.io/4"
+const tempoAccountAttribute = "_Account_"
+const tempoPageSize = 1000
+
+type Worklog struct {
+	Started    time.Time
+	Spent      time.Duration
+	Comment    string
+	Attributes map[string]string
+}
+
+// WorklogStore is where time is logged, Jira's own worklogs or Tempo's
+// when Tempo is configured. Issues only need their key, Tempo looks up the
+// id when it's missing.
+type WorklogStore interface {
+	Worklogs(ctx context.Context, issue *jira.Issue) ([]*Worklog, error)
+	AddWorklog(ctx context.Context, issue *jira.Issue, w *Worklog) error
+}
+
+func newWorklogStore(jc *jira.Client, config *Config) (WorklogStore, error) {
+	if config.Tempo == nil {
+		return &jiraWorklogs{jc: jc}, nil
+	}
+	return newTempo(jc, config)
+}
+
+func newTempo(jc *jira.Client, config *Config) (*tempoWorklogs, error) {
+	if config.Tempo == nil || config.Tempo.Token == "" {
+		return nil, fmt.Errorf("tempo.token is required")
+	}
+	return &tempoWorklogs{jc: jc, config: config.Tempo}, nil
+}
+
+type jiraWorklogs struct {
+	jc *jira.Client
+}
+
+func (s *jiraWorklogs) Worklogs(ctx context.Context, issue *jira.Issue) ([]*Worklog, error) {
+	found, _, err := s.jc.Issue.GetWorklogsWithContext(ctx, issue.Key)
+	if err != nil {
+		return nil, fmt.Errorf("error getting worklogs: %w", err)
+	}
+	worklogs := make([]*Worklog, 0)
+	for _, w := range found.Worklogs {
+		worklog := &Worklog{Spent: time.Duration(w.TimeSpentSeconds) * time.Second, Comment: w.Comment}
+		if w.Started != nil {
+			worklog.Started = time.Time(*w.Started)
+		}
+		worklogs = append(worklogs, worklog)
+	}
+	return worklogs, nil
+}
+
+func (s *jiraWorklogs) AddWorklog(ctx context.Context, issue *jira.Issue, w *Worklog) error {
+	if len(w.Attributes) > 0 {
+		return fmt.Errorf("worklog attributes require tempo")
+	}
+	started := jira.Time(w.Started)
+	record := &jira.WorklogRecord{
+		Comment:          w.Comment,
+		Started:          &started,
+		TimeSpentSeconds: int(w.Spent.Seconds()),
+	}
+	if _, _, err := s.jc.Issue.AddWorklogRecordWithContext(ctx, issue.Key, record); err != nil {
+		return fmt.Errorf("error adding worklog: %w", err)
+	}
+	return nil
+}
+
+type tempoWorklogs struct {
+	jc     *jira.Client
+	config *TempoConfig
+	self   string
+}
+
+type tempoAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (t *tempoWorklogs) request(ctx context.Context, method, path string, body interface{}, into interface{}) error {
+	headers := map[string]string{"Authorization": "Bearer " + t.config.Token}
+	if err := incidentRequest(ctx, method, strings.TrimSuffix(valueOr(t.config.URL, tempoURL), "/")+path, headers, body, into); err != nil {
+		return fmt.Errorf("tempo: %w", err)
+	}
+	return nil
+}
+
+// author is the account id Tempo Cloud logs time against. Jira Server has
+// no account ids and its Tempo has a different api, which isn't supported.
+func (t *tempoWorklogs) author(ctx context.Context) (string, error) {
+	if t.self != "" {
+		return t.self, nil
+	}
+	self, _, err := t.jc.User.GetSelfWithContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error getting user: %w", err)
+	}
+	if self.AccountID == "" {
+		return "", fmt.Errorf("tempo: only Tempo Cloud is supported, %s has no account id", self.Name)
+	}
+	t.self = self.AccountID
+	return t.self, nil
+}
+
+func (t *tempoWorklogs) accountAttribute() string {
+	return valueOr(t.config.AccountAttribute, tempoAccountAttribute)
+}
+
+// Tempo identifies issues by id rather than key.
+func (t *tempoWorklogs) issueID(ctx context.Context, issue *jira.Issue) (string, error) {
+	if issue.ID != "" {
+		return issue.ID, nil
+	}
+	found, _, err := t.jc.Issue.GetWithContext(ctx, issue.Key, &jira.GetQueryOptions{Fields: "summary"})
+	if err != nil {
+		return "", fmt.Errorf("error getting issue: %w", err)
+	}
+	issue.ID = found.ID
+	return issue.ID, nil
+}
+
+func (t *tempoWorklogs) Worklogs(ctx context.Context, issue *jira.Issue) ([]*Worklog, error) {
+	if _, err := t.author(ctx); err != nil {
+		return nil, err
+	}
+
+	id, err := t.issueID(ctx, issue)
+	if err != nil {
+		return nil, err
+	}
+
+	worklogs := make([]*Worklog, 0)
+	for offset := 0; ; offset += tempoPageSize {
+		found := struct {
+			Results []struct {
+				Spent       int    `json:"timeSpentSeconds"`
+				StartDate   string `json:"startDate"`
+				StartTime   string `json:"startTime"`
+				Description string `json:"description"`
+				Attributes  struct {
+					Values []*tempoAttribute `json:"values"`
+				} `json:"attributes"`
+			} `json:"results"`
+		}{}
+		path := fmt.Sprintf("/worklogs/issue/%s?limit=%d&offset=%d", url.PathEscape(id), tempoPageSize, offset)
+		if err := t.request(ctx, "GET", path, nil, &found); err != nil {
+			return nil, err
+		}
+
+		for _, r := range found.Results {
+			started, _ := time.ParseInLocation("2006-01-02 15:04:05", r.StartDate+" "+valueOr(r.StartTime, "00:00:00"), time.Local)
+			w := &Worklog{
+				Started:    started,
+				Spent:      time.Duration(r.Spent) * time.Second,
+				Comment:    r.Description,
+				Attributes: make(map[string]string),
+			}
+			for _, a := range r.Attributes.Values {
+				w.Attributes[a.Key] = a.Value
+			}
+			worklogs = append(worklogs, w)
+		}
+
+		if len(found.Results) < tempoPageSize {
+			return worklogs, nil
+		}
+	}
+}
+
+func (t *tempoWorklogs) AddWorklog(ctx context.Context, issue *jira.Issue, w *Worklog) error {
+	author, err := t.author(ctx)
+	if err != nil {
+		return err
+	}
+
+	id, err := t.issueID(ctx, issue)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]string)
+	for k, v := range t.config.Attributes {
+		values[k] = v
+	}
+	if t.config.Account != "" {
+		values[t.accountAttribute()] = t.config.Account
+	}
+	for k, v := range w.Attributes {
+		values[k] = v
+	}
+	attributes := make([]*tempoAttribute, 0)
+	for k, v := range values {
+		attributes = append(attributes, &tempoAttribute{Key: k, Value: v})
+	}
+
+	var issueID int
+	if _, err := fmt.Sscanf(id, "%d", &issueID); err != nil {
+		return fmt.Errorf("tempo: unexpected issue id %s", id)
+	}
+
+	body := map[string]interface{}{
+		"issueId":          issueID,
+		"authorAccountId":  author,
+		"timeSpentSeconds": int(w.Spent.Seconds()),
+		"startDate":        w.Started.Local().Format("2006-01-02"),
+		"startTime":        w.Started.Local().Format("15:04:05"),
+		"description":      valueOr(w.Comment, "Working on issue "+issue.Key),
+		"attributes":       attributes,
+	}
+
+	return t.request(ctx, "POST", "/worklogs", body, nil)
+}
+
+// account is the account most of the time was logged against.
+func (t *tempoWorklogs) account(worklogs []*Worklog) string {
+	spent := make(map[string]time.Duration)
+	for _, w := range worklogs {
+		spent[valueOr(w.Attributes[t.accountAttribute()], "(none)")] += w.Spent
+	}
+	account := "(none)"
+	for name, d := range spent {
+		if d > spent[account] || (d == spent[account] && name < account) {
+			account = name
+		}
+	}
+	return account
+}
+
+// parseAttributes reads key=value pairs separated by commas.
+func parseAttributes(text string) (map[string]string, error) {
+	attributes := make(map[string]string)
+	for _, pair := range strings.Split(text, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("expected key=value attribute, got '%s'", pair)
+		}
+		attributes[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return attributes, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func startOfWeek(t time.Time) time.Time {
+	t = t.Local()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func linearTrend(values []float64) (float64, float64) {
+	n := float64(len(values))
+	if n < 2 {
+		return 0, 0
+	}
+	var sx, sy, sxy, sxx float64
+	for i, y := range values {
+		x := float64(i)
+		sx += x
+		sy += y
+		sxy += x * y
+		sxx += x * x
+	}
+	slope := (n*sxy - sx*sy) / (n*sxx - sx*sx)
+	return slope, (sy - slope*sx) / n
+}
+
+func reportThroughputCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("report throughput", flag.ExitOnError)
+	weeks := flags.Int("weeks", 12, "number of weeks to include")
+	jql := flags.String("jql", "", "restrict to issues matching this query")
+	by := flags.String("by", "", "also break down each week (component, assignee, type)")
+	chart := flags.String("chart", "", "also write a chart to this .svg or .png file")
+	flags.Parse(args)
+
+	first := startOfWeek(time.Now()).AddDate(0, 0, -7*(*weeks-1))
+
+	search := reportSearch(options, fmt.Sprintf("(resolution IS NOT EMPTY) AND (resolved >= -%dw)", *weeks), *jql)
+
+	counts := make([]float64, *weeks)
+	breakdown := make([]map[string]int, *weeks)
+	for i := range breakdown {
+		breakdown[i] = make(map[string]int)
+	}
+
+	err := searchIssues(jc, options, search, &jira.SearchOptions{MaxResults: 100, Fields: withFields([]string{"resolutiondate"}, *by)}, func(issue jira.Issue) error {
+		week := int(math.Round(startOfWeek(time.Time(issue.Fields.Resolutiondate)).Sub(first).Hours() / 24 / 7))
+		if week < 0 || week >= *weeks {
+			return nil
+		}
+		counts[week]++
+		if *by != "" {
+			for _, g := range issueGroups(&issue, *by) {
+				breakdown[week][g]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	slope, intercept := linearTrend(counts)
+
+	fmt.Printf("%-10s %5s %6s\n", "WEEK", "N", "TREND")
+	for i, n := range counts {
+		week := first.AddDate(0, 0, 7*i)
+		line := fmt.Sprintf("%-10s %5.0f %6.1f %s", week.Format("2006/01/02"), n, intercept+slope*float64(i), strings.Repeat("#", int(n)))
+		if *by != "" && len(breakdown[i]) > 0 {
+			names := make([]string, 0)
+			for name := range breakdown[i] {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			parts := make([]string, 0)
+			for _, name := range names {
+				parts = append(parts, fmt.Sprintf("%s=%d", name, breakdown[i][name]))
+			}
+			line += "  " + strings.Join(parts, " ")
+		}
+		fmt.Println(line)
+	}
+
+	direction := "flat"
+	if slope > 0.05 {
+		direction = "rising"
+	} else if slope < -0.05 {
+		direction = "falling"
+	}
+
+	fmt.Printf("\ntrend %+.2f issues/week per week (%s)\n", slope, direction)
+
+	if *chart != "" {
+		c := &Chart{
+			Title:  "Issues resolved per week",
+			Series: []*ChartSeries{{Name: "resolved", Values: counts}, {Name: "trend", Line: true}},
+		}
+		for i := range counts {
+			c.Labels = append(c.Labels, first.AddDate(0, 0, 7*i).Format("01/02"))
+			c.Series[1].Values = append(c.Series[1].Values, intercept+slope*float64(i))
+		}
+		return writeChart(c, *chart)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/reports"
+)
+
+const timelineWidth = 40
+
+func timelineCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("timeline", flag.ExitOnError)
+	history := flags.Bool("history", false, "also list each status change")
+	positional := parseArgs(flags, args)
+
+	key, _, err := resolveIssueKey(options, positional)
+	if err != nil {
+		return err
+	}
+
+	issue, err := getIssue(jc, options, key)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	durations := reports.TimeInStatuses(issue, now)
+
+	var longest, total time.Duration
+	for _, d := range durations {
+		total += d.Duration
+		if d.Duration > longest {
+			longest = d.Duration
+		}
+	}
+
+	fmt.Printf("%s '%s' (%s)\n\n", issue.Key, issue.Fields.Summary, issue.Fields.Status.Name)
+
+	for _, d := range durations {
+		width := 0
+		if longest > 0 {
+			width = int(float64(timelineWidth) * float64(d.Duration) / float64(longest))
+		}
+		fmt.Printf("%-20s %8s %5.1f%% %s\n", d.Status, reports.FormatDays(d.Duration), 100*float64(d.Duration)/float64(total), strings.Repeat("#", width))
+	}
+
+	fmt.Printf("%-20s %8s\n", "total", reports.FormatDays(total))
+
+	if *history {
+		fmt.Println()
+		fmt.Printf("%-16s %s\n", time.Time(issue.Fields.Created).Local().Format("2006/01/02 15:04"), "created")
+		for _, c := range reports.StatusChanges(issue) {
+			fmt.Printf("%-16s %s -> %s\n", c.Time.Local().Format("2006/01/02 15:04"), c.From, c.To)
+		}
+	}
+
+	return nil
+}
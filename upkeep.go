@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+	"github.com/jlewallen/jira-ops/jiraops/client"
+)
+
+type Upkeep struct {
+	issues    client.Issues
+	config    *Config
+	options   *Options
+	rules     Rules
+	labelers  []*Labeler
+	noise     []*NoiseFilter
+	templates []*DescriptionTemplate
+	names     *AttachmentNames
+	links     *LinkChecker
+	audit     *AuditLog
+	output    sync.Mutex
+}
+
+func (u *Upkeep) process(f *pages.Fetched) error {
+	issues, options := u.issues, u.options
+	i, issue := f.Search, f.Issue
+	enabled := !options.DryRun
+
+	var out bytes.Buffer
+	defer func() {
+		u.output.Lock()
+		defer u.output.Unlock()
+		os.Stdout.Write(out.Bytes())
+	}()
+
+	if f.Err != nil {
+		return fmt.Errorf("error getting issue: %+v", f.Err)
+	}
+
+	if u.config.skipped(issue) {
+		log.Printf("[%s] skipping, opted out", issue.Key)
+		return nil
+	}
+
+	if u.links != nil {
+		u.links.checkIssue(issue)
+	}
+
+	if err := autoLabel(issues, u.labelers, issue, options, u.audit, &out); err != nil {
+		return err
+	}
+
+	if err := cleanupNoise(issues, u.noise, issue, options, u.audit, &out); err != nil {
+		return err
+	}
+
+	if u.names != nil {
+		u.names.report(issue, &out)
+	}
+
+	newDescription, applied, err := u.rules.apply(issue, i.Fields.Description)
+	if err != nil {
+		return fmt.Errorf("error applying rules: %w", err)
+	}
+
+	newDescription, inserted := applyDescriptionTemplates(u.templates, issue, newDescription, &out)
+	applied = append(applied, inserted...)
+
+	if newDescription != i.Fields.Description {
+		fmt.Fprintf(&out, "%-8s %v (%s)\n", i.Key, i.Fields.Summary, strings.Join(applied, ", "))
+		fmt.Fprint(&out, unifiedDiff(i.Key+"/description", i.Fields.Description, newDescription, options.DiffContext))
+
+		update := map[string]interface{}{
+			"fields": map[string]interface{}{
+				"description": newDescription,
+			},
+		}
+
+		if enabled {
+			if _, err := issues.UpdateIssueWithContext(options.ctx, i.Key, update); err != nil {
+				return fmt.Errorf("error updating description: %w", err)
+			}
+			if err := u.audit.record(i.Key, "description", applied, i.Fields.Description, newDescription); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, c := range issue.Fields.Comments.Comments {
+		newBody, applied, err := u.rules.apply(issue, c.Body)
+		if err != nil {
+			return fmt.Errorf("error applying rules: %w", err)
+		}
+
+		if newBody != c.Body {
+			fmt.Fprintf(&out, "%-8s %v (%s)\n", i.Key, i.Fields.Summary, strings.Join(applied, ", "))
+			fmt.Fprint(&out, unifiedDiff(i.Key+"/comment-"+c.ID, c.Body, newBody, options.DiffContext))
+
+			if enabled {
+				before := c.Body
+				c.Body = newBody
+				if _, _, err := issues.UpdateCommentWithContext(options.ctx, i.Key, c); err != nil {
+					return fmt.Errorf("error updating: %w", err)
+				}
+				if err := u.audit.record(i.Key, "comment-"+c.ID, applied, before, newBody); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func newUpkeep(issues client.Issues, config *Config, options *Options) (*Upkeep, error) {
+	u := &Upkeep{
+		issues:  issues,
+		config:  config,
+		options: options,
+	}
+
+	var err error
+
+	if u.rules, err = loadRules(options.ctx, issues, config); err != nil {
+		return nil, err
+	}
+
+	if u.labelers, err = loadLabelers(config); err != nil {
+		return nil, err
+	}
+
+	if u.noise, err = loadNoiseFilters(config); err != nil {
+		return nil, err
+	}
+
+	if u.templates, err = loadDescriptionTemplates(config); err != nil {
+		return nil, err
+	}
+
+	if u.names, err = loadAttachmentNames(config); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// issue applies upkeep to a single issue, outside of a search.
+func (u *Upkeep) issue(ctx context.Context, key string) error {
+	issue, _, err := u.issues.GetWithContext(ctx, key, nil)
+	if err != nil {
+		return fmt.Errorf("error getting issue: %w", err)
+	}
+	return u.process(&pages.Fetched{Search: issue, Issue: issue})
+}
+
+func runUpkeep(issues client.Issues, config *Config, options *Options) error {
+	u, err := newUpkeep(issues, config, options)
+	if err != nil {
+		return err
+	}
+
+	state, err := loadState()
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+
+	search, err := makeUpkeepSearch(state, options, started)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("upkeep: %s", search)
+
+	enabled := !options.DryRun
+
+	var unchanged int32
+	var keep func(*jira.Issue) bool
+
+	var processedState *ProcessedState
+	if enabled && options.Only == "" && !options.CheckLinks && !options.Reprocess {
+		processedState, err = loadProcessedState(config)
+		if err != nil {
+			return err
+		}
+
+		keep = func(issue *jira.Issue) bool {
+			if processedState.unchanged(issue) {
+				atomic.AddInt32(&unchanged, 1)
+				return false
+			}
+			return true
+		}
+
+		defer func() {
+			if err := processedState.save(); err != nil {
+				log.Printf("saving processed state: %v", err)
+			}
+		}()
+	}
+
+	if enabled {
+		u.audit, err = openAuditLog()
+		if err != nil {
+			return err
+		}
+
+		defer u.audit.close()
+	}
+
+	if options.CheckLinks {
+		u.links, err = newLinkChecker()
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			u.links.report()
+			if err := u.links.close(); err != nil {
+				log.Printf("saving link cache: %v", err)
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithCancel(options.ctx)
+	defer cancel()
+
+	stream, wait := pages.Stream(ctx, issues, search, &jira.SearchOptions{Fields: processedFields}, streamBuffer)
+
+	var processed int32
+	err = forEachIssue(pages.Fetch(ctx, issues, stream, options.Fetchers, keep), options.Workers, func(f *pages.Fetched) error {
+		if err := u.process(f); err != nil {
+			return err
+		}
+		if processedState != nil {
+			processedState.record(f.Search)
+		}
+		atomic.AddInt32(&processed, 1)
+		return nil
+	})
+	if options.ctx.Err() != nil {
+		log.Printf("upkeep: interrupted after %d issues, last run not saved", atomic.LoadInt32(&processed))
+		return options.ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	if err := wait(); err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	log.Printf("upkeep: %d issues processed, %d unchanged since last processed", processed, unchanged)
+
+	// --only names the issues to touch, stale issues outside it are left alone.
+	if options.Only == "" {
+		if err := nagStaleIssues(issues, config, options, u.audit); err != nil {
+			return err
+		}
+	}
+
+	if enabled {
+		state.UpkeepLastRun = &started
+		if err := state.save(); err != nil {
+			return fmt.Errorf("saving state: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func upkeepCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	return runUpkeep(jc.Issue, config, options)
+}
+
+func makeUpkeepSearch(state *State, options *Options, now time.Time) (string, error) {
+	clauses := []string{"(resolution IS EMPTY)"}
+	if options.JQL != "" {
+		clauses = []string{fmt.Sprintf("(%s)", options.JQL)}
+	}
+
+	if options.projectSet {
+		clauses = append(clauses, fmt.Sprintf("(project = '%s')", options.Project))
+	}
+
+	if options.Only != "" {
+		keys := make([]string, 0)
+		for _, key := range strings.Split(options.Only, ",") {
+			keys = append(keys, fmt.Sprintf("'%s'", strings.TrimSpace(key)))
+		}
+		clauses = append(clauses, fmt.Sprintf("(key IN (%s))", strings.Join(keys, ", ")))
+		return strings.Join(clauses, " AND ") + " ORDER BY updated DESC", nil
+	}
+
+	switch options.UpdatedSince {
+	case "", "all":
+	case "last":
+		if state.UpkeepLastRun != nil {
+			clauses = append(clauses, fmt.Sprintf("(updated >= '%s')", state.UpkeepLastRun.Format(jqlTimeLayout)))
+		}
+	default:
+		since, err := parseSince(options.UpdatedSince, now)
+		if err != nil {
+			return "", err
+		}
+		clauses = append(clauses, fmt.Sprintf("(updated >= '%s')", since.Format(jqlTimeLayout)))
+	}
+
+	return strings.Join(clauses, " AND ") + " ORDER BY updated DESC", nil
+}
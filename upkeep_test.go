@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/jiraops/jiratest"
+)
+
+func newUpkeepFake() *jiratest.Fake {
+	return jiratest.NewFake(&jira.Issue{
+		Key: "FK-1",
+		Fields: &jira.IssueFields{
+			Summary:     "Upload fails",
+			Description: "Fails after teh update.",
+			Status:      &jira.Status{Name: "In Progress"},
+			Comments: &jira.Comments{Comments: []*jira.Comment{
+				{ID: "1", Body: "build 12 failed", Author: jira.User{Name: "ci"}},
+				{ID: "2", Body: "teh logs are attached", Author: jira.User{Name: "jacob"}},
+				{ID: "3", Body: "build 13 failed", Author: jira.User{Name: "ci"}},
+			}},
+		},
+	})
+}
+
+func newUpkeepConfig() *Config {
+	return &Config{
+		Rules:  []*RuleConfig{{Name: "spelling", Type: "regex", Pattern: `\bteh\b`, Replace: "the"}},
+		Labels: []*LabelConfig{{Label: "uploads", Patterns: []string{"upload"}}},
+		Noise:  []*NoiseConfig{{Name: "ci", Authors: []string{"ci"}, Action: "delete"}},
+	}
+}
+
+func TestUpkeepIssue(t *testing.T) {
+	fake := newUpkeepFake()
+	options := &Options{ctx: context.Background()}
+
+	u, err := newUpkeep(fake, newUpkeepConfig(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.issue(options.ctx, "FK-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	issue := fake.Issue("FK-1")
+	if issue.Fields.Description != "Fails after the update." {
+		t.Errorf("description: %q", issue.Fields.Description)
+	}
+	if !hasLabel(issue, "uploads") {
+		t.Errorf("labels: %v", issue.Fields.Labels)
+	}
+
+	comments := issue.Fields.Comments.Comments
+	if len(comments) != 2 || comments[0].ID != "2" || comments[1].ID != "3" {
+		t.Fatalf("comments: %+v", comments)
+	}
+	if comments[0].Body != "the logs are attached" {
+		t.Errorf("comment: %q", comments[0].Body)
+	}
+}
+
+func TestUpkeepIssueDryRun(t *testing.T) {
+	fake := newUpkeepFake()
+	options := &Options{ctx: context.Background(), DryRun: true}
+
+	u, err := newUpkeep(fake, newUpkeepConfig(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.issue(options.ctx, "FK-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.Calls) != 1 || fake.Calls[0] != "get FK-1" {
+		t.Errorf("calls: %v", fake.Calls)
+	}
+
+	issue := fake.Issue("FK-1")
+	if issue.Fields.Description != "Fails after teh update." || len(issue.Fields.Comments.Comments) != 3 || len(issue.Fields.Labels) != 0 {
+		t.Errorf("changed in a dry run: %+v", issue.Fields)
+	}
+}
+
+func TestChangeIssueStatus(t *testing.T) {
+	fake := newUpkeepFake()
+	fake.Transitions = []jira.Transition{
+		{ID: "21", Name: "Review", To: jira.Status{Name: "Awaiting QA"}},
+		{ID: "31", Name: "Done", To: jira.Status{Name: "Done"}},
+	}
+
+	issue := fake.Issue("FK-1")
+
+	if err := changeIssueStatus(context.Background(), fake, issue, "Awaiting QA"); err != nil {
+		t.Fatal(err)
+	}
+	if status := fake.Issue("FK-1").Fields.Status.Name; status != "Awaiting QA" {
+		t.Errorf("status: %s", status)
+	}
+
+	if err := changeIssueStatus(context.Background(), fake, issue, "Closed"); err == nil {
+		t.Error("expected an error without a transition to Closed")
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+)
+
+var watchFields = []string{"summary", "status", "comment", "attachment", "updated"}
+
+type WatchedIssue struct {
+	Status      string
+	Comments    map[string]bool
+	Attachments map[string]bool
+}
+
+func newWatchedIssue(issue *jira.Issue) *WatchedIssue {
+	w := &WatchedIssue{
+		Comments:    make(map[string]bool),
+		Attachments: make(map[string]bool),
+	}
+	if issue.Fields.Status != nil {
+		w.Status = issue.Fields.Status.Name
+	}
+	if issue.Fields.Comments != nil {
+		for _, c := range issue.Fields.Comments.Comments {
+			w.Comments[c.ID] = true
+		}
+	}
+	for _, a := range issue.Fields.Attachments {
+		w.Attachments[a.ID] = true
+	}
+	return w
+}
+
+func truncate(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length]) + "..."
+}
+
+func isSelf(self *jira.User, author *jira.User) bool {
+	if self == nil || author == nil {
+		return false
+	}
+	if self.AccountID != "" {
+		return self.AccountID == author.AccountID
+	}
+	return self.Name == author.Name
+}
+
+// changes describes what happened to an issue since it was last seen,
+// leaving out comments and attachments added by self.
+func (w *WatchedIssue) changes(issue *jira.Issue, self *jira.User) []string {
+	messages := make([]string, 0)
+	seen := newWatchedIssue(issue)
+	if seen.Status != w.Status {
+		messages = append(messages, fmt.Sprintf("%s -> %s", w.Status, seen.Status))
+	}
+	if issue.Fields.Comments != nil {
+		for _, c := range issue.Fields.Comments.Comments {
+			if !w.Comments[c.ID] && !isSelf(self, &c.Author) {
+				messages = append(messages, fmt.Sprintf("%s commented: %s", c.Author.DisplayName, truncate(c.Body, 120)))
+			}
+		}
+	}
+	for _, a := range issue.Fields.Attachments {
+		if !w.Attachments[a.ID] && !isSelf(self, a.Author) {
+			messages = append(messages, fmt.Sprintf("attached %s", a.Filename))
+		}
+	}
+	return messages
+}
+
+type Watcher struct {
+	jc       *jira.Client
+	notifier Notifier
+	self     *jira.User
+	search   string
+	issues   map[string]*WatchedIssue
+	last     time.Time
+}
+
+func (w *Watcher) poll(ctx context.Context, initial bool) error {
+	started := time.Now()
+
+	search := w.search
+	if initial {
+		search += " AND (resolution IS EMPTY)"
+	} else {
+		search += fmt.Sprintf(" AND (updated >= '%s')", w.last.Add(-time.Minute).Format(jqlTimeLayout))
+	}
+
+	err := pages.Each(ctx, w.jc.Issue, search, &jira.SearchOptions{MaxResults: 100, Fields: watchFields}, func(issue *jira.Issue) error {
+		previous, ok := w.issues[issue.Key]
+		w.issues[issue.Key] = newWatchedIssue(issue)
+		if !ok {
+			return nil
+		}
+		for _, message := range previous.changes(issue, w.self) {
+			log.Printf("[%s] %s", issue.Key, message)
+			if err := w.notifier.Notify(ctx, fmt.Sprintf("%s %s", issue.Key, issue.Fields.Summary), message); err != nil {
+				log.Printf("[%s] notify: %v", issue.Key, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	w.last = started
+
+	return nil
+}
+
+func notifyCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("notify", flag.ExitOnError)
+	interval := flags.Duration("interval", 2*time.Minute, "time between checks")
+	jql := flags.String("jql", "", "restrict to issues matching this query")
+	flags.Parse(args)
+
+	notifier, err := newNotifier(options)
+	if err != nil {
+		return err
+	}
+	if notifier == nil {
+		notifier = &desktopNotifier{}
+	}
+
+	self, _, err := jc.User.GetSelfWithContext(options.ctx)
+	if err != nil {
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	search := "(assignee = currentUser())"
+	if *jql != "" {
+		search += fmt.Sprintf(" AND (%s)", *jql)
+	}
+
+	w := &Watcher{
+		jc:       jc,
+		notifier: notifier,
+		self:     self,
+		search:   search,
+		issues:   make(map[string]*WatchedIssue),
+	}
+
+	if err := w.poll(options.ctx, true); err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	log.Printf("notify: watching %d issues every %v", len(w.issues), *interval)
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-options.ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := w.poll(options.ctx, false); err != nil && options.ctx.Err() == nil {
+				log.Printf("notify: %v", err)
+			}
+		}
+	}
+}
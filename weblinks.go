@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func deleteRemoteLink(ctx context.Context, jc *jira.Client, key string, id int) error {
+	req, _ := jc.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("/rest/api/2/issue/%s/remotelink/%d", key, id), nil)
+	_, err := jc.Do(req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// findLinkedIssue returns an issue with a remote link whose global id is
+// globalID, so importing twice doesn't create a second issue.
+func findLinkedIssue(ctx context.Context, jc *jira.Client, globalID string) (*jira.Issue, error) {
+	search := fmt.Sprintf(`issue IN issuesWithRemoteLinksByGlobalId("%s")`, globalID)
+	issues, _, err := jc.Issue.SearchWithContext(ctx, search, &jira.SearchOptions{MaxResults: 1, Fields: []string{"summary"}})
+	if err != nil {
+		return nil, err
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	return &issues[0], nil
+}
+
+func webLinkAddCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("weblink add", flag.ExitOnError)
+	title := flags.String("title", "", "title of the link")
+	relationship := flags.String("relationship", "", "relationship shown above the link")
+	positional := parseArgs(flags, args)
+
+	key, rest, err := resolveIssueKey(options, positional)
+	if err != nil {
+		return err
+	}
+
+	if len(rest) != 1 {
+		return fmt.Errorf("usage: weblink add [FK-123] <url> [--title <title>]")
+	}
+
+	url := rest[0]
+	if *title == "" {
+		*title = url
+	}
+
+	link := &jira.RemoteLink{
+		GlobalID:     url,
+		Relationship: *relationship,
+		Object: &jira.RemoteLinkObject{
+			URL:   url,
+			Title: *title,
+		},
+	}
+
+	log.Printf("[%s] linking %s", key, url)
+
+	if options.DryRun {
+		return nil
+	}
+
+	if _, _, err := jc.Issue.AddRemoteLinkWithContext(options.ctx, key, link); err != nil {
+		return fmt.Errorf("error adding remote link: %w", err)
+	}
+
+	return nil
+}
+
+func webLinkListCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	key, _, err := resolveIssueKey(options, args)
+	if err != nil {
+		return err
+	}
+
+	links, _, err := jc.Issue.GetRemoteLinksWithContext(options.ctx, key)
+	if err != nil {
+		return fmt.Errorf("error getting remote links: %w", err)
+	}
+
+	for _, l := range *links {
+		if l.Object == nil {
+			continue
+		}
+		fmt.Printf("%-8d %s\n", l.ID, l.Object.URL)
+		if l.Object.Title != "" && l.Object.Title != l.Object.URL {
+			fmt.Printf("%-8s %s\n", "", l.Object.Title)
+		}
+	}
+
+	return nil
+}
+
+func webLinkDeleteCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	key, rest, err := resolveIssueKey(options, args)
+	if err != nil {
+		return err
+	}
+
+	if len(rest) != 1 {
+		return fmt.Errorf("usage: weblink delete [FK-123] <id|url>")
+	}
+
+	links, _, err := jc.Issue.GetRemoteLinksWithContext(options.ctx, key)
+	if err != nil {
+		return fmt.Errorf("error getting remote links: %w", err)
+	}
+
+	id, _ := strconv.Atoi(rest[0])
+
+	deleted := 0
+	for _, l := range *links {
+		if l.ID != id && (l.Object == nil || l.Object.URL != rest[0]) {
+			continue
+		}
+
+		log.Printf("[%s] deleting link %d", key, l.ID)
+
+		if options.DryRun {
+			continue
+		}
+
+		if err := deleteRemoteLink(options.ctx, jc, key, l.ID); err != nil {
+			return fmt.Errorf("error deleting remote link: %w", err)
+		}
+
+		deleted += 1
+	}
+
+	if deleted == 0 && !options.DryRun {
+		return fmt.Errorf("no link matching %s on %s", rest[0], key)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jlewallen/jira-ops/internal/pages"
+)
+
+func localIssueBranches(projects []string) (map[string][]string, error) {
+	output, err := git("for-each-ref", "--format=%(refname:short)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make(map[string][]string)
+	for _, branch := range strings.Split(output, "\n") {
+		for _, key := range (&Commit{Subject: branch}).issueKeys(projects) {
+			branches[key] = append(branches[key], branch)
+		}
+	}
+
+	return branches, nil
+}
+
+func wipCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	projects := config.projects(options)
+
+	branches, err := localIssueBranches(projects)
+	if err != nil {
+		return err
+	}
+
+	issues := make(map[string]*jira.Issue)
+
+	if len(branches) > 0 {
+		search := fmt.Sprintf("key IN (%s)", strings.Join(sortedKeys(keysOf(branches)), ", "))
+		found, _, err := jc.Issue.SearchWithContext(options.ctx, search, &jira.SearchOptions{MaxResults: len(branches), ValidateQuery: "warn", Fields: withFields(listingFields, "resolution")})
+		if err != nil {
+			return fmt.Errorf("error getting issues: %w", err)
+		}
+		for i := range found {
+			issues[found[i].Key] = &found[i]
+		}
+	}
+
+	for _, key := range sortedKeys(keysOf(branches)) {
+		issue, ok := issues[key]
+		for _, branch := range branches[key] {
+			switch {
+			case !ok:
+				fmt.Printf("%-8s %-20s %s (issue not found)\n", key, "", branch)
+			case issue.Fields.Resolution != nil:
+				fmt.Printf("%-8s %-20s %s (resolved, branch can be deleted)\n", key, issue.Fields.Status.Name, branch)
+			default:
+				fmt.Printf("%-8s %-20s %s\n", key, issue.Fields.Status.Name, branch)
+			}
+		}
+	}
+
+	search := fmt.Sprintf("(project IN (%s)) AND (status = 'In Progress') AND (assignee = currentUser())", quoteList(projects))
+	progress, err := pages.All(options.ctx, jc.Issue, search, &jira.SearchOptions{Fields: listingFields})
+	if err != nil {
+		return fmt.Errorf("error getting issues: %w", err)
+	}
+
+	for _, i := range progress {
+		if _, ok := branches[i.Key]; !ok {
+			fmt.Printf("%-8s %-20s '%s' (no branch)\n", i.Key, i.Fields.Status.Name, i.Fields.Summary)
+		}
+	}
+
+	return nil
+}
+
+func keysOf(m map[string][]string) map[string]bool {
+	keys := make(map[string]bool)
+	for k := range m {
+		keys[k] = true
+	}
+	return keys
+}
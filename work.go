@@ -0,0 +1,158 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const defaultDoneStatus = "Ready for Deploy"
+
+var issueKeyRegexp = regexp.MustCompile(`(?i)\b([a-z][a-z0-9]+-[0-9]+)\b`)
+var issueNumberRegexp = regexp.MustCompile(`^[0-9]+$`)
+
+func currentBranchIssueKey() (string, error) {
+	branch, err := git("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	m := issueKeyRegexp.FindStringSubmatch(branch)
+	if m == nil {
+		return "", fmt.Errorf("no issue key in branch '%s'", branch)
+	}
+
+	return strings.ToUpper(m[1]), nil
+}
+
+func looksLikeIssue(arg string) bool {
+	return issueNumberRegexp.MatchString(arg) || issueKeyRegexp.FindString(arg) == arg
+}
+
+func resolveIssueKey(options *Options, args []string) (string, []string, error) {
+	if len(args) > 0 && looksLikeIssue(args[0]) {
+		return issueKeyFromArg(options, args[0]), args[1:], nil
+	}
+
+	key, err := currentBranchIssueKey()
+	if err != nil {
+		return "", args, fmt.Errorf("no issue given and %w", err)
+	}
+
+	return key, args, nil
+}
+
+func getResolvedIssue(jc *jira.Client, options *Options, args []string) (*jira.Issue, []string, error) {
+	key, rest, err := resolveIssueKey(options, args)
+	if err != nil {
+		return nil, rest, err
+	}
+
+	issue, _, err := jc.Issue.GetWithContext(options.ctx, key, nil)
+	if err != nil {
+		return nil, rest, fmt.Errorf("error getting issue: %w", err)
+	}
+
+	return issue, rest, nil
+}
+
+func pullCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	issue, _, err := getResolvedIssue(jc, options, args)
+	if err != nil {
+		return err
+	}
+	return pullIssue(options.ctx, jc, issue)
+}
+
+func doneCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	issue, _, err := getResolvedIssue(jc, options, args)
+	if err != nil {
+		return err
+	}
+
+	status := config.DoneStatus
+	if status == "" {
+		status = defaultDoneStatus
+	}
+
+	return changeIssueStatus(options.ctx, jc.Issue, issue, status)
+}
+
+func commentCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	key, rest, err := resolveIssueKey(options, args)
+	if err != nil {
+		return err
+	}
+
+	body := strings.TrimSpace(strings.Join(rest, " "))
+	if body == "" {
+		return fmt.Errorf("usage: comment [issue] <text>")
+	}
+
+	if _, _, err := jc.Issue.AddCommentWithContext(options.ctx, key, &jira.Comment{Body: body}); err != nil {
+		return fmt.Errorf("error adding comment: %w", err)
+	}
+
+	return nil
+}
+
+func logCommand(jc *jira.Client, config *Config, options *Options, args []string) error {
+	flags := flag.NewFlagSet("log", flag.ExitOnError)
+	fromGit := flags.Bool("from-git", false, "estimate time spent from my commits on the issue's branch")
+	base := flags.String("base", "origin/HEAD", "branch the issue's branch was started from")
+	estimate := &WorkEstimate{}
+	flags.DurationVar(&estimate.Gap, "gap", 2*time.Hour, "commits further apart than this start a new session")
+	flags.DurationVar(&estimate.Lead, "lead", 30*time.Minute, "time credited before the first commit of a session")
+	flags.DurationVar(&estimate.Maximum, "max-session", 4*time.Hour, "longest time credited to a single session")
+	flags.DurationVar(&estimate.Rounding, "round", 15*time.Minute, "round each session up to a multiple of this")
+	account := flags.String("account", "", "tempo account to log against, instead of the configured default")
+	attributeFlag := flags.String("attributes", "", "tempo work attributes as key=value,key=value")
+	positional := parseArgs(flags, args)
+
+	key, rest, err := resolveIssueKey(options, positional)
+	if err != nil {
+		return err
+	}
+
+	store, err := newWorklogStore(jc, config)
+	if err != nil {
+		return err
+	}
+
+	attributes, err := parseAttributes(*attributeFlag)
+	if err != nil {
+		return err
+	}
+	if *account != "" {
+		if config.Tempo == nil {
+			return fmt.Errorf("--account requires tempo")
+		}
+		attributes[valueOr(config.Tempo.AccountAttribute, tempoAccountAttribute)] = *account
+	}
+
+	if *fromGit {
+		return logFromGit(store, options, key, *base, estimate, attributes)
+	}
+
+	if len(rest) == 0 {
+		return fmt.Errorf("usage: log [issue] <duration> [comment]")
+	}
+
+	spent, err := time.ParseDuration(rest[0])
+	if err != nil {
+		return fmt.Errorf("invalid duration: %s", rest[0])
+	}
+
+	worklog := &Worklog{
+		Started:    time.Now().Add(-spent),
+		Spent:      spent,
+		Comment:    strings.Join(rest[1:], " "),
+		Attributes: attributes,
+	}
+
+	return store.AddWorklog(options.ctx, &jira.Issue{Key: key}, worklog)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/jlewallen/jira-ops/internal/pages"
+)
+
+const streamBuffer = 100
+
+func forEachIssue(fetched <-chan *pages.Fetched, workers int, fn func(f *pages.Fetched) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	queue := make(chan *pages.Fetched)
+
+	var lock sync.Mutex
+	var failed error
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range queue {
+				if err := fn(f); err != nil {
+					lock.Lock()
+					if failed == nil {
+						failed = err
+					}
+					lock.Unlock()
+				}
+			}
+		}()
+	}
+
+	for f := range fetched {
+		lock.Lock()
+		stop := failed != nil
+		lock.Unlock()
+		if stop {
+			break
+		}
+		queue <- f
+	}
+
+	close(queue)
+	wg.Wait()
+
+	return failed
+}
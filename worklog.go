@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type WorkSession struct {
+	Started time.Time
+	Spent   time.Duration
+	First   string
+	Last    string
+}
+
+type WorkEstimate struct {
+	Gap      time.Duration
+	Lead     time.Duration
+	Maximum  time.Duration
+	Rounding time.Duration
+}
+
+func (s *WorkSession) comment() string {
+	if s.First == s.Last {
+		return fmt.Sprintf("jira-ops: estimated from commit %s", s.First[:8])
+	}
+	return fmt.Sprintf("jira-ops: estimated from commits %s..%s", s.First[:8], s.Last[:8])
+}
+
+func (e *WorkEstimate) sessions(commits []*Commit) []*WorkSession {
+	sorted := make([]*Commit, len(commits))
+	copy(sorted, commits)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	sessions := make([]*WorkSession, 0)
+	var session *WorkSession
+	var last time.Time
+
+	for _, c := range sorted {
+		if session == nil || c.Time.Sub(last) > e.Gap {
+			session = &WorkSession{Started: c.Time.Add(-e.Lead), Spent: e.Lead, First: c.Hash}
+			sessions = append(sessions, session)
+		} else {
+			session.Spent += c.Time.Sub(last)
+		}
+		session.Last = c.Hash
+		last = c.Time
+	}
+
+	for _, s := range sessions {
+		if e.Maximum > 0 && s.Spent > e.Maximum {
+			s.Spent = e.Maximum
+		}
+		if e.Rounding > 0 {
+			s.Spent = ((s.Spent + e.Rounding - 1) / e.Rounding) * e.Rounding
+		}
+	}
+
+	return sessions
+}
+
+func findWorkBranch(key string) (string, error) {
+	if current, err := currentBranchIssueKey(); err == nil && current == key {
+		return git("rev-parse", "--abbrev-ref", "HEAD")
+	}
+
+	branches, err := findIssueBranches(key)
+	if err != nil {
+		return "", err
+	}
+	if len(branches) == 0 {
+		return "", fmt.Errorf("no branch found for %s", key)
+	}
+
+	return branches[0], nil
+}
+
+func logFromGit(store WorklogStore, options *Options, key, base string, estimate *WorkEstimate, attributes map[string]string) error {
+	branch, err := findWorkBranch(key)
+	if err != nil {
+		return err
+	}
+
+	email, err := git("config", "user.email")
+	if err != nil {
+		return err
+	}
+
+	history, err := readCommits(fmt.Sprintf("%s..%s", base, branch), false)
+	if err != nil {
+		return err
+	}
+
+	mine := make([]*Commit, 0)
+	for _, c := range history {
+		if strings.EqualFold(c.Email, email) {
+			mine = append(mine, c)
+		}
+	}
+
+	if len(mine) == 0 {
+		return fmt.Errorf("no commits by %s on %s", email, branch)
+	}
+
+	issue := &jira.Issue{Key: key}
+
+	worklogs, err := store.Worklogs(options.ctx, issue)
+	if err != nil {
+		return err
+	}
+
+	logged := make(map[string]bool)
+	for _, w := range worklogs {
+		logged[w.Comment] = true
+	}
+
+	for _, s := range estimate.sessions(mine) {
+		comment := s.comment()
+		if logged[comment] {
+			continue
+		}
+
+		log.Printf("[%s] %v starting %v (%s)", key, s.Spent, s.Started.Format(time.RFC822), comment)
+
+		if options.DryRun {
+			continue
+		}
+
+		if err := store.AddWorklog(options.ctx, issue, &Worklog{Started: s.Started, Spent: s.Spent, Comment: comment, Attributes: attributes}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}